@@ -3,21 +3,29 @@ package app
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"maps"
 	"os"
 	"os/exec"
+	"os/signal"
+	"path/filepath"
 	"slices"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/alesr/impact/internal/config"
 	"github.com/alesr/impact/internal/estimate"
+	"github.com/alesr/impact/internal/pkg/actualview"
+	"github.com/alesr/impact/internal/pkg/daterange"
 	"github.com/alesr/impact/internal/pkg/progress"
 	"github.com/alesr/impact/internal/pkg/strx"
 	"github.com/alesr/impact/internal/plan"
+	"github.com/alesr/impact/internal/policy"
+	"github.com/alesr/impact/internal/prcomment"
+	"github.com/alesr/impact/internal/projectnames"
 	"github.com/alesr/impact/internal/report"
 	"github.com/alesr/impact/internal/scw/catalog"
 	"github.com/alesr/impact/internal/scw/footprint"
@@ -27,8 +35,16 @@ import (
 )
 
 const (
-	userAgent            = "impact/dev"
+	userAgent = "impact/dev"
+	// terraformShowTimeout bounds terraform show when --timeout is not set.
 	terraformShowTimeout = 2 * time.Minute
+	// showDrainBytes is how much output is read after a plan fails to parse,
+	// waiting for the command to report its own error.
+	showDrainBytes = 64 << 10
+	// API timeouts bound each attempt; --timeout bounds the whole command.
+	apiTimeout           = 15 * time.Second
+	doctorTimeout        = 10 * time.Second
+	defaultMaxPlanSizeMB = 50
 )
 
 var errUsage = errors.New("usage: impact <command> (run 'impact --help')")
@@ -37,34 +53,113 @@ type catalogProductLister interface {
 	ListAllProducts(ctx context.Context) ([]catalog.Product, error)
 }
 
+// Run executes the command line. Ctrl-C or SIGTERM cancels the command
+// context; a second signal kills the process as usual.
 func Run(args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	context.AfterFunc(ctx, stop)
+
 	rootCmd := newRootCmd()
 	rootCmd.SetArgs(args)
 	rootCmd.SilenceUsage = true
 	rootCmd.SilenceErrors = true
-	return rootCmd.Execute()
+	return rootCmd.ExecuteContext(ctx)
 }
 
 type planOptions struct {
-	planFile      string
-	fromTerraform bool
-	format        string
-	tuiMode       bool
+	root           rootOptions
+	planFiles      []string
+	fromTerraform  bool
+	format         string
+	csvUnsupported bool
+	tuiMode        bool
+	maxPlanSizeMB  int64
+	terraform      terraformOptions
+	terragrunt     terragruntOptions
+	policyFlags    policyFlags
+	policy         policy.Policy
+	estimate       estimateFlags
+	comment        commentOptions
+}
+
+type terraformOptions struct {
+	bin   string
+	chdir string
 }
 
 type actualOptions struct {
+	root              rootOptions
 	org               string
 	start             string
 	end               string
+	period            string
 	projects          string
 	regions           string
 	zones             string
 	serviceCategories string
 	productCategories string
 	format            string
+	depth             string
+	sortBy            string
+	top               int
+	tuiMode           bool
+	granularity       string
+	compare           compareOptions
+	projectNames      bool
+}
+
+// rootOptions holds the persistent flags shared by every command.
+type rootOptions struct {
+	profile string
+	verbose bool
+	timeout time.Duration
+}
+
+// withTimeout calls fn with ctx bounded by --timeout.
+func (r rootOptions) withTimeout(ctx context.Context, fn func(ctx context.Context) error) error {
+	if r.timeout <= 0 {
+		return fn(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if err := fn(ctx); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("could not finish within --timeout %s: %w", r.timeout, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// runCommand bounds the whole command by --timeout. A TUI session lasts as
+// long as the user keeps it open, so it bounds each of its queries instead.
+func (r rootOptions) runCommand(cmd *cobra.Command, tuiMode bool, fn func(ctx context.Context) error) error {
+	if tuiMode {
+		return fn(cmd.Context())
+	}
+	return r.withTimeout(cmd.Context(), fn)
+}
+
+// queryWithTimeout runs one TUI query bounded by --timeout.
+func queryWithTimeout[T any](root rootOptions, ctx context.Context, query func(ctx context.Context) (T, error)) (T, error) {
+	var out T
+	err := root.withTimeout(ctx, func(ctx context.Context) error {
+		var err error
+		out, err = query(ctx)
+		return err
+	})
+	return out, err
 }
 
 func newRootCmd() *cobra.Command {
+	var (
+		root     rootOptions
+		settings config.Settings
+	)
+
 	cmd := &cobra.Command{}
 	cmd.Use = "impact"
 	cmd.Short = "scaleway environmental footprint cli"
@@ -74,301 +169,697 @@ func newRootCmd() *cobra.Command {
 		}
 		return errUsage
 	}
-	cmd.AddCommand(newPlanCmd(), newActualCmd(), newDoctorCmd())
+	// Config files only fill in flags that were not given on the command line.
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, _ []string) error {
+		skip, warn := inspectsConfig(cmd)
+		if skip {
+			return nil
+		}
+
+		dir, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("could not get working directory: %w", err)
+		}
+		if settings, err = loadSettings(dir); err != nil {
+			return err
+		}
+		if err := validateSettings(cmd.Root(), settings); err != nil {
+			if !warn {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "warning: %v\n", err)
+			return nil
+		}
+		return applySettings(cmd, settings)
+	}
+	cmd.PersistentFlags().StringVar(&root.profile, "profile", "", "Scaleway CLI config profile (defaults to SCW_PROFILE, then the active profile)")
+	cmd.PersistentFlags().BoolVarP(&root.verbose, "verbose", "v", false, "log API retries to stderr")
+	cmd.PersistentFlags().DurationVar(&root.timeout, "timeout", 0, "time limit for the whole command, e.g. 2m (0 disables it)")
+	cmd.AddCommand(newPlanCmd(&root), newHCLCmd(&root), newActualCmd(&root), newReconcileCmd(&root), newDoctorCmd(&root), newConfigCmd(&settings), newDevCmd())
 	return cmd
 }
 
-func newPlanCmd() *cobra.Command {
+func newPlanCmd(root *rootOptions) *cobra.Command {
 	opts := planOptions{}
 
 	cmd := &cobra.Command{
 		Use:   "plan",
 		Short: "estimate impact from terraform plan",
-		RunE: func(_ *cobra.Command, _ []string) error {
-			return runPlan(opts)
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			opts.root = *root
+			p, err := opts.policyFlags.resolve(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			opts.policy = p
+			return root.runCommand(cmd, opts.tuiMode, func(ctx context.Context) error {
+				return runPlan(ctx, opts)
+			})
 		},
 	}
 
-	cmd.Flags().StringVar(&opts.planFile, "file", "", "terraform show -json plan file")
+	cmd.Flags().StringArrayVar(&opts.planFiles, "file", nil, "terraform plan file (show -json output or binary plan); repeatable, accepts globs and directories")
 	cmd.Flags().BoolVar(&opts.fromTerraform, "from-terraform", false, "read terraform show -json from local terraform command")
-	cmd.Flags().StringVar(&opts.format, "format", "table", "output format: table|json")
+	cmd.Flags().StringVar(&opts.terraform.bin, "terraform-bin", "", "terraform or tofu binary (defaults to terraform, then tofu, from PATH)")
+	cmd.Flags().StringVar(&opts.terraform.chdir, "chdir", "", "terraform working directory for --from-terraform and binary plan files")
+	cmd.Flags().StringVar(&opts.terragrunt.dir, "terragrunt", "", "terragrunt root directory; builds one report across all units")
+	cmd.Flags().StringVar(&opts.terragrunt.bin, "terragrunt-bin", defaultTerragruntBin, "terragrunt binary")
+	cmd.Flags().StringVar(&opts.terragrunt.planName, "terragrunt-plan", defaultTerragruntPlan, "plan file name saved by terragrunt run-all plan -out (a <name>.json next to it is used when present)")
+	cmd.Flags().StringVar(&opts.format, "format", "table", "output format: table|json|csv|markdown|sarif")
+	cmd.Flags().BoolVar(&opts.csvUnsupported, "include-unsupported", false, "append unsupported resources to csv output")
 	cmd.Flags().BoolVar(&opts.tuiMode, "tui", false, "interactive terminal UI for plan report")
+	cmd.Flags().Int64Var(&opts.maxPlanSizeMB, "max-plan-size", defaultMaxPlanSizeMB, "maximum plan json size in MB (0 disables the limit)")
+	cmd.Flags().StringVar(&opts.comment.provider, "comment", "", "post or update a sticky comment on the current pull/merge request: github|gitlab")
+	cmd.Flags().StringVar(&opts.comment.apiURL, "comment-api-url", "", "API base URL for --comment (defaults to GITHUB_API_URL or CI_API_V4_URL)")
+	opts.policyFlags.register(cmd.Flags())
+	opts.estimate.register(cmd.Flags())
+	_ = cmd.MarkFlagFilename("file")
+	_ = cmd.MarkFlagFilename("policy", "yaml", "yml")
+	_ = cmd.MarkFlagDirname("chdir")
+	_ = cmd.MarkFlagDirname("terragrunt")
+	flagChoices(cmd, "format", "table", "json", "csv", "markdown", "sarif")
+	flagChoices(cmd, "comment", prcomment.ProviderGitHub, prcomment.ProviderGitLab)
+	exclusiveFlags(cmd, []string{"file"}, []string{"from-terraform"}, []string{"terragrunt"})
 
 	return cmd
 }
 
-func newActualCmd() *cobra.Command {
+func newActualCmd(root *rootOptions) *cobra.Command {
 	var opts actualOptions
 
 	cmd := &cobra.Command{
 		Use:   "actual",
 		Short: "query measured footprint impact",
-		RunE: func(_ *cobra.Command, _ []string) error {
-			return runActual(opts)
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			opts.root = *root
+			return root.runCommand(cmd, opts.tuiMode, func(ctx context.Context) error {
+				return runActual(ctx, opts)
+			})
 		},
 	}
 
-	cmd.Flags().StringVar(&opts.org, "org", "", "organization id (defaults to SCW_ORGANIZATION_ID)")
-	cmd.Flags().StringVar(&opts.start, "start", "", "start date (YYYY-MM-DD or RFC3339)")
-	cmd.Flags().StringVar(&opts.end, "end", "", "end date (YYYY-MM-DD or RFC3339)")
-	cmd.Flags().StringVar(&opts.projects, "project", "", "comma-separated project IDs filter")
+	cmd.Flags().StringVar(&opts.org, "org", "", "comma-separated organizations as ORG_ID, ORG_ID@PROFILE or @PROFILE (defaults to SCW_ORGANIZATION_ID)")
+	cmd.Flags().StringVar(&opts.start, "start", "", "start date (YYYY-MM-DD, RFC3339, today, now or relative such as -90d)")
+	cmd.Flags().StringVar(&opts.end, "end", "", "end date (YYYY-MM-DD, RFC3339, today, now or relative such as -1m)")
+	cmd.Flags().StringVar(&opts.period, "period", "", "named period instead of --start/--end: last-month|this-month|last-Nd|ytd|YYYY|YYYY-Qn|YYYY-MM")
+	cmd.Flags().StringVar(&opts.projects, "project", "", "comma-separated project IDs or names filter")
 	cmd.Flags().StringVar(&opts.regions, "region", "", "comma-separated regions filter")
 	cmd.Flags().StringVar(&opts.zones, "zone", "", "comma-separated zones filter")
 	cmd.Flags().StringVar(&opts.serviceCategories, "service-category", "", "comma-separated service categories filter")
 	cmd.Flags().StringVar(&opts.productCategories, "product-category", "", "comma-separated product categories filter")
-	cmd.Flags().StringVar(&opts.format, "format", "table", "output format: table|json")
+	cmd.Flags().BoolVar(&opts.projectNames, "project-names", false, "show project names from the Account API (cached on disk)")
+	cmd.Flags().StringVar(&opts.format, "format", "table", "output format: table|json|csv")
+	cmd.Flags().StringVar(&opts.depth, "depth", "project", "table breakdown depth: project|region|zone|sku")
+	cmd.Flags().StringVar(&opts.sortBy, "sort", "co2", "table sort order: co2|water")
+	cmd.Flags().IntVar(&opts.top, "top", 0, "show only the top N table lines (0 shows all)")
+	cmd.Flags().BoolVar(&opts.tuiMode, "tui", false, "interactive terminal UI to drill into measured impact")
+	cmd.Flags().StringVar(&opts.granularity, "granularity", "period", "period (one aggregate) or month (one query per calendar month)")
+	cmd.Flags().BoolVar(&opts.compare.previous, "compare-previous", false, "compare with the period right before --start/--end")
+	cmd.Flags().StringVar(&opts.compare.start, "compare-start", "", "start date of the period to compare with (YYYY-MM-DD, RFC3339, today, now or relative such as -180d)")
+	cmd.Flags().StringVar(&opts.compare.end, "compare-end", "", "end date of the period to compare with (YYYY-MM-DD, RFC3339, today, now or relative such as -90d)")
+	flagChoices(cmd, "format", "table", "json", "csv")
+	flagChoices(cmd, "depth", "project", "region", "zone", "sku")
+	flagChoices(cmd, "sort", "co2", "water")
+	flagChoices(cmd, "granularity", "period", "month")
+	exclusiveFlags(cmd, []string{"period"}, []string{"start", "end"})
+	exclusiveFlags(cmd, []string{"compare-previous"}, []string{"compare-start", "compare-end"})
 
 	return cmd
 }
 
-func newDoctorCmd() *cobra.Command {
-	return &cobra.Command{
+func newDoctorCmd(root *rootOptions) *cobra.Command {
+	var org string
+
+	cmd := &cobra.Command{
 		Use:   "doctor",
 		Short: "run diagnostics",
-		RunE: func(_ *cobra.Command, _ []string) error {
-			return runDoctor()
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return root.withTimeout(cmd.Context(), func(ctx context.Context) error {
+				return runDoctor(ctx, *root, org)
+			})
 		},
 	}
+
+	cmd.Flags().StringVar(&org, "org", "", "organizations to check, same syntax as impact actual --org (defaults to SCW_ORGANIZATION_ID)")
+
+	return cmd
 }
 
-func runPlan(opts planOptions) error {
-	if opts.planFile != "" && opts.fromTerraform {
+func runPlan(ctx context.Context, opts planOptions) error {
+	if len(opts.planFiles) > 0 && opts.fromTerraform {
 		return errors.New("could not build plan report: use either --file or --from-terraform, not both")
 	}
 
-	if opts.planFile == "" && !opts.fromTerraform {
-		return errors.New("could not build plan report: provide --file or --from-terraform")
+	if opts.terragrunt.dir != "" && (len(opts.planFiles) > 0 || opts.fromTerraform) {
+		return errors.New("could not build plan report: --terragrunt cannot be combined with --file or --from-terraform")
 	}
 
-	if opts.tuiMode {
-		return tui.RunPlanReportLoading(
-			func() (estimate.Report, error) {
-				return buildPlanReport(opts.planFile, opts.fromTerraform)
-			},
-		)
+	if len(opts.planFiles) == 0 && !opts.fromTerraform && opts.terragrunt.dir == "" {
+		return errors.New("could not build plan report: provide --file, --from-terraform or --terragrunt")
+	}
+
+	if opts.tuiMode && !opts.policy.IsZero() {
+		return errors.New("could not build plan report: policy limits cannot be combined with --tui")
+	}
+
+	var commentTarget prcomment.Target
+	if opts.comment.provider != "" {
+		if opts.tuiMode {
+			return errors.New("could not build plan report: --comment cannot be combined with --tui")
+		}
+
+		target, err := resolveCommentTarget(opts.comment)
+		if err != nil {
+			return err
+		}
+		commentTarget = target
+	}
+
+	var rep estimate.Report
+	out := reportOutput{root: opts.root, format: opts.format, tuiMode: opts.tuiMode, csvUnsupported: opts.csvUnsupported}
+	if err := renderPlanReport(ctx, out, func(ctx context.Context) (estimate.Report, error) {
+		var err error
+		if rep, err = buildPlanReport(ctx, opts); err != nil {
+			return rep, err
+		}
+		rep.Findings, err = policy.EvaluateRules(opts.policy.Rules, rep)
+		return rep, err
+	}); err != nil {
+		return err
+	}
+
+	if opts.comment.provider != "" {
+		if err := postPlanComment(ctx, commentTarget, rep); err != nil {
+			return err
+		}
+	}
+	return checkPolicy(os.Stderr, opts.policy, rep)
+}
+
+type reportOutput struct {
+	root           rootOptions
+	format         string
+	tuiMode        bool
+	csvUnsupported bool
+}
+
+func renderPlanReport(ctx context.Context, out reportOutput, buildFn func(ctx context.Context) (estimate.Report, error)) error {
+	if out.tuiMode {
+		return tui.RunPlanReportLoading(ctx, func(ctx context.Context) (estimate.Report, error) {
+			return queryWithTimeout(out.root, ctx, buildFn)
+		})
 	}
 
 	var rep estimate.Report
 	if err := runWithSpinner("processing plan and fetching catalog", func() error {
 		var runErr error
-		rep, runErr = buildPlanReport(opts.planFile, opts.fromTerraform)
+		rep, runErr = buildFn(ctx)
 		return runErr
 	}); err != nil {
 		return err
 	}
-	return outputPlanReport(opts.format, rep)
+	return outputPlanReport(out, rep)
 }
 
-func buildPlanReport(planFile string, fromTerraform bool) (estimate.Report, error) {
-	var (
-		changes []plan.ResourceChange
-		err     error
-	)
-
-	if fromTerraform {
-		changes, err = readChangesFromTerraform()
-	} else {
-		changes, err = plan.ParseFile(planFile)
+func buildPlanReport(ctx context.Context, opts planOptions) (estimate.Report, error) {
+	inputs, err := loadPlanInputs(ctx, opts)
+	if err != nil {
+		return estimate.Report{}, err
 	}
+	return estimateFromCatalog(ctx, opts.root, opts.estimate, inputs)
+}
 
+func estimateFromCatalog(ctx context.Context, root rootOptions, flags estimateFlags, inputs []estimate.Input) (estimate.Report, error) {
+	estimateOpts, err := flags.options()
 	if err != nil {
 		return estimate.Report{}, err
 	}
 
-	env, err := config.LoadScalewayFromEnv()
+	env, _, err := loadScaleway(root.profile)
 	if err != nil {
 		return estimate.Report{}, err
 	}
 
-	catalogClient, err := catalog.NewClient(
-		catalog.WithBaseURL(env.APIBaseURL),
-		catalog.WithUserAgent(userAgent),
-		catalog.WithTimeout(15*time.Second),
-	)
+	catalogClient, err := newCatalogClient(root, env, apiTimeout)
 	if err != nil {
 		return estimate.Report{}, err
 	}
-	return buildEstimateReport(context.Background(), changes, catalogClient)
+	return buildEstimateReport(ctx, inputs, flags.lister(catalogClient, env), estimateOpts...)
 }
 
-func buildEstimateReport(ctx context.Context, changes []plan.ResourceChange, lister catalogProductLister) (estimate.Report, error) {
+func loadPlanInputs(ctx context.Context, opts planOptions) ([]estimate.Input, error) {
+	if opts.terragrunt.dir != "" {
+		return loadTerragruntInputs(ctx, opts)
+	}
+
+	if opts.fromTerraform {
+		changes, err := loadPlanChanges(ctx, opts, "")
+		if err != nil {
+			return nil, err
+		}
+		return []estimate.Input{{Source: opts.terraform.chdir, Changes: changes}}, nil
+	}
+
+	files, err := expandPlanFiles(opts.planFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := make([]estimate.Input, 0, len(files))
+	for _, file := range files {
+		changes, err := loadPlanChanges(ctx, opts, file)
+		if err != nil {
+			if len(files) > 1 {
+				return nil, fmt.Errorf("%s: %w", file, err)
+			}
+			return nil, err
+		}
+		inputs = append(inputs, estimate.Input{Source: file, Changes: changes})
+	}
+	return inputs, nil
+}
+
+// loadPlanChanges reads one plan file, or the terraform working directory
+// when planFile is empty.
+func loadPlanChanges(ctx context.Context, opts planOptions, planFile string) ([]plan.ResourceChange, error) {
+	parseOpts := []plan.Option{plan.WithMaxBytes(opts.maxPlanSizeMB << 20)}
+
+	if planFile == "" {
+		return readChangesFromTerraform(ctx, opts.terraform, "", parseOpts...)
+	}
+
+	binary, err := plan.IsBinaryFile(planFile)
+	if err != nil {
+		return nil, err
+	}
+	if binary {
+		return readChangesFromTerraform(ctx, opts.terraform, planFile, parseOpts...)
+	}
+	return plan.ParseFile(planFile, parseOpts...)
+}
+
+// expandPlanFiles resolves --file values into plan paths. Values can be plain
+// paths, glob patterns, or directories (every *.json file inside).
+func expandPlanFiles(values []string) ([]string, error) {
+	var (
+		files = make([]string, 0, len(values))
+		seen  = make(map[string]struct{}, len(values))
+	)
+
+	add := func(path string) {
+		path = filepath.Clean(path)
+		if _, ok := seen[path]; ok {
+			return
+		}
+		seen[path] = struct{}{}
+		files = append(files, path)
+	}
+
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		pattern := ""
+		switch info, err := os.Stat(value); {
+		case err == nil && info.IsDir():
+			pattern = filepath.Join(value, "*.json")
+		case strings.ContainsAny(value, "*?["):
+			pattern = value
+		default:
+			add(value)
+			continue
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("could not expand --file %q: %w", value, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("could not expand --file %q: no plan files found", value)
+		}
+
+		slices.Sort(matches)
+		for _, match := range matches {
+			add(match)
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, errors.New("could not build plan report: provide --file, --from-terraform or --terragrunt")
+	}
+	return files, nil
+}
+
+func buildEstimateReport(ctx context.Context, inputs []estimate.Input, lister catalogProductLister, opts ...estimate.Option) (estimate.Report, error) {
 	products, err := lister.ListAllProducts(ctx)
 	if err != nil {
 		return estimate.Report{}, fmt.Errorf("could not fetch catalog products: %w", err)
 	}
-	return estimate.Build(changes, products), nil
+
+	if len(inputs) == 1 {
+		return estimate.Build(inputs[0].Changes, products, opts...), nil
+	}
+	return estimate.BuildSources(inputs, products, opts...), nil
 }
 
-func runActual(opts actualOptions) error {
-	env, err := config.LoadScalewayFromEnv()
+func runActual(ctx context.Context, opts actualOptions) error {
+	tableOpts, err := actualTableOptions(opts)
 	if err != nil {
 		return err
 	}
 
-	orgID := strings.TrimSpace(opts.org)
-	if orgID == "" {
-		orgID = env.OrganizationID
+	monthly, err := parseGranularity(opts.granularity)
+	if err != nil {
+		return err
 	}
 
-	if orgID == "" {
-		return errors.New("could not resolve organization id (use --org or SCW_ORGANIZATION_ID)")
+	env, _, err := loadScaleway(opts.root.profile)
+	if err != nil {
+		return err
 	}
 
-	var startDate *time.Time
-	if strings.TrimSpace(opts.start) != "" {
-		t, err := parseDate(opts.start)
-		if err != nil {
-			return fmt.Errorf("could not parse --start: %w", err)
-		}
-		startDate = &t
+	targets, err := resolveOrgTargets(opts.org, env, loadProfile)
+	if err != nil {
+		return err
 	}
 
-	var endDate *time.Time
-	if strings.TrimSpace(opts.end) != "" {
-		t, err := parseDate(opts.end)
-		if err != nil {
-			return fmt.Errorf("could not parse --end: %w", err)
+	startDate, endDate, err := actualWindow(opts, time.Now())
+	if err != nil {
+		return err
+	}
+
+	serviceCategories, err := parseServiceCategories(opts.serviceCategories)
+	if err != nil {
+		return err
+	}
+
+	productCategories, err := parseProductCategories(opts.productCategories)
+	if err != nil {
+		return err
+	}
+
+	if len(targets) > 1 {
+		if opts.compare.enabled() || monthly || opts.tuiMode || strings.TrimSpace(opts.projects) != "" {
+			return errors.New("could not query several organizations: --org with more than one organization cannot be combined with --compare-*, --granularity month, --tui or --project")
 		}
-		endDate = &t
+
+		queryReq := footprint.QueryImpactDataRequest{
+			StartDate:         startDate,
+			EndDate:           endDate,
+			Regions:           strx.ParseCSV(opts.regions),
+			Zones:             strx.ParseCSV(opts.zones),
+			ServiceCategories: serviceCategories,
+			ProductCategories: productCategories,
+		}
+
+		var rep actualview.OrgReport
+		if err := runWithSpinner(fmt.Sprintf("querying footprint data for %d organizations", len(targets)), func() error {
+			var runErr error
+			rep, runErr = queryOrgs(ctx, targets, func(ctx context.Context, target orgTarget) (impactQuerier, error) {
+				querier, _, err := newActualQuerier(ctx, opts.root, target, "", opts.projectNames)
+				return querier, err
+			}, queryReq)
+			return runErr
+		}); err != nil {
+			return err
+		}
+		return outputOrgReport(opts.format, rep, tableOpts)
 	}
 
-	footprintClient, err := footprint.NewClient(
-		env.AccessKey,
-		env.SecretKey,
-		footprint.WithBaseURL(env.APIBaseURL),
-		footprint.WithUserAgent(userAgent),
-		footprint.WithTimeout(15*time.Second),
-	)
+	target := targets[0]
+	querier, projectIDs, err := newActualQuerier(ctx, opts.root, target, opts.projects, opts.projectNames)
 	if err != nil {
 		return err
 	}
 
 	queryReq := footprint.QueryImpactDataRequest{
-		OrganizationID:    orgID,
+		OrganizationID:    target.id,
 		StartDate:         startDate,
 		EndDate:           endDate,
-		ProjectIDs:        strx.ParseCSV(opts.projects),
+		ProjectIDs:        projectIDs,
 		Regions:           strx.ParseCSV(opts.regions),
 		Zones:             strx.ParseCSV(opts.zones),
-		ServiceCategories: nil,
-		ProductCategories: nil,
+		ServiceCategories: serviceCategories,
+		ProductCategories: productCategories,
 	}
 
-	serviceCategories, err := parseServiceCategories(opts.serviceCategories)
-	if err != nil {
-		return err
+	if opts.compare.enabled() {
+		if monthly || opts.tuiMode {
+			return errors.New("could not build comparison: --compare-* cannot be combined with --granularity month or --tui")
+		}
+
+		current, previous, err := comparisonWindows(opts.compare, startDate, endDate, time.Now())
+		if err != nil {
+			return err
+		}
+
+		var cmp actualview.Comparison
+		if err := runWithSpinner("querying footprint data for both periods", func() error {
+			var runErr error
+			cmp, runErr = queryComparison(ctx, querier, queryReq, current, previous, tableOpts.SortBy)
+			return runErr
+		}); err != nil {
+			return err
+		}
+		return outputComparison(opts.format, cmp, tableOpts)
 	}
 
-	productCategories, err := parseProductCategories(opts.productCategories)
-	if err != nil {
-		return err
+	if monthly {
+		if startDate == nil || endDate == nil {
+			return errors.New("could not build monthly report: --granularity month requires --start and --end")
+		}
+		if opts.tuiMode {
+			return errors.New("could not build monthly report: --granularity month cannot be combined with --tui")
+		}
+
+		var months []actualview.MonthImpact
+		if err := runWithSpinner("querying monthly footprint data", func() error {
+			var runErr error
+			months, runErr = queryMonthly(ctx, querier, queryReq, actualview.MonthWindows(*startDate, *endDate))
+			return runErr
+		}); err != nil {
+			return err
+		}
+		return outputMonthlyReport(opts.format, months, tableOpts.SortBy)
 	}
 
-	queryReq.ServiceCategories = serviceCategories
-	queryReq.ProductCategories = productCategories
+	if opts.tuiMode {
+		return tui.RunActualReportLoading(ctx, startDate, endDate, func(ctx context.Context, start, end *time.Time) (*footprint.QueryImpactDataResponse, error) {
+			req := queryReq
+			req.StartDate, req.EndDate = start, end
+			return queryWithTimeout(opts.root, ctx, func(ctx context.Context) (*footprint.QueryImpactDataResponse, error) {
+				return querier.QueryImpactData(ctx, req)
+			})
+		})
+	}
 
 	var resp *footprint.QueryImpactDataResponse
 	if err := runWithSpinner("querying actual footprint data", func() error {
 		var runErr error
-		resp, runErr = footprintClient.QueryImpactData(context.Background(), queryReq)
+		resp, runErr = querier.QueryImpactData(ctx, queryReq)
 		return runErr
 	}); err != nil {
 		return err
 	}
-	return outputActualReport(opts.format, resp)
+	return outputActualReport(opts.format, resp, tableOpts)
+}
+
+// newActualQuerier builds the clients for target and returns a querier that
+// attaches project names, with the --project filter resolved to IDs.
+func newActualQuerier(ctx context.Context, root rootOptions, target orgTarget, rawProjects string, withNames bool) (impactQuerier, []string, error) {
+	footprintClient, err := newFootprintClient(root, target.env, apiTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	accountClient, err := newAccountClient(root, target.env, apiTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	projectIDs, names, err := resolveProjects(ctx, projectnames.NewResolver(accountClient, target.id), rawProjects, withNames, os.Stderr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return namedQuerier{impactQuerier: footprintClient, names: names}, projectIDs, nil
 }
 
-func readChangesFromTerraform() ([]plan.ResourceChange, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), terraformShowTimeout)
+func parseGranularity(raw string) (bool, error) {
+	switch normalizeFormat(raw) {
+	case "", "period":
+		return false, nil
+	case "month":
+		return true, nil
+	default:
+		return false, fmt.Errorf("could not parse --granularity %q (use period or month)", raw)
+	}
+}
+
+func actualTableOptions(opts actualOptions) (report.ActualTableOptions, error) {
+	depth, err := actualview.ParseDepth(opts.depth)
+	if err != nil {
+		return report.ActualTableOptions{}, fmt.Errorf("could not parse --depth: %w", err)
+	}
+
+	sortBy, err := actualview.ParseSortKey(opts.sortBy)
+	if err != nil {
+		return report.ActualTableOptions{}, fmt.Errorf("could not parse --sort: %w", err)
+	}
+
+	if opts.top < 0 {
+		return report.ActualTableOptions{}, errors.New("could not validate --top: must not be negative")
+	}
+	return report.ActualTableOptions{Depth: depth, SortBy: sortBy, Top: opts.top}, nil
+}
+
+// showContext bounds a terraform show run by the deadline of ctx, set by
+// --timeout, or by terraformShowTimeout when ctx has none.
+func showContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, terraformShowTimeout)
+}
+
+func readChangesFromTerraform(ctx context.Context, tf terraformOptions, planFile string, parseOpts ...plan.Option) ([]plan.ResourceChange, error) {
+	ctx, cancel := showContext(ctx)
+	defer cancel()
+
+	bin := resolveTerraformBin(tf.bin)
+
+	args := make([]string, 0, 4)
+	if tf.chdir != "" {
+		args = append(args, "-chdir="+tf.chdir)
+	}
+	args = append(args, "show", "-json")
+	if planFile != "" {
+		// -chdir changes how terraform resolves relative paths
+		absPath, err := filepath.Abs(planFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve plan file path: %w", err)
+		}
+		args = append(args, absPath)
+	}
+
+	return runShowJSON(ctx, bin, "", args, parseOpts...)
+}
+
+// runShowJSON runs a `show -json` style command in dir and decodes its stdout
+// as a terraform plan stream.
+func runShowJSON(ctx context.Context, bin, dir string, args []string, parseOpts ...plan.Option) ([]plan.ResourceChange, error) {
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "terraform", "show", "-json")
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Dir = dir
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
-	out, err := cmd.Output()
+
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
+		return nil, fmt.Errorf("could not run %s show -json: %w", bin, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("could not run %s show -json: %w", bin, err)
+	}
+
+	changes, parseErr := plan.ParseReader(stdout, parseOpts...)
+	if parseErr != nil {
+		// A failing command prints little before it exits with its own
+		// error; anything longer is a plan we gave up on, so stop it.
+		n, _ := io.Copy(io.Discard, io.LimitReader(stdout, showDrainBytes))
+		if n == showDrainBytes {
+			cancel()
+			_ = cmd.Wait()
+			return nil, parseErr
+		}
+	}
+
+	if err := cmd.Wait(); err != nil {
 		stderrText := strings.TrimSpace(stderr.String())
 		if stderrText == "" {
-			return nil, fmt.Errorf("could not run terraform show -json: %w", err)
+			return nil, fmt.Errorf("could not run %s show -json: %w", bin, err)
 		}
-		return nil, fmt.Errorf("could not run terraform show -json: %s", stderrText)
+		return nil, fmt.Errorf("could not run %s show -json: %s", bin, stderrText)
 	}
-	return plan.ParseBytes(out)
-}
 
-func runDoctor() error {
-	env, err := config.LoadScalewayFromEnv()
-	if err != nil {
-		return err
+	if parseErr != nil {
+		return nil, parseErr
 	}
+	return changes, nil
+}
 
-	missing := make([]string, 0, 2)
-
-	if env.AccessKey == "" {
-		missing = append(missing, "SCW_ACCESS_KEY")
+// resolveTerraformBin prefers an explicit binary, then terraform, then OpenTofu.
+func resolveTerraformBin(bin string) string {
+	if bin = strings.TrimSpace(bin); bin != "" {
+		return bin
 	}
 
-	if env.SecretKey == "" {
-		missing = append(missing, "SCW_SECRET_KEY")
+	for _, candidate := range []string{"terraform", "tofu"} {
+		if _, err := exec.LookPath(candidate); err == nil {
+			return candidate
+		}
 	}
+	return "terraform"
+}
 
-	if env.OrganizationID == "" {
-		missing = append(missing, "SCW_ORGANIZATION_ID")
+func runDoctor(ctx context.Context, root rootOptions, org string) error {
+	env, sources, err := loadScaleway(root.profile)
+	if err != nil {
+		return err
 	}
 
-	status := map[string]string{
-		"api_base_url": env.APIBaseURL,
-		"auth":         "ok",
+	// Without --org the environment is checked as is, so missing variables
+	// are reported instead of failing.
+	targets := []orgTarget{{id: env.OrganizationID, env: env}}
+	if strings.TrimSpace(org) != "" {
+		if targets, err = resolveOrgTargets(org, env, loadProfile); err != nil {
+			return err
+		}
 	}
 
-	if len(missing) > 0 {
-		status["auth"] = "missing: " + strings.Join(missing, ",")
-	}
+	status := doctorSources(env, sources)
 
-	catalogClient, err := catalog.NewClient(
-		catalog.WithBaseURL(env.APIBaseURL),
-		catalog.WithTimeout(10*time.Second),
-	)
+	catalogClient, err := newCatalogClient(root, env, doctorTimeout)
 	if err != nil {
 		return err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	if _, err := catalogClient.ListProducts(ctx, catalog.ListProductsRequest{Page: 1, PageSize: 1}); err != nil {
+	// Each check gets its own bound, so a stuck catalog still leaves time for
+	// the footprint checks.
+	catalogCtx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	_, err = catalogClient.ListProducts(catalogCtx, catalog.ListProductsRequest{Page: 1, PageSize: 1})
+	cancel()
+	if err != nil {
 		status["catalog"] = "error: " + err.Error()
 	} else {
 		status["catalog"] = "ok"
 	}
 
-	if env.AccessKey != "" && env.SecretKey != "" && env.OrganizationID != "" {
-		footprintClient, err := footprint.NewClient(
-			env.AccessKey,
-			env.SecretKey,
-			footprint.WithBaseURL(env.APIBaseURL),
-			footprint.WithTimeout(10*time.Second),
-		)
-		if err != nil {
-			return err
+	startDate, endDate := doctorQueryWindow(time.Now().UTC())
+
+	for _, target := range targets {
+		// One organization keeps the plain keys; several get one key each.
+		suffix := ""
+		if len(targets) > 1 {
+			suffix = "[" + target.id + "]"
 		}
 
-		startDate, endDate := doctorQueryWindow(time.Now().UTC())
+		missing := doctorMissingAuth(target.env)
+		if len(missing) > 0 {
+			status["auth"+suffix] = "missing: " + strings.Join(missing, ",")
+			status["footprint"+suffix] = "skipped (missing auth)"
+			continue
+		}
+		status["auth"+suffix] = "ok"
 
-		if _, err := footprintClient.QueryImpactData(
-			ctx,
-			footprint.QueryImpactDataRequest{
-				OrganizationID: env.OrganizationID,
-				StartDate:      &startDate,
-				EndDate:        &endDate,
-			},
-		); err != nil {
-			status["footprint"] = "error: " + err.Error()
-		} else {
-			status["footprint"] = "ok"
+		footprintStatus, err := doctorCheckFootprint(ctx, root, target.env, startDate, endDate)
+		if err != nil {
+			return err
 		}
-	} else {
-		status["footprint"] = "skipped (missing auth)"
+		status["footprint"+suffix] = footprintStatus
 	}
 
 	keys := slices.Collect(maps.Keys(status))
@@ -380,58 +871,160 @@ func runDoctor() error {
 	return nil
 }
 
-func outputPlanReport(format string, rep estimate.Report) error {
-	switch normalizeFormat(format) {
+// doctorSources reports the loaded settings and where each came from.
+// Credentials are never printed, only their source.
+func doctorSources(env config.Scaleway, sources config.Sources) map[string]string {
+	profile := sources.Profile
+	if profile == "" {
+		profile = "none (no scaleway config file)"
+	}
+
+	organization := config.SourceUnset
+	if env.OrganizationID != "" {
+		organization = env.OrganizationID + " (" + sources.OrganizationID + ")"
+	}
+
+	status := map[string]string{
+		"profile":         profile,
+		"api_base_url":    env.APIBaseURL + " (" + sources.APIBaseURL + ")",
+		"access_key":      sources.AccessKey,
+		"secret_key":      sources.SecretKey,
+		"organization_id": organization,
+	}
+	if env.CABundle != "" {
+		status["ca_bundle"] = env.CABundle
+	}
+	if env.ProxyURL != "" {
+		status["proxy"] = env.ProxyURL
+	}
+	return status
+}
+
+func doctorMissingAuth(env config.Scaleway) []string {
+	missing := make([]string, 0, 3)
+
+	if env.AccessKey == "" {
+		missing = append(missing, "SCW_ACCESS_KEY")
+	}
+
+	if env.SecretKey == "" {
+		missing = append(missing, "SCW_SECRET_KEY")
+	}
+
+	if env.OrganizationID == "" {
+		missing = append(missing, "SCW_ORGANIZATION_ID")
+	}
+	return missing
+}
+
+func doctorCheckFootprint(ctx context.Context, root rootOptions, env config.Scaleway, startDate, endDate time.Time) (string, error) {
+	footprintClient, err := newFootprintClient(root, env, doctorTimeout)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+
+	if _, err := footprintClient.QueryImpactData(
+		ctx,
+		footprint.QueryImpactDataRequest{
+			OrganizationID: env.OrganizationID,
+			StartDate:      &startDate,
+			EndDate:        &endDate,
+		},
+	); err != nil {
+		return "error: " + err.Error(), nil
+	}
+	return "ok", nil
+}
+
+func outputPlanReport(out reportOutput, rep estimate.Report) error {
+	switch normalizeFormat(out.format) {
 	case "json":
 		return report.PrintJSON(rep)
 	case "table":
 		return report.PrintTable(rep)
+	case "csv":
+		return report.PrintPlanCSV(rep, out.csvUnsupported)
+	case "markdown":
+		return report.PrintMarkdown(rep)
+	case "sarif":
+		return report.PrintSARIF(rep)
 	default:
-		return fmt.Errorf("could not render output format %q (use table or json)", format)
+		return fmt.Errorf("could not render output format %q (use table, json, csv, markdown or sarif)", out.format)
 	}
 }
 
-func outputActualReport(format string, rep *footprint.QueryImpactDataResponse) error {
+func outputActualReport(format string, rep *footprint.QueryImpactDataResponse, tableOpts report.ActualTableOptions) error {
 	switch normalizeFormat(format) {
 	case "json":
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(rep)
+		return report.PrintActualJSON(rep)
 
-	case "table":
-		fmt.Printf(
-			"Period: %s -> %s\nTotal kgCO2e/month: %.6f\nTotal m3 water/month: %.6f\n\n",
-			rep.StartDate.Format(time.RFC3339), rep.EndDate.Format(time.RFC3339),
-			rep.TotalImpact.KgCO2Equivalent, rep.TotalImpact.M3WaterUsage,
-		)
+	case "csv":
+		return report.PrintActualCSV(rep)
 
-		for _, project := range rep.Projects {
-			fmt.Printf("Project %s: kgCO2e=%.6f m3=%.6f\n", project.ProjectID, project.TotalProjectImpact.KgCO2Equivalent, project.TotalProjectImpact.M3WaterUsage)
-		}
-		return nil
+	case "table":
+		return report.PrintActualTable(rep, tableOpts)
 
 	default:
-		return fmt.Errorf("could not render output format %q (use table or json)", format)
+		return fmt.Errorf("could not render output format %q (use table, json or csv)", format)
 	}
 }
 
-const dateLayout = "2006-01-02"
+func parseDate(raw string, now time.Time) (time.Time, error) {
+	return daterange.ParseDate(raw, now)
+}
+
+// actualWindow resolves --period or --start/--end against now. A bound that
+// is not given stays nil so the API default applies; the range is still
+// validated against now in that case.
+func actualWindow(opts actualOptions, now time.Time) (*time.Time, *time.Time, error) {
+	hasPeriod := strings.TrimSpace(opts.period) != ""
+	hasStart := strings.TrimSpace(opts.start) != ""
+	hasEnd := strings.TrimSpace(opts.end) != ""
 
-func parseDate(raw string) (time.Time, error) {
-	raw = strings.TrimSpace(raw)
-	if raw == "" {
-		return time.Time{}, errors.New("could not parse date: date is empty")
+	if hasPeriod && (hasStart || hasEnd) {
+		return nil, nil, errors.New("could not resolve date range: use either --period or --start/--end, not both")
 	}
 
-	if t, err := time.Parse(time.RFC3339, raw); err == nil {
-		return t.UTC(), nil
+	if hasPeriod {
+		start, end, err := daterange.ParsePeriod(opts.period, now)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := daterange.Validate(start, end); err != nil {
+			return nil, nil, err
+		}
+		return &start, &end, nil
 	}
 
-	if t, err := time.Parse(dateLayout, raw); err == nil {
-		return t.UTC(), nil
+	var startDate, endDate *time.Time
+	if hasStart {
+		t, err := parseDate(opts.start, now)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not parse --start: %w", err)
+		}
+		startDate = &t
+	}
+	if hasEnd {
+		t, err := parseDate(opts.end, now)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not parse --end: %w", err)
+		}
+		endDate = &t
 	}
 
-	return time.Time{}, fmt.Errorf("could not validate date %q (use YYYY-MM-DD or RFC3339)", raw)
+	if startDate != nil {
+		end := now.UTC()
+		if endDate != nil {
+			end = *endDate
+		}
+		if err := daterange.Validate(*startDate, end); err != nil {
+			return nil, nil, err
+		}
+	}
+	return startDate, endDate, nil
 }
 
 func normalizeFormat(format string) string {
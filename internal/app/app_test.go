@@ -3,11 +3,20 @@ package app
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
+	"github.com/alesr/impact/internal/config"
+	"github.com/alesr/impact/internal/estimate"
+	"github.com/alesr/impact/internal/pkg/actualview"
 	"github.com/alesr/impact/internal/plan"
+	"github.com/alesr/impact/internal/report"
 	"github.com/alesr/impact/internal/scw/catalog"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -30,10 +39,193 @@ func TestRunPlan(t *testing.T) {
 	t.Run("rejects conflicting source flags", func(t *testing.T) {
 		t.Parallel()
 
-		err := runPlan(planOptions{planFile: "x.json", fromTerraform: true})
+		err := runPlan(context.Background(), planOptions{planFiles: []string{"x.json"}, fromTerraform: true})
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "either --file or --from-terraform")
 	})
+
+	t.Run("rejects comments in tui mode", func(t *testing.T) {
+		t.Parallel()
+
+		err := runPlan(context.Background(), planOptions{planFiles: []string{"x.json"}, tuiMode: true, comment: commentOptions{provider: "github"}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--comment cannot be combined with --tui")
+	})
+}
+
+func TestLoadPlanChanges(t *testing.T) {
+	t.Parallel()
+
+	planJSON := `{"format_version": "1.2", "resource_changes": [{"address": "scaleway_instance_server.web", "type": "scaleway_instance_server", "change": {"actions": ["create"], "before": null, "after": {"type": "DEV1-S"}}}]}`
+
+	t.Run("runs terraform show for binary plan files", func(t *testing.T) {
+		t.Parallel()
+
+		bin, argsFile := fakeBinary(t, "terraform", planJSON, 0)
+
+		dir := t.TempDir()
+		planFile := filepath.Join(dir, "tfplan")
+		require.NoError(t, os.WriteFile(planFile, []byte("PK\x03\x04binary"), 0o600))
+
+		changes, err := loadPlanChanges(context.Background(), planOptions{terraform: terraformOptions{bin: bin, chdir: dir}}, planFile)
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		assert.Equal(t, "scaleway_instance_server.web", changes[0].Address)
+
+		args, err := os.ReadFile(argsFile)
+		require.NoError(t, err)
+		assert.Equal(t, "-chdir="+dir+"\nshow\n-json\n"+planFile+"\n", string(args))
+	})
+
+	t.Run("reads current state with --from-terraform", func(t *testing.T) {
+		t.Parallel()
+
+		bin, argsFile := fakeBinary(t, "terraform", planJSON, 0)
+
+		changes, err := loadPlanChanges(context.Background(), planOptions{fromTerraform: true, terraform: terraformOptions{bin: bin}}, "")
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+
+		args, err := os.ReadFile(argsFile)
+		require.NoError(t, err)
+		assert.Equal(t, "show\n-json\n", string(args))
+	})
+
+	t.Run("parses json plan files without terraform", func(t *testing.T) {
+		t.Parallel()
+
+		planFile := filepath.Join(t.TempDir(), "plan.json")
+		require.NoError(t, os.WriteFile(planFile, []byte(planJSON), 0o600))
+
+		changes, err := loadPlanChanges(context.Background(), planOptions{terraform: terraformOptions{bin: "/nonexistent/terraform"}}, planFile)
+		require.NoError(t, err)
+		assert.Len(t, changes, 1)
+	})
+
+	t.Run("surfaces terraform stderr on failure", func(t *testing.T) {
+		t.Parallel()
+
+		bin, _ := fakeBinary(t, "terraform", "", 1)
+
+		_, err := loadPlanChanges(context.Background(), planOptions{fromTerraform: true, terraform: terraformOptions{bin: bin}}, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "fake terraform failed")
+	})
+
+	t.Run("stops terraform once the plan exceeds the size cap", func(t *testing.T) {
+		t.Parallel()
+
+		if runtime.GOOS == "windows" {
+			t.Skip("fake binaries require a posix shell")
+		}
+
+		bin := filepath.Join(t.TempDir(), "terraform")
+		require.NoError(t, os.WriteFile(bin, []byte("#!/bin/sh\nprintf '{\"resource_changes\": ['\nexec yes '{\"address\": \"x\"},'\n"), 0o700))
+
+		began := time.Now()
+		_, err := loadPlanChanges(context.Background(), planOptions{fromTerraform: true, maxPlanSizeMB: 1, terraform: terraformOptions{bin: bin}}, "")
+		require.ErrorContains(t, err, "payload too large")
+		assert.Less(t, time.Since(began), 10*time.Second)
+	})
+
+	t.Run("stops terraform when the context ends", func(t *testing.T) {
+		t.Parallel()
+
+		if runtime.GOOS == "windows" {
+			t.Skip("fake binaries require a posix shell")
+		}
+
+		bin := filepath.Join(t.TempDir(), "terraform")
+		require.NoError(t, os.WriteFile(bin, []byte("#!/bin/sh\nexec sleep 30\n"), 0o700))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		began := time.Now()
+		_, err := loadPlanChanges(ctx, planOptions{fromTerraform: true, terraform: terraformOptions{bin: bin}}, "")
+		require.Error(t, err)
+		assert.Less(t, time.Since(began), 10*time.Second)
+	})
+}
+
+// fakeBinary writes a shell script that records its arguments and prints
+// stdout (or fails with a message on stderr when exitCode is non-zero).
+func fakeBinary(t *testing.T, name, stdout string, exitCode int) (string, string) {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("fake binaries require a posix shell")
+	}
+
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	outFile := filepath.Join(dir, "stdout")
+	require.NoError(t, os.WriteFile(outFile, []byte(stdout), 0o600))
+
+	script := fmt.Sprintf(`#!/bin/sh
+printf '%%s\n' "$@" > %q
+if [ %d -ne 0 ]; then
+	echo "fake %s failed" >&2
+	exit %d
+fi
+cat %q
+`, argsFile, exitCode, name, exitCode, outFile)
+
+	bin := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(bin, []byte(script), 0o700))
+	return bin, argsFile
+}
+
+func TestPlanFileFlag(t *testing.T) {
+	t.Parallel()
+
+	plan := subcommand(newRootCmd(), "plan")
+	require.NoError(t, plan.ParseFlags([]string{"--file", "plans/app,v2.json", "--file", "a,b.json"}))
+
+	files, err := plan.Flags().GetStringArray("file")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"plans/app,v2.json", "a,b.json"}, files, "commas in paths are kept")
+}
+
+func TestExpandPlanFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"network/plan.json", "app/plan.json", "app/notes.txt"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte("{}"), 0o600))
+	}
+
+	t.Run("expands globs and directories without duplicates", func(t *testing.T) {
+		t.Parallel()
+
+		files, err := expandPlanFiles([]string{
+			filepath.Join(dir, "*", "plan.json"),
+			filepath.Join(dir, "app"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			filepath.Join(dir, "app", "plan.json"),
+			filepath.Join(dir, "network", "plan.json"),
+		}, files)
+	})
+
+	t.Run("keeps literal paths as given", func(t *testing.T) {
+		t.Parallel()
+
+		files, err := expandPlanFiles([]string{"missing.json"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"missing.json"}, files)
+	})
+
+	t.Run("fails when a pattern matches nothing", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := expandPlanFiles([]string{filepath.Join(dir, "*", "none.json")})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no plan files found")
+	})
 }
 
 func TestBuildEstimateReport(t *testing.T) {
@@ -62,10 +254,45 @@ func TestBuildEstimateReport(t *testing.T) {
 			},
 		}
 
-		rep, err := buildEstimateReport(context.Background(), changes, lister)
+		rep, err := buildEstimateReport(context.Background(), []estimate.Input{{Changes: changes}}, lister)
 		require.NoError(t, err)
 		assert.True(t, called)
 		assert.Len(t, rep.Rows, 1)
+		assert.Empty(t, rep.Groups)
+	})
+
+	t.Run("fetches catalog once for several plans", func(t *testing.T) {
+		t.Parallel()
+
+		changes := []plan.ResourceChange{{
+			Address: "scaleway_instance_server.web",
+			Type:    "scaleway_instance_server",
+			Actions: []string{"create"},
+			After:   map[string]any{"zone": "fr-par-2", "type": "POP2-HC-2C-4G"},
+		}}
+
+		calls := 0
+		lister := &mockCatalogProductLister{
+			listAllProductsFunc: func(context.Context) ([]catalog.Product, error) {
+				calls++
+				return []catalog.Product{{
+					SKU:             "/compute/pop2_hc_2c_4g/run_fr-par-2",
+					ProductCategory: "instances",
+					Locality:        catalog.Locality{Zone: "fr-par-2"},
+					UnitOfMeasure:   catalog.UnitOfMeasure{Unit: "hour", Size: 1},
+				}}, nil
+			},
+		}
+
+		rep, err := buildEstimateReport(context.Background(), []estimate.Input{
+			{Source: "a/plan.json", Changes: changes},
+			{Source: "b/plan.json", Changes: changes},
+		}, lister)
+		require.NoError(t, err)
+		assert.Equal(t, 1, calls)
+		assert.Len(t, rep.Rows, 2)
+		require.Len(t, rep.Groups, 2)
+		assert.Equal(t, "b/plan.json", rep.Groups[1].Source)
 	})
 
 	t.Run("returns wrapped error when catalog fetch fails", func(t *testing.T) {
@@ -78,7 +305,7 @@ func TestBuildEstimateReport(t *testing.T) {
 			},
 		}
 
-		_, err := buildEstimateReport(context.Background(), changes, lister)
+		_, err := buildEstimateReport(context.Background(), []estimate.Input{{Changes: changes}}, lister)
 		assert.Error(t, err)
 	})
 }
@@ -94,14 +321,17 @@ func TestParseDate(t *testing.T) {
 	}{
 		{name: "rfc3339", input: "2025-02-01T12:00:00Z", want: "2025-02-01T12:00:00Z"},
 		{name: "yyyy-mm-dd", input: "2025-02-01", want: "2025-02-01T00:00:00Z"},
+		{name: "relative", input: "-90d", want: "2025-11-27T00:00:00Z"},
 		{name: "invalid", input: "not-a-date", wantErr: true},
 	}
 
+	now := time.Date(2026, 2, 25, 15, 4, 5, 0, time.UTC)
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			got, err := parseDate(tt.input)
+			got, err := parseDate(tt.input, now)
 			if tt.wantErr {
 				assert.Error(t, err)
 				return
@@ -123,6 +353,37 @@ func TestDoctorQueryWindow(t *testing.T) {
 	assert.Equal(t, "2026-01-26T15:04:05Z", start.Format(time.RFC3339))
 }
 
+func TestDoctorSources(t *testing.T) {
+	t.Parallel()
+
+	env := config.Scaleway{APIBaseURL: "https://api.scaleway.com", AccessKey: "SCWXXXXXXXXXXXXXXXXX", SecretKey: "secret", OrganizationID: "org-id"}
+	sources := config.Sources{
+		Profile:        "bu-a",
+		APIBaseURL:     config.SourceDefault,
+		AccessKey:      "env SCW_ACCESS_KEY",
+		SecretKey:      "profile bu-a",
+		OrganizationID: "profile bu-a",
+	}
+
+	status := doctorSources(env, sources)
+	assert.Equal(t, map[string]string{
+		"profile":         "bu-a",
+		"api_base_url":    "https://api.scaleway.com (default)",
+		"access_key":      "env SCW_ACCESS_KEY",
+		"secret_key":      "profile bu-a",
+		"organization_id": "org-id (profile bu-a)",
+	}, status)
+
+	status = doctorSources(config.Scaleway{APIBaseURL: "https://api.scaleway.com"}, config.Sources{APIBaseURL: config.SourceDefault, OrganizationID: config.SourceUnset})
+	assert.Equal(t, "none (no scaleway config file)", status["profile"])
+	assert.Equal(t, config.SourceUnset, status["organization_id"])
+	assert.NotContains(t, status, "proxy")
+
+	status = doctorSources(config.Scaleway{CABundle: "/etc/ca.pem", ProxyURL: "http://proxy:3128"}, config.Sources{})
+	assert.Equal(t, "/etc/ca.pem", status["ca_bundle"])
+	assert.Equal(t, "http://proxy:3128", status["proxy"])
+}
+
 func TestParseServiceCategories(t *testing.T) {
 	t.Parallel()
 
@@ -186,3 +447,153 @@ func TestParseProductCategories(t *testing.T) {
 		})
 	}
 }
+
+func TestActualTableOptions(t *testing.T) {
+	t.Parallel()
+
+	got, err := actualTableOptions(actualOptions{depth: "sku", sortBy: "water", top: 5})
+	require.NoError(t, err)
+	assert.Equal(t, report.ActualTableOptions{Depth: actualview.DepthSKU, SortBy: actualview.SortByWater, Top: 5}, got)
+
+	_, err = actualTableOptions(actualOptions{depth: "org"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "could not parse --depth")
+
+	_, err = actualTableOptions(actualOptions{top: -1})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "could not validate --top")
+}
+
+func TestActualWindow(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 5, 14, 10, 30, 0, 0, time.UTC)
+
+	t.Run("resolves a period", func(t *testing.T) {
+		t.Parallel()
+
+		start, end, err := actualWindow(actualOptions{period: "last-month"}, now)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), *start)
+		assert.Equal(t, time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), *end)
+	})
+
+	t.Run("resolves relative dates and leaves missing bounds nil", func(t *testing.T) {
+		t.Parallel()
+
+		start, end, err := actualWindow(actualOptions{start: "-90d"}, now)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2026, 2, 13, 0, 0, 0, 0, time.UTC), *start)
+		assert.Nil(t, end)
+
+		start, end, err = actualWindow(actualOptions{start: "-2y", end: "today"}, now)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC), *start)
+		assert.Equal(t, time.Date(2026, 5, 14, 0, 0, 0, 0, time.UTC), *end)
+
+		start, end, err = actualWindow(actualOptions{}, now)
+		require.NoError(t, err)
+		assert.Nil(t, start)
+		assert.Nil(t, end)
+	})
+
+	t.Run("rejects invalid ranges", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, err := actualWindow(actualOptions{period: "ytd", start: "-1d"}, now)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not both")
+
+		_, _, err = actualWindow(actualOptions{start: "2026-03-01", end: "2026-02-01"}, now)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must be before end")
+
+	})
+}
+
+func TestShowContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("follows --timeout", func(t *testing.T) {
+		t.Parallel()
+
+		err := rootOptions{timeout: time.Hour}.withTimeout(context.Background(), func(ctx context.Context) error {
+			want, _ := ctx.Deadline()
+			showCtx, cancel := showContext(ctx)
+			defer cancel()
+
+			got, ok := showCtx.Deadline()
+			assert.True(t, ok)
+			assert.Equal(t, want, got)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("falls back to the terraform show timeout", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := showContext(context.Background())
+		defer cancel()
+
+		got, ok := ctx.Deadline()
+		require.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(terraformShowTimeout), got, time.Second)
+	})
+}
+
+func TestWithTimeout(t *testing.T) {
+	t.Parallel()
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+
+	t.Run("passes the command context without a timeout", func(t *testing.T) {
+		t.Parallel()
+
+		err := rootOptions{}.withTimeout(cmd.Context(), func(ctx context.Context) error {
+			_, ok := ctx.Deadline()
+			assert.False(t, ok)
+			return errors.New("boom")
+		})
+		require.EqualError(t, err, "boom")
+	})
+
+	t.Run("names the flag when the deadline passes", func(t *testing.T) {
+		t.Parallel()
+
+		err := rootOptions{timeout: 10 * time.Millisecond}.withTimeout(cmd.Context(), func(ctx context.Context) error {
+			<-ctx.Done()
+			return fmt.Errorf("could not list products: %w", ctx.Err())
+		})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.EqualError(t, err, "could not finish within --timeout 10ms: could not list products: context deadline exceeded")
+	})
+
+	t.Run("bounds each TUI query rather than the session", func(t *testing.T) {
+		t.Parallel()
+
+		root := rootOptions{timeout: 10 * time.Millisecond}
+		err := root.runCommand(cmd, true, func(ctx context.Context) error {
+			_, ok := ctx.Deadline()
+			assert.False(t, ok, "the session is not bounded")
+
+			for range 2 {
+				_, err := queryWithTimeout(root, ctx, func(ctx context.Context) (int, error) {
+					<-ctx.Done()
+					return 0, ctx.Err()
+				})
+				require.EqualError(t, err, "could not finish within --timeout 10ms: context deadline exceeded")
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		err = root.runCommand(cmd, false, func(ctx context.Context) error {
+			_, ok := ctx.Deadline()
+			assert.True(t, ok)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+}
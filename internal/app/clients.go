@@ -0,0 +1,102 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/alesr/impact/internal/config"
+	"github.com/alesr/impact/internal/scw/account"
+	"github.com/alesr/impact/internal/scw/catalog"
+	"github.com/alesr/impact/internal/scw/footprint"
+	"github.com/alesr/impact/internal/scw/httpclient"
+)
+
+// loadScaleway wraps config.LoadScaleway and warns on stderr when the API base
+// URL is plain http on a host that is not loopback.
+func loadScaleway(profile string) (config.Scaleway, config.Sources, error) {
+	env, sources, err := config.LoadScaleway(profile)
+	if err != nil {
+		return config.Scaleway{}, config.Sources{}, err
+	}
+	warnInsecureBaseURL(os.Stderr, env)
+	return env, sources, nil
+}
+
+// loadProfile wraps config.LoadProfile like loadScaleway, and only warns
+// when the profile changes the base URL.
+func loadProfile(name string, base config.Scaleway) (config.Scaleway, error) {
+	env, err := config.LoadProfile(name, base)
+	if err != nil {
+		return config.Scaleway{}, err
+	}
+	if env.APIBaseURL != base.APIBaseURL {
+		warnInsecureBaseURL(os.Stderr, env)
+	}
+	return env, nil
+}
+
+func warnInsecureBaseURL(w io.Writer, env config.Scaleway) {
+	if env.InsecureBaseURL() {
+		fmt.Fprintf(w, "warning: credentials are sent over plain http to %s (IMPACT_ALLOW_INSECURE_BASE_URL is set)\n", env.APIBaseURL)
+	}
+}
+
+// The client helpers bound each HTTP request, retries included, by timeout;
+// each attempt gets at most the time left. Paginated listings such as the
+// catalog are only bounded as a whole by --timeout.
+func newCatalogClient(root rootOptions, env config.Scaleway, timeout time.Duration) (*catalog.Client, error) {
+	return catalog.NewClient(
+		catalog.WithBaseURL(env.APIBaseURL),
+		catalog.WithUserAgent(userAgent),
+		catalog.WithTimeout(timeout),
+		catalog.WithRequestDeadline(timeout),
+		catalog.WithCABundle(env.CABundle),
+		catalog.WithProxyURL(env.ProxyURL),
+		catalog.WithRetryObserver(root.retryObserver(os.Stderr)),
+	)
+}
+
+func newFootprintClient(root rootOptions, env config.Scaleway, timeout time.Duration) (*footprint.Client, error) {
+	return footprint.NewClient(
+		env.AccessKey,
+		env.SecretKey,
+		footprint.WithBaseURL(env.APIBaseURL),
+		footprint.WithUserAgent(userAgent),
+		footprint.WithTimeout(timeout),
+		footprint.WithRequestDeadline(timeout),
+		footprint.WithCABundle(env.CABundle),
+		footprint.WithProxyURL(env.ProxyURL),
+		footprint.WithRetryObserver(root.retryObserver(os.Stderr)),
+	)
+}
+
+func newAccountClient(root rootOptions, env config.Scaleway, timeout time.Duration) (*account.Client, error) {
+	return account.NewClient(
+		env.AccessKey,
+		env.SecretKey,
+		account.WithBaseURL(env.APIBaseURL),
+		account.WithUserAgent(userAgent),
+		account.WithTimeout(timeout),
+		account.WithRequestDeadline(timeout),
+		account.WithCABundle(env.CABundle),
+		account.WithProxyURL(env.ProxyURL),
+		account.WithRetryObserver(root.retryObserver(os.Stderr)),
+	)
+}
+
+// retryObserver logs each retry to w in verbose mode, and is nil otherwise.
+func (r rootOptions) retryObserver(w io.Writer) func(httpclient.RetryEvent) {
+	if !r.verbose {
+		return nil
+	}
+	return func(e httpclient.RetryEvent) {
+		reason := fmt.Sprintf("status %d", e.Status)
+		if e.Err != nil {
+			reason = e.Err.Error()
+		}
+		fmt.Fprintf(w, "retry %d/%d: %s %s failed (%s), retrying in %s\n",
+			e.Attempt, e.MaxAttempts-1, e.Method, e.URL, reason, e.Wait.Round(time.Millisecond))
+	}
+}
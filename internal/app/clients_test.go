@@ -0,0 +1,48 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/alesr/impact/internal/config"
+	"github.com/alesr/impact/internal/scw/httpclient"
+)
+
+func TestWarnInsecureBaseURL(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	warnInsecureBaseURL(&buf, config.Scaleway{APIBaseURL: "https://api.scaleway.com"})
+	warnInsecureBaseURL(&buf, config.Scaleway{APIBaseURL: "http://127.0.0.1:8080"})
+	assert.Empty(t, buf.String())
+
+	warnInsecureBaseURL(&buf, config.Scaleway{APIBaseURL: "http://mirror.internal", AllowInsecureBaseURL: true})
+	assert.Equal(t, "warning: credentials are sent over plain http to http://mirror.internal (IMPACT_ALLOW_INSECURE_BASE_URL is set)\n", buf.String())
+}
+
+func TestRetryObserver(t *testing.T) {
+	t.Parallel()
+
+	t.Run("is nil unless verbose", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Nil(t, rootOptions{}.retryObserver(&bytes.Buffer{}))
+	})
+
+	t.Run("logs each retry", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		observe := rootOptions{verbose: true}.retryObserver(&buf)
+		observe(httpclient.RetryEvent{Method: http.MethodGet, URL: "https://api/products", Attempt: 1, MaxAttempts: 4, Status: http.StatusTooManyRequests, Wait: time.Second})
+		observe(httpclient.RetryEvent{Method: http.MethodGet, URL: "https://api/products", Attempt: 2, MaxAttempts: 4, Err: errors.New("connection reset"), Wait: 1500 * time.Microsecond})
+
+		assert.Equal(t, "retry 1/3: GET https://api/products failed (status 429), retrying in 1s\n"+
+			"retry 2/3: GET https://api/products failed (connection reset), retrying in 2ms\n", buf.String())
+	})
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/alesr/impact/internal/estimate"
+	"github.com/alesr/impact/internal/prcomment"
+	"github.com/alesr/impact/internal/report"
+)
+
+const commentTimeout = 30 * time.Second
+
+type commentOptions struct {
+	provider string
+	apiURL   string
+}
+
+// resolveCommentTarget reads the PR/MR from the CI environment, so a missing
+// variable fails before the plan is processed.
+func resolveCommentTarget(opts commentOptions) (prcomment.Target, error) {
+	return prcomment.TargetFromEnv(opts.provider, opts.apiURL, os.Getenv)
+}
+
+func postPlanComment(ctx context.Context, target prcomment.Target, rep estimate.Report) error {
+	ctx, cancel := context.WithTimeout(ctx, commentTimeout)
+	defer cancel()
+
+	client := prcomment.NewClient(target, prcomment.WithUserAgent(userAgent), prcomment.WithTimeout(commentTimeout))
+	return client.Upsert(ctx, report.RenderMarkdown(rep))
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/alesr/impact/internal/pkg/actualview"
+	"github.com/alesr/impact/internal/pkg/daterange"
+	"github.com/alesr/impact/internal/report"
+	"github.com/alesr/impact/internal/scw/footprint"
+)
+
+type compareOptions struct {
+	previous bool
+	start    string
+	end      string
+}
+
+func (o compareOptions) enabled() bool {
+	return o.previous || strings.TrimSpace(o.start) != "" || strings.TrimSpace(o.end) != ""
+}
+
+// comparisonWindows returns the current window and the one to compare it
+// with: either the explicit --compare-start/--compare-end or, with
+// --compare-previous, the period right before the current one.
+func comparisonWindows(opts compareOptions, start, end *time.Time, now time.Time) (actualview.Window, actualview.Window, error) {
+	if start == nil || end == nil {
+		return actualview.Window{}, actualview.Window{}, errors.New("could not build comparison: --start and --end are required")
+	}
+	current := actualview.Window{Start: *start, End: *end}
+
+	hasExplicit := strings.TrimSpace(opts.start) != "" || strings.TrimSpace(opts.end) != ""
+	if opts.previous && hasExplicit {
+		return actualview.Window{}, actualview.Window{}, errors.New("could not build comparison: use either --compare-previous or --compare-start/--compare-end, not both")
+	}
+	if opts.previous {
+		return current, actualview.PreviousWindow(current), nil
+	}
+
+	compareStart, err := parseDate(opts.start, now)
+	if err != nil {
+		return actualview.Window{}, actualview.Window{}, fmt.Errorf("could not parse --compare-start: %w", err)
+	}
+	compareEnd, err := parseDate(opts.end, now)
+	if err != nil {
+		return actualview.Window{}, actualview.Window{}, fmt.Errorf("could not parse --compare-end: %w", err)
+	}
+	if err := daterange.Validate(compareStart, compareEnd); err != nil {
+		return actualview.Window{}, actualview.Window{}, err
+	}
+	return current, actualview.Window{Start: compareStart, End: compareEnd}, nil
+}
+
+func queryComparison(ctx context.Context, querier impactQuerier, req footprint.QueryImpactDataRequest, current, previous actualview.Window, key actualview.SortKey) (actualview.Comparison, error) {
+	var currentResp, previousResp *footprint.QueryImpactDataResponse
+
+	g, ctx := errgroup.WithContext(ctx)
+	query := func(w actualview.Window, out **footprint.QueryImpactDataResponse) func() error {
+		return func() error {
+			windowReq := req
+			windowReq.StartDate, windowReq.EndDate = &w.Start, &w.End
+
+			resp, err := querier.QueryImpactData(ctx, windowReq)
+			if err != nil {
+				return fmt.Errorf("could not query %s -> %s: %w", w.Start.Format(daterange.Layout), w.End.Format(daterange.Layout), err)
+			}
+			*out = resp
+			return nil
+		}
+	}
+	g.Go(query(current, &currentResp))
+	g.Go(query(previous, &previousResp))
+
+	if err := g.Wait(); err != nil {
+		return actualview.Comparison{}, err
+	}
+	return actualview.Compare(currentResp, previousResp, current, previous, key), nil
+}
+
+func outputComparison(format string, cmp actualview.Comparison, tableOpts report.ActualTableOptions) error {
+	switch normalizeFormat(format) {
+	case "json":
+		return report.PrintComparisonJSON(cmp)
+	case "table":
+		return report.PrintComparisonTable(cmp, tableOpts)
+	default:
+		return fmt.Errorf("could not render output format %q (use table or json)", format)
+	}
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alesr/impact/internal/pkg/actualview"
+	"github.com/alesr/impact/internal/scw/footprint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComparisonWindows(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("previous period", func(t *testing.T) {
+		t.Parallel()
+
+		current, previous, err := comparisonWindows(compareOptions{previous: true}, &start, &end, end)
+		require.NoError(t, err)
+		assert.Equal(t, start, current.Start)
+		assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), previous.Start)
+		assert.Equal(t, start, previous.End)
+	})
+
+	t.Run("explicit period", func(t *testing.T) {
+		t.Parallel()
+
+		_, previous, err := comparisonWindows(compareOptions{start: "2025-02-01", end: "2025-03-01"}, &start, &end, end)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), previous.Start)
+	})
+
+	t.Run("rejects invalid combinations", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, err := comparisonWindows(compareOptions{previous: true}, nil, &end, end)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--start and --end are required")
+
+		_, _, err = comparisonWindows(compareOptions{previous: true, start: "2025-01-01"}, &start, &end, end)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not both")
+
+		_, _, err = comparisonWindows(compareOptions{start: "2025-01-01"}, &start, &end, end)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "could not parse --compare-end")
+	})
+}
+
+func TestQueryComparison(t *testing.T) {
+	t.Parallel()
+
+	current := actualview.Window{Start: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)}
+	previous := actualview.PreviousWindow(current)
+
+	querier := &fakeQuerier{}
+	cmp, err := queryComparison(context.Background(), querier, footprint.QueryImpactDataRequest{OrganizationID: "org"}, current, previous, actualview.SortByCO2)
+	require.NoError(t, err)
+
+	assert.Len(t, querier.requests, 2)
+	// March (3 kg over 31 days) against February (2 kg over 28 days), as monthly rates.
+	assert.InDelta(t, 3*730.0/744-2*730.0/672, cmp.Total.KgCO2eDelta, 1e-9)
+	require.Len(t, cmp.Projects, 1)
+	assert.Equal(t, "org", cmp.Projects[0].ProjectID)
+
+	_, err = queryComparison(context.Background(), &fakeQuerier{failOn: time.February}, footprint.QueryImpactDataRequest{}, current, previous, actualview.SortByCO2)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "could not query 2026-02-01 -> 2026-03-01")
+}
@@ -0,0 +1,326 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+
+	"github.com/alesr/impact/internal/config"
+)
+
+const (
+	choicesAnnotation   = "impact_choices"
+	conflictsAnnotation = "impact_conflicts"
+)
+
+func newConfigCmd(settings *config.Settings) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "inspect config files",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "show",
+		Short: "print the effective configuration merged from config files and flags",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return showSettings(os.Stdout, cmd.Root(), *settings)
+		},
+	})
+	return cmd
+}
+
+// loadSettings discovers and reads the config files for dir.
+func loadSettings(dir string) (config.Settings, error) {
+	files, err := config.DiscoverFiles(dir)
+	if err != nil {
+		return config.Settings{}, err
+	}
+	return config.LoadSettings(files...)
+}
+
+// flagChoices lists the values a config file may give the flag name of cmd.
+func flagChoices(cmd *cobra.Command, name string, values ...string) {
+	_ = cmd.Flags().SetAnnotation(name, choicesAnnotation, values)
+}
+
+// exclusiveFlags marks groups of flags of cmd that select the same thing, such
+// as an input, so that a config file value of one group is dropped when a flag
+// of another group is given on the command line.
+func exclusiveFlags(cmd *cobra.Command, groups ...[]string) {
+	for i, group := range groups {
+		var rivals []string
+		for j, other := range groups {
+			if i != j {
+				rivals = append(rivals, other...)
+			}
+		}
+		for _, name := range group {
+			_ = cmd.Flags().SetAnnotation(name, conflictsAnnotation, rivals)
+		}
+	}
+}
+
+// validateSettings checks every setting against the command tree of root, so
+// unknown commands, unknown flags and values of the wrong type are reported
+// even for commands that are not running. A top-level value is checked
+// against each command it reaches, skipping commands whose section sets the
+// same flag. Values are parsed into scratch flags; the tree itself is left
+// untouched.
+func validateSettings(root *cobra.Command, settings config.Settings) error {
+	for _, name := range slices.Sorted(maps.Keys(settings.Flags)) {
+		setting := settings.Flags[name]
+
+		targets := commandsWithFlag(root, name)
+		if len(targets) == 0 {
+			return settingError(setting, name, fmt.Errorf("unknown flag or command --%s", name))
+		}
+		for _, cmd := range targets {
+			if _, ok := settings.Commands[cmd.Name()][name]; ok {
+				continue
+			}
+			if err := setFlag(scratchFlag(lookupFlag(cmd, name)), setting); err != nil {
+				return settingError(setting, name, fmt.Errorf("%s: %w", cmd.CommandPath(), err))
+			}
+		}
+	}
+
+	for _, section := range slices.Sorted(maps.Keys(settings.Commands)) {
+		cmd := subcommand(root, section)
+		for _, name := range slices.Sorted(maps.Keys(settings.Commands[section])) {
+			setting := settings.Commands[section][name]
+			key := section + "." + name
+
+			if cmd == nil {
+				return settingError(setting, key, fmt.Errorf("unknown command %q", section))
+			}
+			flag := lookupFlag(cmd, name)
+			if flag == nil {
+				return settingError(setting, key, fmt.Errorf("impact %s has no --%s flag", section, name))
+			}
+			if err := setFlag(scratchFlag(flag), setting); err != nil {
+				return settingError(setting, key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// scratchFlag returns a detached flag of the same type as flag. Types without
+// a pflag constructor below are checked as strings.
+func scratchFlag(flag *pflag.Flag) *pflag.Flag {
+	fs := pflag.NewFlagSet(flag.Name, pflag.ContinueOnError)
+	switch flag.Value.Type() {
+	case "bool":
+		fs.Bool(flag.Name, false, "")
+	case "int":
+		fs.Int(flag.Name, 0, "")
+	case "int64":
+		fs.Int64(flag.Name, 0, "")
+	case "float64":
+		fs.Float64(flag.Name, 0, "")
+	case "duration":
+		fs.Duration(flag.Name, 0, "")
+	case "stringSlice":
+		fs.StringSlice(flag.Name, nil, "")
+	case "stringArray":
+		fs.StringArray(flag.Name, nil, "")
+	default:
+		fs.String(flag.Name, "", "")
+	}
+
+	scratch := fs.Lookup(flag.Name)
+	scratch.Annotations = flag.Annotations
+	return scratch
+}
+
+// inspectsConfig reports commands that run with a broken config file: help
+// and shell completion never read it, and config show is how it gets fixed.
+func inspectsConfig(cmd *cobra.Command) (skip, warn bool) {
+	switch topLevelName(cmd) {
+	case "help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true, false
+	case "config":
+		return false, true
+	}
+	return false, false
+}
+
+// applySettings sets the flags of cmd that were not given on the command
+// line: values from the section of its top-level command first, then
+// top-level values. Flags that conflict with one given on the command line
+// are left alone.
+func applySettings(cmd *cobra.Command, settings config.Settings) error {
+	section := settings.Commands[topLevelName(cmd)]
+
+	given := map[string]bool{}
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		given[flag.Name] = true
+	})
+
+	for _, name := range effectiveNames(cmd, settings) {
+		flag := lookupFlag(cmd, name)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		if slices.ContainsFunc(flag.Annotations[conflictsAnnotation], func(rival string) bool { return given[rival] }) {
+			continue
+		}
+
+		setting, key := settings.Flags[name], name
+		if s, ok := section[name]; ok {
+			setting, key = s, topLevelName(cmd)+"."+name
+		}
+		if err := setFlag(flag, setting); err != nil {
+			return settingError(setting, key, err)
+		}
+	}
+	return nil
+}
+
+func effectiveNames(cmd *cobra.Command, settings config.Settings) []string {
+	names := map[string]struct{}{}
+	for name := range settings.Flags {
+		names[name] = struct{}{}
+	}
+	for name := range settings.Commands[topLevelName(cmd)] {
+		names[name] = struct{}{}
+	}
+	return slices.Sorted(maps.Keys(names))
+}
+
+// setFlag sets flag to the setting, resolving relative paths of file and
+// directory flags against the config file's directory. Flags with choices
+// reject other values.
+func setFlag(flag *pflag.Flag, setting config.Setting) error {
+	for _, v := range setting.Values {
+		if choices := flag.Annotations[choicesAnnotation]; len(choices) > 0 && !slices.Contains(choices, normalizeFormat(v)) {
+			return fmt.Errorf("%q is not one of %s", v, strings.Join(choices, "|"))
+		}
+		if isPathFlag(flag) && v != "" && !filepath.IsAbs(v) {
+			v = filepath.Join(filepath.Dir(setting.Path), v)
+		}
+		if err := flag.Value.Set(v); err != nil {
+			return err
+		}
+	}
+	flag.Changed = true
+	return nil
+}
+
+func isPathFlag(flag *pflag.Flag) bool {
+	_, file := flag.Annotations[cobra.BashCompFilenameExt]
+	_, dir := flag.Annotations[cobra.BashCompSubdirsInDir]
+	return file || dir
+}
+
+func settingError(setting config.Setting, key string, err error) error {
+	return fmt.Errorf("could not apply config file %s: %s: %w", setting.Source(), key, err)
+}
+
+func commandsWithFlag(root *cobra.Command, name string) []*cobra.Command {
+	if root.PersistentFlags().Lookup(name) != nil {
+		return []*cobra.Command{root}
+	}
+	var out []*cobra.Command
+	for _, cmd := range root.Commands() {
+		if cmd.Flags().Lookup(name) != nil {
+			out = append(out, cmd)
+		}
+	}
+	return out
+}
+
+func lookupFlag(cmd *cobra.Command, name string) *pflag.Flag {
+	for _, flags := range []*pflag.FlagSet{cmd.Flags(), cmd.PersistentFlags(), cmd.InheritedFlags()} {
+		if flag := flags.Lookup(name); flag != nil {
+			return flag
+		}
+	}
+	return nil
+}
+
+func subcommand(root *cobra.Command, name string) *cobra.Command {
+	for _, cmd := range root.Commands() {
+		if cmd.Name() == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// topLevelName is the name of the command right below the root, which names
+// the config section of cmd and all its subcommands.
+func topLevelName(cmd *cobra.Command) string {
+	for cmd.HasParent() && cmd.Parent().HasParent() {
+		cmd = cmd.Parent()
+	}
+	return cmd.Name()
+}
+
+// showSettings prints the merged settings as yaml, each value commented with
+// its source. Root flags given on the command line are included.
+func showSettings(w io.Writer, root *cobra.Command, settings config.Settings) error {
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	if len(settings.Files) == 0 {
+		doc.HeadComment = "no config file found"
+	} else {
+		doc.HeadComment = "files (lowest precedence first):\n" + strings.Join(settings.Files, "\n")
+	}
+
+	flags := maps.Clone(settings.Flags)
+	root.PersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Changed && !slices.Contains(flags[flag.Name].Values, flag.Value.String()) {
+			flags[flag.Name] = config.Setting{Values: []string{flag.Value.String()}, Path: "flag"}
+		}
+	})
+
+	for _, name := range slices.Sorted(maps.Keys(flags)) {
+		doc.Content = append(doc.Content, scalarNode(name), settingNode(flags[name]))
+	}
+
+	for _, section := range slices.Sorted(maps.Keys(settings.Commands)) {
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for _, name := range slices.Sorted(maps.Keys(settings.Commands[section])) {
+			node.Content = append(node.Content, scalarNode(name), settingNode(settings.Commands[section][name]))
+		}
+		doc.Content = append(doc.Content, scalarNode(section), node)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("could not encode config: %w", err)
+	}
+	return enc.Close()
+}
+
+func scalarNode(v string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: v}
+}
+
+func settingNode(setting config.Setting) *yaml.Node {
+	comment := setting.Path
+	if setting.Line > 0 {
+		comment = setting.Source()
+	}
+
+	if !setting.List {
+		node := scalarNode(setting.Values[0])
+		node.LineComment = comment
+		return node
+	}
+
+	node := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle, LineComment: comment}
+	for _, v := range setting.Values {
+		node.Content = append(node.Content, scalarNode(v))
+	}
+	return node
+}
@@ -0,0 +1,164 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alesr/impact/internal/config"
+)
+
+func parseTestSettings(t *testing.T, path, content string) config.Settings {
+	t.Helper()
+
+	s, err := config.ParseSettings(path, []byte(content))
+	require.NoError(t, err)
+	return s
+}
+
+func TestValidateSettings(t *testing.T) {
+	t.Parallel()
+
+	valid := parseTestSettings(t, "/repo/.impact.yaml", "profile: prod\nformat: json\ncatalog-cache-ttl: 24h\ndefault-zone: fr-par-1\nplan:\n  max-plan-size: 10\n  file: [a.json]\n  mapping: mapping.yaml\n  usage: usage.yaml\nactual:\n  project-names: false\n")
+	root := newRootCmd()
+	require.NoError(t, validateSettings(root, valid))
+	assert.False(t, root.PersistentFlags().Lookup("profile").Changed, "validation leaves the tree untouched")
+	assert.Equal(t, "table", subcommand(root, "plan").Flag("format").Value.String())
+	assert.Equal(t, "[]", subcommand(root, "plan").Flag("file").Value.String())
+
+	tests := map[string]string{
+		"bogus: 1\n":                    "/repo/.impact.yaml:1: bogus: unknown flag or command --bogus",
+		"nope:\n  format: json\n":       "nope.format: unknown command \"nope\"",
+		"doctor:\n  depth: sku\n":       "doctor.depth: impact doctor has no --depth flag",
+		"plan:\n  max-plan-size: x\n":   "plan.max-plan-size: strconv.ParseInt",
+		"actual:\n  project-names: 2\n": "actual.project-names",
+		"catalog-cache-ttl: soon\n":     "catalog-cache-ttl: impact hcl: time: invalid duration",
+		"format: markdown\n":            "format: impact actual: \"markdown\" is not one of table|json|csv",
+		"actual:\n  depth: org\n":       "actual.depth: \"org\" is not one of project|region|zone|sku",
+	}
+	for input, want := range tests {
+		err := validateSettings(newRootCmd(), parseTestSettings(t, "/repo/.impact.yaml", input))
+		require.Error(t, err, input)
+		assert.Contains(t, err.Error(), want)
+	}
+
+	overridden := parseTestSettings(t, "/repo/.impact.yaml", "format: markdown\nactual:\n  format: csv\nreconcile:\n  format: table\n")
+	assert.NoError(t, validateSettings(newRootCmd(), overridden), "sections override the top-level value")
+}
+
+func TestInvalidConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, config.ProjectFileName), []byte("bogus: 1\n"), 0o600))
+
+	t.Run("fails commands that would use it", func(t *testing.T) {
+		var runErr error
+		captureStdout(t, func() {
+			runErr = Run([]string{"hcl", dir})
+		})
+		require.ErrorContains(t, runErr, "unknown flag or command --bogus")
+	})
+
+	t.Run("is only a warning for config show", func(t *testing.T) {
+		var runErr error
+		out := captureStdout(t, func() {
+			runErr = Run([]string{"config", "show"})
+		})
+		require.NoError(t, runErr)
+		assert.Contains(t, out, "bogus: 1")
+	})
+
+	t.Run("is ignored by help and completion", func(t *testing.T) {
+		for _, args := range [][]string{{"help", "plan"}, {"completion", "bash"}, {"__complete", "plan", "--fo"}} {
+			var runErr error
+			captureStdout(t, func() {
+				runErr = Run(args)
+			})
+			require.NoError(t, runErr, args)
+		}
+	})
+}
+
+func TestApplySettings(t *testing.T) {
+	t.Parallel()
+
+	settings := parseTestSettings(t, "/repo/.impact.yaml", "format: json\ntop: 2\nactual:\n  format: csv\nplan:\n  policy: policy.yaml\n  file: [a.json, /abs/b.json]\n")
+
+	t.Run("section beats top level and flags beat both", func(t *testing.T) {
+		t.Parallel()
+
+		root := newRootCmd()
+		actual := subcommand(root, "actual")
+		require.NoError(t, actual.ParseFlags([]string{"--top", "5"}))
+		require.NoError(t, applySettings(actual, settings))
+
+		assert.Equal(t, "csv", actual.Flag("format").Value.String())
+		assert.Equal(t, "5", actual.Flag("top").Value.String())
+	})
+
+	t.Run("resolves relative paths against the config file", func(t *testing.T) {
+		t.Parallel()
+
+		plan := subcommand(newRootCmd(), "plan")
+		require.NoError(t, applySettings(plan, settings))
+
+		assert.Equal(t, "json", plan.Flag("format").Value.String())
+		assert.Equal(t, filepath.Join("/repo", "policy.yaml"), plan.Flag("policy").Value.String())
+		assert.Equal(t, "[/repo/a.json,/abs/b.json]", plan.Flag("file").Value.String())
+		assert.True(t, plan.Flag("policy").Changed)
+	})
+
+	t.Run("skips values that conflict with command-line flags", func(t *testing.T) {
+		t.Parallel()
+
+		plan := subcommand(newRootCmd(), "plan")
+		require.NoError(t, plan.ParseFlags([]string{"--from-terraform"}))
+		require.NoError(t, applySettings(plan, settings))
+
+		assert.False(t, plan.Flag("file").Changed)
+		assert.Equal(t, filepath.Join("/repo", "policy.yaml"), plan.Flag("policy").Value.String())
+
+		actual := subcommand(newRootCmd(), "actual")
+		require.NoError(t, actual.ParseFlags([]string{"--end", "today"}))
+		require.NoError(t, applySettings(actual, parseTestSettings(t, "/repo/.impact.yaml", "actual:\n  period: last-month\n  start: -90d\n")))
+
+		assert.False(t, actual.Flag("period").Changed)
+		assert.Equal(t, "-90d", actual.Flag("start").Value.String())
+	})
+}
+
+func TestShowSettings(t *testing.T) {
+	t.Parallel()
+
+	t.Run("prints merged values with their source", func(t *testing.T) {
+		t.Parallel()
+
+		root := newRootCmd()
+		require.NoError(t, root.PersistentFlags().Set("profile", "dev"))
+
+		var buf bytes.Buffer
+		settings := parseTestSettings(t, "/repo/.impact.yaml", "format: json\nplan:\n  file: [a.json, b.json]\n")
+		require.NoError(t, showSettings(&buf, root, settings))
+
+		assert.Equal(t, `# files (lowest precedence first):
+# /repo/.impact.yaml
+format: json # /repo/.impact.yaml:1
+profile: dev # flag
+plan:
+  file: [a.json, b.json] # /repo/.impact.yaml:3
+`, buf.String())
+	})
+
+	t.Run("says when no file was found", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		require.NoError(t, showSettings(&buf, newRootCmd(), config.Settings{}))
+		assert.Contains(t, buf.String(), "# no config file found")
+	})
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alesr/impact/internal/fakeapi"
+)
+
+type fakeAPIOptions struct {
+	addr        string
+	fixtures    string
+	latency     time.Duration
+	failStatus  int
+	failFirst   int
+	failEvery   int
+	maxPageSize int
+	quiet       bool
+}
+
+func newDevCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dev",
+		Short: "tools for developing and testing against impact",
+	}
+	cmd.AddCommand(newFakeAPICmd())
+	return cmd
+}
+
+func newFakeAPICmd() *cobra.Command {
+	var opts fakeAPIOptions
+
+	cmd := &cobra.Command{
+		Use:   "fake-api",
+		Short: "serve the catalog, footprint and account APIs from fixtures",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runFakeAPI(cmd.Context(), opts, os.Stdout)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.addr, "addr", "127.0.0.1:8787", "listen address")
+	cmd.Flags().StringVar(&opts.fixtures, "fixtures", "", "directory with products.json, impact.json and projects.json (built-in fixtures fill the gaps)")
+	cmd.Flags().DurationVar(&opts.latency, "latency", 0, "delay added to every response")
+	cmd.Flags().IntVar(&opts.failStatus, "fail-status", http.StatusServiceUnavailable, "http status of injected failures")
+	cmd.Flags().IntVar(&opts.failFirst, "fail-first", 0, "fail the first N requests")
+	cmd.Flags().IntVar(&opts.failEvery, "fail-every", 0, "fail every Nth request (0 disables)")
+	cmd.Flags().IntVar(&opts.maxPageSize, "max-page-size", 0, "cap the page size of list endpoints (0 keeps the requested size)")
+	cmd.Flags().BoolVar(&opts.quiet, "quiet", false, "do not log requests")
+	_ = cmd.MarkFlagDirname("fixtures")
+
+	return cmd
+}
+
+func runFakeAPI(ctx context.Context, opts fakeAPIOptions, w io.Writer) error {
+	fixtures := fakeapi.DefaultFixtures()
+	if opts.fixtures != "" {
+		var err error
+		if fixtures, err = fakeapi.LoadFixtures(opts.fixtures); err != nil {
+			return err
+		}
+	}
+
+	serverOpts := []fakeapi.Option{
+		fakeapi.WithLatency(opts.latency),
+		fakeapi.WithFailures(fakeapi.Failures{Status: opts.failStatus, First: opts.failFirst, Every: opts.failEvery}),
+		fakeapi.WithMaxPageSize(opts.maxPageSize),
+	}
+	if !opts.quiet {
+		serverOpts = append(serverOpts, fakeapi.WithLog(w))
+	}
+
+	ln, err := net.Listen("tcp", opts.addr)
+	if err != nil {
+		return fmt.Errorf("could not listen on %s: %w", opts.addr, err)
+	}
+
+	srv := &http.Server{Handler: fakeapi.New(fixtures, serverOpts...), ReadHeaderTimeout: 10 * time.Second}
+
+	baseURL := "http://" + ln.Addr().String()
+	fmt.Fprintf(w, "serving fake Scaleway API on %s\n", baseURL)
+	fmt.Fprintf(w, "export IMPACT_SCW_API_BASE_URL=%s SCW_ACCESS_KEY=SCWXXXXXXXXXXXXXXXXX SCW_SECRET_KEY=00000000-0000-0000-0000-000000000000 SCW_ORGANIZATION_ID=00000000-0000-0000-0000-000000000000\n", baseURL)
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.Serve(ln) }()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("could not serve fake api: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("could not stop fake api: %w", err)
+	}
+	return nil
+}
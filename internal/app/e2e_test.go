@@ -0,0 +1,238 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alesr/impact/internal/estimate"
+	"github.com/alesr/impact/internal/fakeapi"
+)
+
+// setupFakeAPI points the environment at a fake API server and isolates the
+// config and cache directories, so commands run end to end without network
+// access or credentials.
+func setupFakeAPI(t *testing.T, opts ...fakeapi.Option) *fakeapi.Server {
+	t.Helper()
+
+	fake := fakeapi.New(fakeapi.DefaultFixtures(), opts...)
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	t.Setenv("SCW_CONFIG_PATH", filepath.Join(dir, "scw.yaml"))
+	t.Setenv("SCW_PROFILE", "")
+	t.Setenv("IMPACT_SCW_API_BASE_URL", srv.URL)
+	t.Setenv("SCW_ACCESS_KEY", "SCWXXXXXXXXXXXXXXXXX")
+	t.Setenv("SCW_SECRET_KEY", "00000000-0000-0000-0000-000000000000")
+	t.Setenv("SCW_ORGANIZATION_ID", "00000000-0000-0000-0000-000000000000")
+	return fake
+}
+
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	original := os.Stdout
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = w
+
+	fn()
+
+	require.NoError(t, w.Close())
+	os.Stdout = original
+
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+
+	return string(b)
+}
+
+func TestEndToEnd(t *testing.T) {
+	t.Run("plan estimates from the fake catalog", func(t *testing.T) {
+		planFile, err := filepath.Abs(filepath.Join("..", "plan", "testdata", "simple_plan.json"))
+		require.NoError(t, err)
+		setupFakeAPI(t, fakeapi.WithMaxPageSize(1))
+
+		var runErr error
+		out := captureStdout(t, func() {
+			runErr = Run([]string{"plan", "--file", planFile, "--format", "json"})
+		})
+		require.NoError(t, runErr)
+
+		var rep estimate.Report
+		require.NoError(t, json.Unmarshal([]byte(out), &rep), out)
+		require.NotEmpty(t, rep.Rows)
+		assert.Equal(t, "scaleway_instance_server.web", rep.Rows[0].Address)
+		assert.Equal(t, "/compute/pop2_hc_2c_4g/run_fr-par-2", rep.Rows[0].SKU)
+		assert.True(t, rep.Rows[0].KgCO2eKnown)
+	})
+
+	t.Run("plan applies estimate settings from the config file", func(t *testing.T) {
+		planFile, err := filepath.Abs(filepath.Join("..", "plan", "testdata", "simple_plan.json"))
+		require.NoError(t, err)
+		fake := setupFakeAPI(t)
+
+		for name, content := range map[string]string{
+			".impact.yaml": "plan:\n  mapping: mapping.yaml\n  usage: usage.yaml\n  catalog-cache-ttl: 1h\n",
+			"mapping.yaml": "scaleway_instance_server.web: /compute/dev1_s/run_nl-ams-1\n",
+			"usage.yaml":   "scaleway_instance_server: 2\n",
+		} {
+			require.NoError(t, os.WriteFile(name, []byte(content), 0o600))
+		}
+
+		var rows []estimate.Row
+		for range 2 {
+			var runErr error
+			out := captureStdout(t, func() {
+				runErr = Run([]string{"plan", "--file", planFile, "--format", "json"})
+			})
+			require.NoError(t, runErr)
+
+			var rep estimate.Report
+			require.NoError(t, json.Unmarshal([]byte(out), &rep), out)
+			rows = append(rows, rep.Rows[0])
+		}
+
+		assert.Equal(t, "/compute/dev1_s/run_nl-ams-1", rows[0].SKU)
+		assert.Equal(t, rows[0], rows[1])
+		assert.Equal(t, 1, fake.Requests(), "the second run reads the cached catalog")
+	})
+
+	t.Run("hcl fails --max-unknown-rows on a count it cannot evaluate", func(t *testing.T) {
+		setupFakeAPI(t)
+		tf := `variable "names" {}
+
+resource "scaleway_instance_server" "web" {
+  type = "DEV1-S"
+  zone = "fr-par-1"
+}
+
+resource "scaleway_instance_server" "workers" {
+  count = length(var.names)
+  type  = "DEV1-S"
+  zone  = "fr-par-1"
+}
+`
+		require.NoError(t, os.WriteFile("main.tf", []byte(tf), 0o600))
+
+		var runErr error
+		out := captureStdout(t, func() {
+			runErr = Run([]string{"hcl", ".", "--format", "json", "--max-unknown-rows", "0"})
+		})
+		require.ErrorIs(t, runErr, ErrPolicyViolation)
+
+		var rep estimate.Report
+		require.NoError(t, json.Unmarshal([]byte(out), &rep), out)
+		assert.Equal(t, 1, rep.Totals.UnknownRows)
+		require.Len(t, rep.Unsupported, 1)
+		assert.Equal(t, "unknown_value", rep.Unsupported[0].Code)
+	})
+
+	t.Run("actual reports the fake footprint with project names", func(t *testing.T) {
+		setupFakeAPI(t)
+
+		var runErr error
+		out := captureStdout(t, func() {
+			runErr = Run([]string{"actual", "--start", "2026-03-01", "--end", "2026-04-01", "--project-names", "--format", "csv"})
+		})
+		require.NoError(t, runErr)
+		assert.Contains(t, out, "web")
+		assert.Contains(t, out, "batch")
+	})
+
+	t.Run("doctor checks the fake endpoints", func(t *testing.T) {
+		setupFakeAPI(t)
+
+		var runErr error
+		out := captureStdout(t, func() {
+			runErr = Run([]string{"doctor"})
+		})
+		require.NoError(t, runErr)
+		assert.Contains(t, out, "auth: ok")
+		assert.Contains(t, out, "catalog: ok")
+		assert.Contains(t, out, "footprint: ok")
+	})
+
+	t.Run("doctor reports injected failures", func(t *testing.T) {
+		setupFakeAPI(t, fakeapi.WithFailures(fakeapi.Failures{Status: http.StatusForbidden, Every: 1}))
+
+		var runErr error
+		out := captureStdout(t, func() {
+			runErr = Run([]string{"doctor"})
+		})
+		require.NoError(t, runErr)
+		assert.Contains(t, out, "catalog: error: ")
+		assert.Contains(t, out, "footprint: error: ")
+	})
+
+	t.Run("doctor retries transient failures", func(t *testing.T) {
+		fake := setupFakeAPI(t, fakeapi.WithFailures(fakeapi.Failures{Status: http.StatusServiceUnavailable, First: 1}))
+
+		var runErr error
+		out := captureStdout(t, func() {
+			runErr = Run([]string{"doctor"})
+		})
+		require.NoError(t, runErr)
+		assert.Contains(t, out, "catalog: ok")
+		assert.Contains(t, out, "footprint: ok")
+		assert.Equal(t, 3, fake.Requests())
+	})
+}
+
+func TestEndToEndTimeout(t *testing.T) {
+	setupFakeAPI(t, fakeapi.WithLatency(5*time.Second))
+
+	began := time.Now()
+	var runErr error
+	captureStdout(t, func() {
+		runErr = Run([]string{"actual", "--timeout", "200ms", "--format", "json"})
+	})
+	require.Error(t, runErr)
+	assert.Contains(t, runErr.Error(), "could not finish within --timeout 200ms")
+	assert.Less(t, time.Since(began), 3*time.Second)
+}
+
+func TestRunFakeAPI(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r, w := io.Pipe()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- runFakeAPI(ctx, fakeAPIOptions{addr: "127.0.0.1:0", quiet: true}, w)
+		w.Close()
+	}()
+
+	line, err := bufio.NewReader(r).ReadString('\n')
+	require.NoError(t, err)
+	line = strings.TrimSpace(line)
+	baseURL := line[strings.LastIndex(line, " ")+1:]
+	require.True(t, strings.HasPrefix(baseURL, "http://127.0.0.1:"), line)
+	go func() { _, _ = io.Copy(io.Discard, r) }()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(baseURL + "/product-catalog/v2alpha1/public-catalog/products")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	cancel()
+	require.NoError(t, <-errCh)
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/alesr/impact/internal/catalogcache"
+	"github.com/alesr/impact/internal/config"
+	"github.com/alesr/impact/internal/estimate"
+)
+
+// estimateFlags tune how resources are matched to catalog products, for every
+// command that estimates from the catalog.
+type estimateFlags struct {
+	mappingFile     string
+	usageFile       string
+	defaultZone     string
+	catalogCacheTTL time.Duration
+}
+
+func (f *estimateFlags) register(flags *pflag.FlagSet) {
+	flags.StringVar(&f.mappingFile, "mapping", "", "yaml file mapping resource addresses or types to catalog SKUs")
+	flags.StringVar(&f.usageFile, "usage", "", "yaml file with resource quantities, needed to estimate usage-based resources")
+	flags.StringVar(&f.defaultZone, "default-zone", "", "zone of resources that set neither zone nor region, e.g. fr-par-1")
+	flags.DurationVar(&f.catalogCacheTTL, "catalog-cache-ttl", 0, "reuse a catalog fetched within this duration, e.g. 24h (0 disables the cache)")
+	_ = cobra.MarkFlagFilename(flags, "mapping", "yaml", "yml")
+	_ = cobra.MarkFlagFilename(flags, "usage", "yaml", "yml")
+}
+
+// options loads the mapping and usage files.
+func (f estimateFlags) options() ([]estimate.Option, error) {
+	opts := []estimate.Option{estimate.WithDefaultZone(f.defaultZone)}
+
+	if f.mappingFile != "" {
+		skus, err := estimate.LoadMappingFile(f.mappingFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, estimate.WithSKUs(skus))
+	}
+
+	if f.usageFile != "" {
+		quantities, err := estimate.LoadUsageFile(f.usageFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, estimate.WithQuantities(quantities))
+	}
+	return opts, nil
+}
+
+// lister serves the catalog of lister from the disk cache while it is
+// younger than --catalog-cache-ttl.
+func (f estimateFlags) lister(lister catalogProductLister, env config.Scaleway) catalogProductLister {
+	return catalogcache.New(lister, env.APIBaseURL, catalogcache.WithTTL(f.catalogCacheTTL))
+}
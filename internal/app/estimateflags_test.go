@@ -0,0 +1,67 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alesr/impact/internal/estimate"
+	"github.com/alesr/impact/internal/plan"
+	"github.com/alesr/impact/internal/scw/catalog"
+)
+
+func TestEstimateFlags(t *testing.T) {
+	t.Parallel()
+
+	t.Run("registers the flags", func(t *testing.T) {
+		t.Parallel()
+
+		for _, name := range []string{"plan", "hcl", "reconcile"} {
+			cmd := subcommand(newRootCmd(), name)
+			for _, flag := range []string{"mapping", "usage", "default-zone", "catalog-cache-ttl"} {
+				assert.NotNil(t, cmd.Flags().Lookup(flag), "%s --%s", name, flag)
+			}
+		}
+	})
+
+	t.Run("loads the mapping and usage files", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		mappingFile := filepath.Join(dir, "mapping.yaml")
+		usageFile := filepath.Join(dir, "usage.yaml")
+		require.NoError(t, os.WriteFile(mappingFile, []byte("scaleway_object_bucket: /storage/object/standard/fr-par\n"), 0o600))
+		require.NoError(t, os.WriteFile(usageFile, []byte("scaleway_object_bucket.assets: 250\n"), 0o600))
+
+		opts, err := estimateFlags{mappingFile: mappingFile, usageFile: usageFile}.options()
+		require.NoError(t, err)
+
+		kg := 0.001
+		products := []catalog.Product{{
+			SKU:                           "/storage/object/standard/fr-par",
+			UnitOfMeasure:                 catalog.UnitOfMeasure{Unit: "gb", Size: 1},
+			EnvironmentalImpactEstimation: &catalog.EnvironmentalEstimation{KgCO2Equivalent: &kg},
+		}}
+		bucket := plan.ResourceChange{Address: "scaleway_object_bucket.assets", Type: "scaleway_object_bucket", Actions: []string{"create"}, After: map[string]any{"region": "fr-par"}}
+
+		rep := estimate.Build([]plan.ResourceChange{bucket}, products, opts...)
+		require.Len(t, rep.Rows, 1)
+		assert.Equal(t, "/storage/object/standard/fr-par", rep.Rows[0].SKU)
+		assert.Empty(t, rep.Unsupported)
+	})
+
+	t.Run("reports unreadable files", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := estimateFlags{mappingFile: filepath.Join(t.TempDir(), "missing.yaml")}.options()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "could not read mapping file")
+
+		_, err = estimateFlags{usageFile: filepath.Join(t.TempDir(), "missing.yaml")}.options()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "could not read usage file")
+	})
+}
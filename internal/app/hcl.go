@@ -0,0 +1,85 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/alesr/impact/internal/estimate"
+	"github.com/alesr/impact/internal/hclplan"
+	"github.com/alesr/impact/internal/policy"
+	"github.com/spf13/cobra"
+)
+
+type hclOptions struct {
+	root           rootOptions
+	format         string
+	csvUnsupported bool
+	tuiMode        bool
+	estimate       estimateFlags
+	policyFlags    policyFlags
+	policy         policy.Policy
+}
+
+func newHCLCmd(root *rootOptions) *cobra.Command {
+	var opts hclOptions
+
+	cmd := &cobra.Command{
+		Use:   "hcl [dir]",
+		Short: "estimate impact statically from terraform files, without running terraform",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) == 1 {
+				dir = args[0]
+			}
+			opts.root = *root
+			p, err := opts.policyFlags.resolve(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			opts.policy = p
+			return root.runCommand(cmd, opts.tuiMode, func(ctx context.Context) error {
+				return runHCL(ctx, dir, opts)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.format, "format", "table", "output format: table|json|csv|markdown|sarif")
+	cmd.Flags().BoolVar(&opts.csvUnsupported, "include-unsupported", false, "append unsupported resources to csv output")
+	cmd.Flags().BoolVar(&opts.tuiMode, "tui", false, "interactive terminal UI for the report")
+	opts.policyFlags.register(cmd.Flags())
+	opts.estimate.register(cmd.Flags())
+	_ = cmd.MarkFlagFilename("policy", "yaml", "yml")
+	flagChoices(cmd, "format", "table", "json", "csv", "markdown", "sarif")
+
+	return cmd
+}
+
+func runHCL(ctx context.Context, dir string, opts hclOptions) error {
+	if opts.tuiMode && !opts.policy.IsZero() {
+		return errors.New("could not build hcl report: policy limits cannot be combined with --tui")
+	}
+
+	var rep estimate.Report
+	out := reportOutput{root: opts.root, format: opts.format, tuiMode: opts.tuiMode, csvUnsupported: opts.csvUnsupported}
+	if err := renderPlanReport(ctx, out, func(ctx context.Context) (estimate.Report, error) {
+		var err error
+		if rep, err = buildHCLReport(ctx, dir, opts.root, opts.estimate); err != nil {
+			return rep, err
+		}
+		rep.Findings, err = policy.EvaluateRules(opts.policy.Rules, rep)
+		return rep, err
+	}); err != nil {
+		return err
+	}
+	return checkPolicy(os.Stderr, opts.policy, rep)
+}
+
+func buildHCLReport(ctx context.Context, dir string, root rootOptions, flags estimateFlags) (estimate.Report, error) {
+	changes, err := hclplan.ParseDir(dir)
+	if err != nil {
+		return estimate.Report{}, err
+	}
+	return estimateFromCatalog(ctx, root, flags, []estimate.Input{{Source: dir, Changes: changes}})
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/alesr/impact/internal/pkg/actualview"
+	"github.com/alesr/impact/internal/report"
+	"github.com/alesr/impact/internal/scw/footprint"
+)
+
+const monthlyQueryParallelism = 4
+
+type impactQuerier interface {
+	QueryImpactData(ctx context.Context, req footprint.QueryImpactDataRequest) (*footprint.QueryImpactDataResponse, error)
+}
+
+// queryMonthly runs one query per window, at most monthlyQueryParallelism at
+// a time, and returns the results in window order.
+func queryMonthly(ctx context.Context, querier impactQuerier, req footprint.QueryImpactDataRequest, windows []actualview.Window) ([]actualview.MonthImpact, error) {
+	months := make([]actualview.MonthImpact, len(windows))
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(monthlyQueryParallelism)
+
+	for i, w := range windows {
+		g.Go(func() error {
+			monthReq := req
+			monthReq.StartDate, monthReq.EndDate = &w.Start, &w.End
+
+			resp, err := querier.QueryImpactData(ctx, monthReq)
+			if err != nil {
+				return fmt.Errorf("could not query %s: %w", w.Start.Format("2006-01"), err)
+			}
+			months[i] = actualview.NewMonthImpact(w, resp)
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return months, nil
+}
+
+func outputMonthlyReport(format string, months []actualview.MonthImpact, sortBy actualview.SortKey) error {
+	switch normalizeFormat(format) {
+	case "json":
+		return report.PrintMonthlyJSON(months)
+	case "csv":
+		return report.PrintMonthlyCSV(months)
+	case "table":
+		return report.PrintMonthlyTable(months, sortBy)
+	default:
+		return fmt.Errorf("could not render output format %q (use table, json or csv)", format)
+	}
+}
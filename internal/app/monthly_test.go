@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alesr/impact/internal/pkg/actualview"
+	"github.com/alesr/impact/internal/scw/footprint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeQuerier struct {
+	mu       sync.Mutex
+	requests []footprint.QueryImpactDataRequest
+	inFlight atomic.Int32
+	peak     atomic.Int32
+	failOn   time.Month
+}
+
+func (f *fakeQuerier) QueryImpactData(_ context.Context, req footprint.QueryImpactDataRequest) (*footprint.QueryImpactDataResponse, error) {
+	n := f.inFlight.Add(1)
+	defer f.inFlight.Add(-1)
+	for {
+		peak := f.peak.Load()
+		if n <= peak || f.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	f.mu.Lock()
+	f.requests = append(f.requests, req)
+	f.mu.Unlock()
+
+	if req.StartDate.Month() == f.failOn {
+		return nil, errors.New("boom")
+	}
+	kg := float64(req.StartDate.Month())
+	return &footprint.QueryImpactDataResponse{
+		TotalImpact: footprint.TotalImpact{KgCO2Equivalent: kg},
+		Projects:    []footprint.ProjectImpact{{ProjectID: req.OrganizationID, TotalProjectImpact: footprint.TotalImpact{KgCO2Equivalent: kg}}},
+	}, nil
+}
+
+func TestQueryMonthly(t *testing.T) {
+	t.Parallel()
+
+	windows := actualview.MonthWindows(
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	)
+	require.Len(t, windows, 12)
+
+	t.Run("returns months in order with bounded parallelism", func(t *testing.T) {
+		t.Parallel()
+
+		querier := &fakeQuerier{}
+		months, err := queryMonthly(context.Background(), querier, footprint.QueryImpactDataRequest{OrganizationID: "org"}, windows)
+		require.NoError(t, err)
+
+		require.Len(t, months, 12)
+		for i, month := range months {
+			assert.Equal(t, float64(i+1), month.TotalImpact.KgCO2Equivalent)
+		}
+		assert.Equal(t, "2025-03", months[2].Month)
+		assert.Equal(t, "org", months[0].Projects[0].ProjectID)
+		assert.LessOrEqual(t, querier.peak.Load(), int32(monthlyQueryParallelism))
+		assert.Len(t, querier.requests, 12)
+	})
+
+	t.Run("fails when a month fails", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := queryMonthly(context.Background(), &fakeQuerier{failOn: time.June}, footprint.QueryImpactDataRequest{}, windows)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "could not query 2025-06: boom")
+	})
+}
+
+func TestParseGranularity(t *testing.T) {
+	t.Parallel()
+
+	monthly, err := parseGranularity("Month")
+	require.NoError(t, err)
+	assert.True(t, monthly)
+
+	monthly, err = parseGranularity("period")
+	require.NoError(t, err)
+	assert.False(t, monthly)
+
+	_, err = parseGranularity("week")
+	require.Error(t, err)
+}
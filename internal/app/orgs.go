@@ -0,0 +1,116 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/alesr/impact/internal/config"
+	"github.com/alesr/impact/internal/pkg/actualview"
+	"github.com/alesr/impact/internal/pkg/strx"
+	"github.com/alesr/impact/internal/report"
+	"github.com/alesr/impact/internal/scw/footprint"
+)
+
+// orgTarget is one organization to query with the credentials it resolved to.
+type orgTarget struct {
+	id      string
+	profile string
+	env     config.Scaleway
+}
+
+type profileLoader func(name string, base config.Scaleway) (config.Scaleway, error)
+
+// resolveOrgTargets parses --org: comma-separated ORG_ID, ORG_ID@PROFILE or
+// @PROFILE entries. Entries with a profile use the credentials of that
+// Scaleway CLI profile, and @PROFILE uses its default organization. Entries
+// without a profile use env. An empty value falls back to
+// env.OrganizationID.
+func resolveOrgTargets(raw string, env config.Scaleway, loadProfile profileLoader) ([]orgTarget, error) {
+	entries := strx.ParseCSV(raw)
+	if len(entries) == 0 {
+		if env.OrganizationID == "" {
+			return nil, errors.New("could not resolve organization id (use --org or SCW_ORGANIZATION_ID)")
+		}
+		return []orgTarget{{id: env.OrganizationID, env: env}}, nil
+	}
+
+	targets := make([]orgTarget, 0, len(entries))
+	seen := map[string]struct{}{}
+
+	for _, entry := range entries {
+		id, profile, _ := strings.Cut(entry, "@")
+		id, profile = strings.TrimSpace(id), strings.TrimSpace(profile)
+
+		target := orgTarget{id: id, profile: profile, env: env}
+		if profile != "" {
+			cfg, err := loadProfile(profile, env)
+			if err != nil {
+				return nil, err
+			}
+			target.env = cfg
+			if target.id == "" {
+				target.id = cfg.OrganizationID
+			}
+		}
+
+		if target.id == "" {
+			return nil, fmt.Errorf("could not resolve organization id for --org entry %q", entry)
+		}
+		if _, ok := seen[target.id]; ok {
+			return nil, fmt.Errorf("could not use --org: organization %s is listed twice", target.id)
+		}
+		seen[target.id] = struct{}{}
+
+		target.env.OrganizationID = target.id
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// queryOrgs runs req against every target concurrently, each with the
+// querier built for it, and combines the responses in target order.
+func queryOrgs(ctx context.Context, targets []orgTarget, newQuerier func(context.Context, orgTarget) (impactQuerier, error), req footprint.QueryImpactDataRequest) (actualview.OrgReport, error) {
+	orgs := make([]actualview.OrgImpact, len(targets))
+
+	g, ctx := errgroup.WithContext(ctx)
+	for i, target := range targets {
+		g.Go(func() error {
+			querier, err := newQuerier(ctx, target)
+			if err != nil {
+				return err
+			}
+
+			orgReq := req
+			orgReq.OrganizationID = target.id
+
+			resp, err := querier.QueryImpactData(ctx, orgReq)
+			if err != nil {
+				return fmt.Errorf("could not query organization %s: %w", target.id, err)
+			}
+			orgs[i] = actualview.OrgImpact{OrganizationID: target.id, Profile: target.profile, Response: resp}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return actualview.OrgReport{}, err
+	}
+	return actualview.CombineOrgs(orgs), nil
+}
+
+func outputOrgReport(format string, rep actualview.OrgReport, tableOpts report.ActualTableOptions) error {
+	switch normalizeFormat(format) {
+	case "json":
+		return report.PrintOrgJSON(rep)
+	case "csv":
+		return report.PrintOrgCSV(rep)
+	case "table":
+		return report.PrintOrgTable(rep, tableOpts)
+	default:
+		return fmt.Errorf("could not render output format %q (use table, json or csv)", format)
+	}
+}
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/alesr/impact/internal/config"
+	"github.com/alesr/impact/internal/scw/footprint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveOrgTargets(t *testing.T) {
+	t.Parallel()
+
+	env := config.Scaleway{APIBaseURL: "https://api.scaleway.com", AccessKey: "env-ak", SecretKey: "env-sk", OrganizationID: "env-org"}
+
+	loadProfile := func(name string, base config.Scaleway) (config.Scaleway, error) {
+		if name != "bu" {
+			return config.Scaleway{}, errors.New("unknown profile")
+		}
+		base.AccessKey, base.SecretKey, base.OrganizationID = "bu-ak", "bu-sk", "bu-org"
+		return base, nil
+	}
+
+	t.Run("defaults to the environment organization", func(t *testing.T) {
+		t.Parallel()
+
+		targets, err := resolveOrgTargets("", env, loadProfile)
+		require.NoError(t, err)
+		require.Len(t, targets, 1)
+		assert.Equal(t, "env-org", targets[0].id)
+		assert.Equal(t, env, targets[0].env)
+	})
+
+	t.Run("errors without any organization", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := resolveOrgTargets("", config.Scaleway{}, loadProfile)
+		require.Error(t, err)
+	})
+
+	t.Run("mixes environment and profile credentials", func(t *testing.T) {
+		t.Parallel()
+
+		targets, err := resolveOrgTargets("org-a, org-b@bu, @bu", env, func(name string, base config.Scaleway) (config.Scaleway, error) {
+			base.AccessKey = name + "-ak"
+			base.OrganizationID = "default-org"
+			return base, nil
+		})
+		require.NoError(t, err)
+		require.Len(t, targets, 3)
+
+		assert.Equal(t, orgTarget{id: "org-a", env: config.Scaleway{APIBaseURL: env.APIBaseURL, AccessKey: "env-ak", SecretKey: "env-sk", OrganizationID: "org-a"}}, targets[0])
+		assert.Equal(t, "org-b", targets[1].id)
+		assert.Equal(t, "bu", targets[1].profile)
+		assert.Equal(t, "bu-ak", targets[1].env.AccessKey)
+		assert.Equal(t, "org-b", targets[1].env.OrganizationID)
+		assert.Equal(t, "default-org", targets[2].id)
+	})
+
+	t.Run("rejects duplicates and unknown profiles", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := resolveOrgTargets("bu-org,@bu", env, loadProfile)
+		require.ErrorContains(t, err, "listed twice")
+
+		_, err = resolveOrgTargets("org-a@missing", env, loadProfile)
+		require.ErrorContains(t, err, "unknown profile")
+	})
+}
+
+func TestQueryOrgs(t *testing.T) {
+	t.Parallel()
+
+	targets := []orgTarget{{id: "org-a", profile: "a"}, {id: "org-b"}}
+	req := footprint.QueryImpactDataRequest{Regions: []string{"fr-par"}}
+
+	t.Run("combines organizations in order", func(t *testing.T) {
+		t.Parallel()
+
+		var mu sync.Mutex
+		var seen []string
+
+		rep, err := queryOrgs(context.Background(), targets, func(_ context.Context, target orgTarget) (impactQuerier, error) {
+			return stubQuerier(func(req footprint.QueryImpactDataRequest) (*footprint.QueryImpactDataResponse, error) {
+				mu.Lock()
+				seen = append(seen, req.OrganizationID)
+				mu.Unlock()
+
+				assert.Equal(t, target.id, req.OrganizationID)
+				assert.Equal(t, []string{"fr-par"}, req.Regions)
+
+				kg := 1.0
+				if target.id == "org-b" {
+					kg = 2
+				}
+				return &footprint.QueryImpactDataResponse{TotalImpact: footprint.TotalImpact{KgCO2Equivalent: kg}}, nil
+			}), nil
+		}, req)
+		require.NoError(t, err)
+
+		assert.ElementsMatch(t, []string{"org-a", "org-b"}, seen)
+		require.Len(t, rep.Organizations, 2)
+		assert.Equal(t, "org-a", rep.Organizations[0].OrganizationID)
+		assert.Equal(t, "a", rep.Organizations[0].Profile)
+		assert.Equal(t, 3.0, rep.TotalImpact.KgCO2Equivalent)
+	})
+
+	t.Run("names the failing organization", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := queryOrgs(context.Background(), targets, func(_ context.Context, target orgTarget) (impactQuerier, error) {
+			return stubQuerier(func(req footprint.QueryImpactDataRequest) (*footprint.QueryImpactDataResponse, error) {
+				if target.id == "org-b" {
+					return nil, errors.New("forbidden")
+				}
+				return &footprint.QueryImpactDataResponse{}, nil
+			}), nil
+		}, req)
+		require.ErrorContains(t, err, "could not query organization org-b: forbidden")
+	})
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/alesr/impact/internal/estimate"
+	"github.com/alesr/impact/internal/policy"
+	"github.com/spf13/pflag"
+)
+
+const (
+	ExitCodeError  = 1
+	ExitCodePolicy = 3
+)
+
+var ErrPolicyViolation = errors.New("policy check failed")
+
+// ExitCode maps an error returned by Run to the process exit code, so CI can
+// tell a policy failure apart from a runtime error.
+func ExitCode(err error) int {
+	if errors.Is(err, ErrPolicyViolation) {
+		return ExitCodePolicy
+	}
+	return ExitCodeError
+}
+
+type policyFlags struct {
+	file           string
+	maxKgCO2eDelta float64
+	maxM3Delta     float64
+	maxUnknownRows int
+}
+
+func (f *policyFlags) register(flags *pflag.FlagSet) {
+	flags.StringVar(&f.file, "policy", "", "policy file (yaml) with report, per-module and per-resource-type limits")
+	flags.Float64Var(&f.maxKgCO2eDelta, "max-kgco2e-delta", 0, "fail when the total kgCO2e/month delta exceeds this value")
+	flags.Float64Var(&f.maxM3Delta, "max-m3-delta", 0, "fail when the total m3 water/month delta exceeds this value")
+	flags.IntVar(&f.maxUnknownRows, "max-unknown-rows", 0, "fail when more rows than this have unknown footprint data")
+}
+
+// resolve merges the policy file with limit flags; flags set on the command
+// line override the file's report-wide limits.
+func (f *policyFlags) resolve(flags *pflag.FlagSet) (policy.Policy, error) {
+	var p policy.Policy
+	if f.file != "" {
+		loaded, err := policy.LoadFile(f.file)
+		if err != nil {
+			return policy.Policy{}, err
+		}
+		p = loaded
+	}
+
+	if flags.Changed("max-kgco2e-delta") {
+		v := f.maxKgCO2eDelta
+		p.MaxKgCO2eDelta = &v
+	}
+	if flags.Changed("max-m3-delta") {
+		v := f.maxM3Delta
+		p.MaxM3Delta = &v
+	}
+	if flags.Changed("max-unknown-rows") {
+		if f.maxUnknownRows < 0 {
+			return policy.Policy{}, errors.New("could not validate --max-unknown-rows: must not be negative")
+		}
+		v := f.maxUnknownRows
+		p.MaxUnknownRows = &v
+	}
+	return p, nil
+}
+
+// checkPolicy prints budget violations to w and fails when a limit is
+// exceeded or a rule reported an error finding. Findings themselves are part
+// of the report output.
+func checkPolicy(w io.Writer, p policy.Policy, rep estimate.Report) error {
+	if p.IsZero() {
+		return nil
+	}
+
+	violations := policy.Evaluate(p, rep)
+	if len(violations) > 0 {
+		fmt.Fprintf(w, "Policy violations (%d):\n", len(violations))
+		for _, v := range violations {
+			fmt.Fprintf(w, "  - %s\n", v)
+		}
+	}
+
+	ruleErrors := policy.ErrorCount(rep.Findings)
+
+	switch {
+	case len(violations) > 0 && ruleErrors > 0:
+		return fmt.Errorf("%w: %d limit(s) exceeded, %d rule error(s)", ErrPolicyViolation, len(violations), ruleErrors)
+	case len(violations) > 0:
+		return fmt.Errorf("%w: %d limit(s) exceeded", ErrPolicyViolation, len(violations))
+	case ruleErrors > 0:
+		return fmt.Errorf("%w: %d rule error(s)", ErrPolicyViolation, ruleErrors)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alesr/impact/internal/estimate"
+	"github.com/alesr/impact/internal/policy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExitCode(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, ExitCodeError, ExitCode(errors.New("boom")))
+	assert.Equal(t, ExitCodePolicy, ExitCode(ErrPolicyViolation))
+}
+
+func TestPolicyFlagsResolve(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("max_kgco2e_delta: 50\nmax_m3_delta: 2\n"), 0o600))
+
+	var f policyFlags
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	f.register(flags)
+	require.NoError(t, flags.Parse([]string{"--policy", path, "--max-kgco2e-delta", "10", "--max-unknown-rows", "0"}))
+
+	p, err := f.resolve(flags)
+	require.NoError(t, err)
+	require.NotNil(t, p.MaxKgCO2eDelta)
+	assert.Equal(t, 10.0, *p.MaxKgCO2eDelta)
+	require.NotNil(t, p.MaxM3Delta)
+	assert.Equal(t, 2.0, *p.MaxM3Delta)
+	require.NotNil(t, p.MaxUnknownRows)
+	assert.Equal(t, 0, *p.MaxUnknownRows)
+}
+
+func TestCheckPolicy(t *testing.T) {
+	t.Parallel()
+
+	limit := 1.0
+
+	var f policyFlags
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	f.register(flags)
+	p, err := f.resolve(flags)
+	require.NoError(t, err)
+	assert.NoError(t, checkPolicy(&bytes.Buffer{}, p, estimate.Report{Totals: estimate.Totals{KgCO2eMonth: 100}}))
+
+	p.MaxKgCO2eDelta = &limit
+
+	var out bytes.Buffer
+	err = checkPolicy(&out, p, estimate.Report{Totals: estimate.Totals{KgCO2eMonth: 2.5, KgCO2eKnown: true}})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrPolicyViolation)
+	assert.Equal(t, ExitCodePolicy, ExitCode(err))
+	assert.Contains(t, out.String(), "Policy violations (1)")
+	assert.Contains(t, out.String(), "total: kgCO2e/month delta 2.5 exceeds limit 1")
+}
+
+func TestCheckPolicyRuleFindings(t *testing.T) {
+	t.Parallel()
+
+	p := policy.Policy{Rules: []policy.Rule{{Name: "gpu", When: "true", Message: "gpu"}}}
+
+	rep := estimate.Report{Findings: []estimate.Finding{{Rule: "gpu", Severity: policy.SeverityWarning, Message: "gpu"}}}
+	assert.NoError(t, checkPolicy(&bytes.Buffer{}, p, rep))
+
+	rep.Findings = append(rep.Findings, estimate.Finding{Rule: "gpu", Severity: policy.SeverityError, Message: "gpu"})
+	err := checkPolicy(&bytes.Buffer{}, p, rep)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrPolicyViolation)
+	assert.Contains(t, err.Error(), "1 rule error(s)")
+}
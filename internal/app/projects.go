@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/alesr/impact/internal/pkg/strx"
+	"github.com/alesr/impact/internal/projectnames"
+	"github.com/alesr/impact/internal/scw/account"
+	"github.com/alesr/impact/internal/scw/footprint"
+)
+
+type projectNameResolver interface {
+	Names(ctx context.Context) (map[string]string, error)
+	ResolveIDs(ctx context.Context, values []string) ([]string, error)
+}
+
+// resolveProjects turns the --project filter into project IDs and, when
+// withNames is set, loads the names to show next to the measured impact.
+// Failing to load names only prints a warning to w; a filter naming a
+// project that cannot be resolved is an error.
+func resolveProjects(ctx context.Context, resolver projectNameResolver, raw string, withNames bool, w io.Writer) ([]string, map[string]string, error) {
+	values := strx.ParseCSV(raw)
+
+	ids := values
+	if slices.ContainsFunc(values, func(v string) bool { return !projectnames.IsID(v) }) {
+		var err error
+		if ids, err = resolver.ResolveIDs(ctx, values); err != nil {
+			return nil, nil, fmt.Errorf("could not resolve --project: %w", err)
+		}
+	}
+
+	if !withNames {
+		return ids, nil, nil
+	}
+
+	names, err := resolver.Names(ctx)
+	if err != nil {
+		if errors.Is(err, account.ErrPermissionDenied) {
+			fmt.Fprintln(w, "warning: showing project IDs, the API key is not allowed to list projects (needs ProjectReadOnly)")
+		} else {
+			fmt.Fprintf(w, "warning: showing project IDs: %v\n", err)
+		}
+		return ids, nil, nil
+	}
+	return ids, names, nil
+}
+
+// namedQuerier attaches project names to every response.
+type namedQuerier struct {
+	impactQuerier
+	names map[string]string
+}
+
+func (q namedQuerier) QueryImpactData(ctx context.Context, req footprint.QueryImpactDataRequest) (*footprint.QueryImpactDataResponse, error) {
+	resp, err := q.impactQuerier.QueryImpactData(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	projectnames.Attach(resp, q.names)
+	return resp, nil
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/alesr/impact/internal/scw/account"
+	"github.com/alesr/impact/internal/scw/footprint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testProjectID = "11111111-1111-1111-1111-111111111111"
+
+type fakeNameResolver struct {
+	names    map[string]string
+	err      error
+	resolved []string
+}
+
+func (f *fakeNameResolver) Names(context.Context) (map[string]string, error) {
+	return f.names, f.err
+}
+
+func (f *fakeNameResolver) ResolveIDs(_ context.Context, values []string) ([]string, error) {
+	f.resolved = values
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []string{testProjectID}, nil
+}
+
+func TestResolveProjects(t *testing.T) {
+	t.Parallel()
+
+	t.Run("resolves names in the filter and loads names", func(t *testing.T) {
+		t.Parallel()
+
+		resolver := &fakeNameResolver{names: map[string]string{testProjectID: "web"}}
+		var warn bytes.Buffer
+
+		ids, names, err := resolveProjects(context.Background(), resolver, "web", true, &warn)
+		require.NoError(t, err)
+		assert.Equal(t, []string{testProjectID}, ids)
+		assert.Equal(t, "web", names[testProjectID])
+		assert.Equal(t, []string{"web"}, resolver.resolved)
+		assert.Empty(t, warn.String())
+	})
+
+	t.Run("keeps ids without a lookup", func(t *testing.T) {
+		t.Parallel()
+
+		resolver := &fakeNameResolver{}
+		ids, names, err := resolveProjects(context.Background(), resolver, testProjectID, false, &bytes.Buffer{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{testProjectID}, ids)
+		assert.Nil(t, names)
+		assert.Nil(t, resolver.resolved)
+	})
+
+	t.Run("degrades to ids without permission", func(t *testing.T) {
+		t.Parallel()
+
+		resolver := &fakeNameResolver{err: fmt.Errorf("could not list projects: %w", account.ErrPermissionDenied)}
+		var warn bytes.Buffer
+
+		ids, names, err := resolveProjects(context.Background(), resolver, testProjectID, true, &warn)
+		require.NoError(t, err)
+		assert.Equal(t, []string{testProjectID}, ids)
+		assert.Nil(t, names)
+		assert.Contains(t, warn.String(), "not allowed to list projects")
+	})
+
+	t.Run("fails when a name in the filter cannot be resolved", func(t *testing.T) {
+		t.Parallel()
+
+		resolver := &fakeNameResolver{err: errors.New("boom")}
+		_, _, err := resolveProjects(context.Background(), resolver, "web", true, &bytes.Buffer{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "could not resolve --project")
+	})
+}
+
+func TestNamedQuerier(t *testing.T) {
+	t.Parallel()
+
+	querier := namedQuerier{
+		impactQuerier: stubQuerier(func(footprint.QueryImpactDataRequest) (*footprint.QueryImpactDataResponse, error) {
+			return &footprint.QueryImpactDataResponse{Projects: []footprint.ProjectImpact{{ProjectID: testProjectID}}}, nil
+		}),
+		names: map[string]string{testProjectID: "web"},
+	}
+
+	resp, err := querier.QueryImpactData(context.Background(), footprint.QueryImpactDataRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "web", resp.Projects[0].ProjectName)
+}
@@ -0,0 +1,209 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/alesr/impact/internal/estimate"
+	"github.com/alesr/impact/internal/pkg/actualview"
+	"github.com/alesr/impact/internal/pkg/daterange"
+	"github.com/alesr/impact/internal/pkg/strx"
+	"github.com/alesr/impact/internal/plan"
+	"github.com/alesr/impact/internal/reconcile"
+	"github.com/alesr/impact/internal/report"
+	"github.com/alesr/impact/internal/scw/catalog"
+	"github.com/alesr/impact/internal/scw/footprint"
+)
+
+type reconcileOptions struct {
+	planFile string
+	plan     planOptions
+	org      string
+	start    string
+	end      string
+	projects string
+	factor   float64
+	format   string
+}
+
+func newReconcileCmd(root *rootOptions) *cobra.Command {
+	opts := reconcileOptions{plan: planOptions{maxPlanSizeMB: defaultMaxPlanSizeMB}}
+
+	cmd := &cobra.Command{
+		Use:   "reconcile",
+		Short: "compare plan estimates with measured footprint per SKU",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			opts.plan.root = *root
+			return root.withTimeout(cmd.Context(), func(ctx context.Context) error {
+				return runReconcile(ctx, opts)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.planFile, "plan", "", "terraform plan file (show -json output or binary plan) describing the deployed resources")
+	cmd.Flags().BoolVar(&opts.plan.fromTerraform, "from-terraform", false, "read terraform show -json from local terraform command (current state)")
+	cmd.Flags().StringVar(&opts.plan.terraform.bin, "terraform-bin", "", "terraform or tofu binary (defaults to terraform, then tofu, from PATH)")
+	cmd.Flags().StringVar(&opts.plan.terraform.chdir, "chdir", "", "terraform working directory for --from-terraform and binary plan files")
+	cmd.Flags().Int64Var(&opts.plan.maxPlanSizeMB, "max-plan-size", defaultMaxPlanSizeMB, "maximum plan json size in MB (0 disables the limit)")
+	cmd.Flags().StringVar(&opts.org, "org", "", "organization id (defaults to SCW_ORGANIZATION_ID)")
+	cmd.Flags().StringVar(&opts.start, "start", "", "start date of the measured period (YYYY-MM-DD, RFC3339 or relative such as -90d)")
+	cmd.Flags().StringVar(&opts.end, "end", "", "end date of the measured period (YYYY-MM-DD, RFC3339, today or now)")
+	cmd.Flags().StringVar(&opts.projects, "project", "", "comma-separated project IDs filter (defaults to the project_id of the resources)")
+	cmd.Flags().Float64Var(&opts.factor, "factor", reconcile.DefaultFactor, "flag SKUs whose estimate is more than this factor above or below the measurement")
+	cmd.Flags().StringVar(&opts.format, "format", "table", "output format: table|json")
+	opts.plan.estimate.register(cmd.Flags())
+	_ = cmd.MarkFlagFilename("plan")
+	_ = cmd.MarkFlagDirname("chdir")
+	flagChoices(cmd, "format", "table", "json")
+	exclusiveFlags(cmd, []string{"plan"}, []string{"from-terraform"})
+
+	return cmd
+}
+
+func runReconcile(ctx context.Context, opts reconcileOptions) error {
+	if opts.factor <= 1 {
+		return errors.New("could not validate --factor: must be greater than 1")
+	}
+
+	if (opts.planFile == "") == !opts.plan.fromTerraform {
+		return errors.New("could not build reconcile report: provide either --plan or --from-terraform")
+	}
+
+	window, err := reconcileWindow(opts.start, opts.end, time.Now())
+	if err != nil {
+		return err
+	}
+
+	changes, err := loadPlanChanges(ctx, opts.plan, opts.planFile)
+	if err != nil {
+		return err
+	}
+	deployed := reconcile.Deployed(changes)
+
+	estimateOpts, err := opts.plan.estimate.options()
+	if err != nil {
+		return err
+	}
+
+	env, _, err := loadScaleway(opts.plan.root.profile)
+	if err != nil {
+		return err
+	}
+
+	orgID := strings.TrimSpace(opts.org)
+	if orgID == "" {
+		orgID = env.OrganizationID
+	}
+	if orgID == "" {
+		return errors.New("could not resolve organization id (use --org or SCW_ORGANIZATION_ID)")
+	}
+
+	catalogClient, err := newCatalogClient(opts.plan.root, env, apiTimeout)
+	if err != nil {
+		return err
+	}
+
+	footprintClient, err := newFootprintClient(opts.plan.root, env, apiTimeout)
+	if err != nil {
+		return err
+	}
+
+	projectIDs := strx.ParseCSV(opts.projects)
+	if len(projectIDs) == 0 {
+		projectIDs = reconcile.ProjectIDs(deployed)
+	}
+
+	req := reconcileRequest(orgID, projectIDs, deployed, window)
+
+	var rep reconcile.Report
+	if err := runWithSpinner("estimating deployed resources and querying footprint data", func() error {
+		var runErr error
+		rep, runErr = buildReconcileReport(ctx, deployed, opts.plan.estimate.lister(catalogClient, env), footprintClient, req, window, opts.factor, estimateOpts...)
+		return runErr
+	}); err != nil {
+		return err
+	}
+	return outputReconcileReport(opts.format, rep)
+}
+
+// reconcileRequest scopes the footprint query to the regions and zones of
+// the deployed resources.
+func reconcileRequest(orgID string, projectIDs []string, deployed []plan.ResourceChange, window actualview.Window) footprint.QueryImpactDataRequest {
+	return footprint.QueryImpactDataRequest{
+		OrganizationID: orgID,
+		StartDate:      &window.Start,
+		EndDate:        &window.End,
+		ProjectIDs:     projectIDs,
+		Regions:        reconcile.Regions(deployed),
+		Zones:          reconcile.Zones(deployed),
+	}
+}
+
+func reconcileWindow(rawStart, rawEnd string, now time.Time) (actualview.Window, error) {
+	if strings.TrimSpace(rawStart) == "" || strings.TrimSpace(rawEnd) == "" {
+		return actualview.Window{}, errors.New("could not build reconcile report: --start and --end are required")
+	}
+
+	start, err := parseDate(rawStart, now)
+	if err != nil {
+		return actualview.Window{}, fmt.Errorf("could not parse --start: %w", err)
+	}
+	end, err := parseDate(rawEnd, now)
+	if err != nil {
+		return actualview.Window{}, fmt.Errorf("could not parse --end: %w", err)
+	}
+
+	if err := daterange.Validate(start, end); err != nil {
+		return actualview.Window{}, err
+	}
+	return actualview.Window{Start: start, End: end}, nil
+}
+
+// buildReconcileReport estimates the deployed resources and queries the
+// measured footprint concurrently. Regions and projects in req are expected
+// to be scoped to the resources already; SKUs are matched afterwards.
+func buildReconcileReport(ctx context.Context, deployed []plan.ResourceChange, lister catalogProductLister, querier impactQuerier, req footprint.QueryImpactDataRequest, window actualview.Window, factor float64, opts ...estimate.Option) (reconcile.Report, error) {
+	var (
+		products []catalog.Product
+		resp     *footprint.QueryImpactDataResponse
+	)
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		var err error
+		if products, err = lister.ListAllProducts(ctx); err != nil {
+			return fmt.Errorf("could not fetch catalog products: %w", err)
+		}
+		return nil
+	})
+	g.Go(func() error {
+		var err error
+		if resp, err = querier.QueryImpactData(ctx, req); err != nil {
+			return fmt.Errorf("could not query footprint data: %w", err)
+		}
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return reconcile.Report{}, err
+	}
+
+	rows := estimate.Build(deployed, products, opts...).Rows
+	return reconcile.Build(rows, resp, window, factor), nil
+}
+
+func outputReconcileReport(format string, rep reconcile.Report) error {
+	switch normalizeFormat(format) {
+	case "json":
+		return report.PrintReconcileJSON(rep)
+	case "table":
+		return report.PrintReconcileTable(rep)
+	default:
+		return fmt.Errorf("could not render output format %q (use table or json)", format)
+	}
+}
@@ -0,0 +1,163 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alesr/impact/internal/pkg/actualview"
+	"github.com/alesr/impact/internal/plan"
+	"github.com/alesr/impact/internal/reconcile"
+	"github.com/alesr/impact/internal/scw/catalog"
+	"github.com/alesr/impact/internal/scw/footprint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubQuerier func(footprint.QueryImpactDataRequest) (*footprint.QueryImpactDataResponse, error)
+
+func (f stubQuerier) QueryImpactData(_ context.Context, req footprint.QueryImpactDataRequest) (*footprint.QueryImpactDataResponse, error) {
+	return f(req)
+}
+
+func TestReconcileWindow(t *testing.T) {
+	t.Parallel()
+
+	w, err := reconcileWindow("2026-03-01", "2026-04-01", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 31*24*time.Hour, w.End.Sub(w.Start))
+
+	_, err = reconcileWindow("2026-03-01", "", time.Now())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--start and --end are required")
+
+	_, err = reconcileWindow("2026-03-01", "2026-03-01", time.Now())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be before end")
+}
+
+func TestReconcileFromTerraformState(t *testing.T) {
+	t.Parallel()
+
+	state, err := os.ReadFile(filepath.Join("..", "plan", "testdata", "simple_state.json"))
+	require.NoError(t, err)
+	bin, argsFile := fakeBinary(t, "terraform", string(state), 0)
+
+	opts := planOptions{fromTerraform: true, maxPlanSizeMB: defaultMaxPlanSizeMB, terraform: terraformOptions{bin: bin, chdir: "infra"}}
+	changes, err := loadPlanChanges(context.Background(), opts, "")
+	require.NoError(t, err)
+
+	args, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	assert.Equal(t, "-chdir=infra\nshow\n-json\n", string(args))
+
+	deployed := reconcile.Deployed(changes)
+	require.Len(t, deployed, 3)
+	assert.Equal(t, "scaleway_instance_server.web", deployed[0].Address)
+	assert.Equal(t, "module.storage.module.db.scaleway_rdb_instance.main", deployed[2].Address)
+	assert.Equal(t, []string{"0a3d8e5c-0000-4000-8000-000000000001"}, reconcile.ProjectIDs(deployed))
+	assert.Equal(t, []string{"fr-par"}, reconcile.Regions(deployed))
+	assert.Nil(t, reconcile.Zones(deployed), "the regional database leaves zones unfiltered")
+}
+
+func TestBuildReconcileReport(t *testing.T) {
+	t.Parallel()
+
+	const sku = "/compute/pop2_hc_2c_4g/run_fr-par-2"
+	kg := 0.01
+
+	deployed := reconcile.Deployed([]plan.ResourceChange{{
+		Address: "scaleway_instance_server.web",
+		Type:    "scaleway_instance_server",
+		Actions: []string{"no-op"},
+		Before:  map[string]any{"zone": "fr-par-2", "type": "POP2-HC-2C-4G"},
+		After:   map[string]any{"zone": "fr-par-2", "type": "POP2-HC-2C-4G"},
+	}})
+
+	lister := &mockCatalogProductLister{
+		listAllProductsFunc: func(context.Context) ([]catalog.Product, error) {
+			return []catalog.Product{{
+				SKU:                           sku,
+				ProductCategory:               "instances",
+				Locality:                      catalog.Locality{Zone: "fr-par-2"},
+				UnitOfMeasure:                 catalog.UnitOfMeasure{Unit: "hour", Size: 1},
+				EnvironmentalImpactEstimation: &catalog.EnvironmentalEstimation{KgCO2Equivalent: &kg},
+			}}, nil
+		},
+	}
+
+	window := actualview.Window{Start: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)}
+	window.End = window.Start.Add(730 * time.Hour)
+
+	t.Run("compares estimate with measurement", func(t *testing.T) {
+		t.Parallel()
+
+		querier := stubQuerier(func(req footprint.QueryImpactDataRequest) (*footprint.QueryImpactDataResponse, error) {
+			assert.Equal(t, []string{"fr-par"}, req.Regions)
+			return &footprint.QueryImpactDataResponse{Projects: []footprint.ProjectImpact{{
+				ProjectID: "p",
+				Regions: []footprint.RegionImpact{{Region: "fr-par", Zones: []footprint.ZoneImpact{{
+					Zone: "fr-par-2",
+					SKUs: []footprint.SKUImpact{{SKU: sku, TotalSKUImpact: footprint.TotalImpact{KgCO2Equivalent: 1.46}}},
+				}}}},
+			}}}, nil
+		})
+
+		req := footprint.QueryImpactDataRequest{Regions: reconcile.Regions(deployed)}
+		rep, err := buildReconcileReport(context.Background(), deployed, lister, querier, req, window, reconcile.DefaultFactor)
+		require.NoError(t, err)
+
+		require.Len(t, rep.Lines, 1)
+		line := rep.Lines[0]
+		assert.InDelta(t, 7.3, line.EstimatedKgCO2eMonth, 1e-9)
+		assert.InDelta(t, 1.46, line.ActualKgCO2eMonth, 1e-9)
+		require.NotNil(t, line.KgCO2eRatio)
+		assert.InDelta(t, 5, *line.KgCO2eRatio, 1e-9)
+		assert.Equal(t, reconcile.StatusOff, line.Status)
+	})
+
+	t.Run("leaves out usage from other zones", func(t *testing.T) {
+		t.Parallel()
+
+		// The stub filters by zone like the API does.
+		querier := stubQuerier(func(req footprint.QueryImpactDataRequest) (*footprint.QueryImpactDataResponse, error) {
+			var zones []footprint.ZoneImpact
+			for _, zone := range []footprint.ZoneImpact{
+				{Zone: "fr-par-1", SKUs: []footprint.SKUImpact{{SKU: sku, TotalSKUImpact: footprint.TotalImpact{KgCO2Equivalent: 5}}}},
+				{Zone: "fr-par-2", SKUs: []footprint.SKUImpact{{SKU: sku, TotalSKUImpact: footprint.TotalImpact{KgCO2Equivalent: 1.46}}}},
+			} {
+				if len(req.Zones) == 0 || slices.Contains(req.Zones, zone.Zone) {
+					zones = append(zones, zone)
+				}
+			}
+			return &footprint.QueryImpactDataResponse{Projects: []footprint.ProjectImpact{{
+				ProjectID: "p",
+				Regions:   []footprint.RegionImpact{{Region: "fr-par", Zones: zones}},
+			}}}, nil
+		})
+
+		req := reconcileRequest("org", nil, deployed, window)
+		assert.Equal(t, []string{"fr-par-2"}, req.Zones)
+
+		rep, err := buildReconcileReport(context.Background(), deployed, lister, querier, req, window, reconcile.DefaultFactor)
+		require.NoError(t, err)
+		require.Len(t, rep.Lines, 1)
+		assert.InDelta(t, 1.46, rep.Lines[0].ActualKgCO2eMonth, 1e-9)
+	})
+
+	t.Run("returns query errors", func(t *testing.T) {
+		t.Parallel()
+
+		querier := stubQuerier(func(footprint.QueryImpactDataRequest) (*footprint.QueryImpactDataResponse, error) {
+			return nil, errors.New("boom")
+		})
+
+		_, err := buildReconcileReport(context.Background(), deployed, lister, querier, footprint.QueryImpactDataRequest{}, window, reconcile.DefaultFactor)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "could not query footprint data")
+	})
+}
@@ -0,0 +1,187 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alesr/impact/internal/estimate"
+	"github.com/alesr/impact/internal/plan"
+)
+
+const (
+	terragruntConfigFile  = "terragrunt.hcl"
+	terragruntCacheDir    = ".terragrunt-cache"
+	defaultTerragruntBin  = "terragrunt"
+	defaultTerragruntPlan = "tfplan"
+)
+
+type terragruntOptions struct {
+	dir      string
+	bin      string
+	planName string
+}
+
+// loadTerragruntInputs builds one estimate input per terragrunt unit, keyed by
+// the unit path relative to the root directory. A unit uses an existing
+// <plan>.json when one is found in the unit or its cache and is not older
+// than the plan, and falls back to `terragrunt show -json <plan>` otherwise.
+func loadTerragruntInputs(ctx context.Context, opts planOptions) ([]estimate.Input, error) {
+	tg := opts.terragrunt
+
+	units, err := discoverTerragruntUnits(tg.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	parseOpts := []plan.Option{plan.WithMaxBytes(opts.maxPlanSizeMB << 20)}
+
+	inputs := make([]estimate.Input, 0, len(units))
+	for _, unit := range units {
+		source, err := filepath.Rel(tg.dir, unit)
+		if err != nil {
+			source = unit
+		}
+
+		changes, err := loadTerragruntUnit(ctx, tg, unit, parseOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("terragrunt unit %s: %w", source, err)
+		}
+		inputs = append(inputs, estimate.Input{Source: filepath.ToSlash(source), Changes: changes})
+	}
+	return inputs, nil
+}
+
+func loadTerragruntUnit(ctx context.Context, tg terragruntOptions, unit string, parseOpts ...plan.Option) ([]plan.ResourceChange, error) {
+	planName := tg.planName
+	if planName == "" {
+		planName = defaultTerragruntPlan
+	}
+
+	jsonPath, err := findTerragruntPlanJSON(unit, planName)
+	if err != nil {
+		return nil, err
+	}
+	if jsonPath != "" {
+		return plan.ParseFile(jsonPath, parseOpts...)
+	}
+
+	bin := strings.TrimSpace(tg.bin)
+	if bin == "" {
+		bin = defaultTerragruntBin
+	}
+
+	ctx, cancel := showContext(ctx)
+	defer cancel()
+
+	return runShowJSON(ctx, bin, unit, []string{"show", "-json", planName}, parseOpts...)
+}
+
+// discoverTerragruntUnits returns every directory under root holding a
+// terragrunt.hcl, skipping caches and hidden directories. The root itself is
+// only a unit when nothing below it is, since it usually holds shared config.
+func discoverTerragruntUnits(root string) ([]string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("could not read terragrunt directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("could not read terragrunt directory: %s is not a directory", root)
+	}
+
+	root = filepath.Clean(root)
+
+	var units []string
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && (d.Name() == terragruntCacheDir || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() == terragruntConfigFile {
+			units = append(units, filepath.Dir(path))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not discover terragrunt units: %w", err)
+	}
+
+	if len(units) > 1 {
+		units = slices.DeleteFunc(units, func(unit string) bool { return unit == root })
+	}
+	if len(units) == 0 {
+		return nil, fmt.Errorf("could not discover terragrunt units: no %s found under %s", terragruntConfigFile, root)
+	}
+
+	slices.Sort(units)
+	return units, nil
+}
+
+// findTerragruntPlanJSON returns the most recent <plan>.json of the unit, or
+// "" when there is none or when a <plan> file was written after it, in which
+// case the JSON no longer matches the plan.
+func findTerragruntPlanJSON(unit, planName string) (string, error) {
+	jsonPath, jsonTime, err := newestTerragruntFile(unit, planName+".json")
+	if err != nil || jsonPath == "" {
+		return "", err
+	}
+
+	planPath, planTime, err := newestTerragruntFile(unit, planName)
+	if err != nil {
+		return "", err
+	}
+	if planPath != "" && planTime.After(jsonTime) {
+		return "", nil
+	}
+	return jsonPath, nil
+}
+
+// newestTerragruntFile returns the most recent file called name in the unit
+// directory or inside the unit's terragrunt cache.
+func newestTerragruntFile(unit, name string) (string, time.Time, error) {
+	var (
+		newest     string
+		newestTime time.Time
+	)
+	if info, err := os.Stat(filepath.Join(unit, name)); err == nil && !info.IsDir() {
+		newest, newestTime = filepath.Join(unit, name), info.ModTime()
+	}
+
+	cache := filepath.Join(unit, terragruntCacheDir)
+	if _, err := os.Stat(cache); errors.Is(err, fs.ErrNotExist) {
+		return newest, newestTime, nil
+	}
+
+	err := filepath.WalkDir(cache, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Name() != name {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if newest == "" || info.ModTime().After(newestTime) {
+			newest, newestTime = path, info.ModTime()
+		}
+		return nil
+	})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("could not search terragrunt cache: %w", err)
+	}
+	return newest, newestTime, nil
+}
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverTerragruntUnits(t *testing.T) {
+	t.Parallel()
+
+	t.Run("finds nested units and skips caches and the root config", func(t *testing.T) {
+		t.Parallel()
+
+		root := t.TempDir()
+		writeTestFile(t, filepath.Join(root, "terragrunt.hcl"), "")
+		writeTestFile(t, filepath.Join(root, "prod", "app", "terragrunt.hcl"), "")
+		writeTestFile(t, filepath.Join(root, "prod", "network", "terragrunt.hcl"), "")
+		writeTestFile(t, filepath.Join(root, "prod", "app", ".terragrunt-cache", "x", "terragrunt.hcl"), "")
+
+		units, err := discoverTerragruntUnits(root)
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			filepath.Join(root, "prod", "app"),
+			filepath.Join(root, "prod", "network"),
+		}, units)
+	})
+
+	t.Run("treats a lone root config as the unit", func(t *testing.T) {
+		t.Parallel()
+
+		root := t.TempDir()
+		writeTestFile(t, filepath.Join(root, "terragrunt.hcl"), "")
+
+		units, err := discoverTerragruntUnits(root)
+		require.NoError(t, err)
+		assert.Equal(t, []string{root}, units)
+	})
+
+	t.Run("fails when no unit exists", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := discoverTerragruntUnits(t.TempDir())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no terragrunt.hcl found")
+	})
+}
+
+func TestLoadTerragruntInputs(t *testing.T) {
+	t.Parallel()
+
+	planJSON := `{"format_version": "1.2", "resource_changes": [{"address": "scaleway_lb.edge", "type": "scaleway_lb", "change": {"actions": ["create"], "before": null, "after": {"type": "LB-S"}}}]}`
+
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "app", "terragrunt.hcl"), "")
+	writeTestFile(t, filepath.Join(root, "network", "terragrunt.hcl"), "")
+	writeTestFile(t, filepath.Join(root, "network", ".terragrunt-cache", "abc", "def", "tfplan.json"), planJSON)
+
+	bin, argsFile := fakeBinary(t, "terragrunt", planJSON, 0)
+
+	inputs, err := loadTerragruntInputs(context.Background(), planOptions{
+		terragrunt: terragruntOptions{dir: root, bin: bin, planName: "tfplan"},
+	})
+	require.NoError(t, err)
+	require.Len(t, inputs, 2)
+
+	assert.Equal(t, "app", inputs[0].Source)
+	require.Len(t, inputs[0].Changes, 1)
+	assert.Equal(t, "network", inputs[1].Source)
+	require.Len(t, inputs[1].Changes, 1)
+
+	// only the unit without a cached plan json shells out
+	args, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	assert.Equal(t, "show\n-json\ntfplan\n", string(args))
+}
+
+func TestFindTerragruntPlanJSON(t *testing.T) {
+	t.Parallel()
+
+	older := time.Now().Add(-time.Hour)
+
+	t.Run("uses the newest json of the unit and its cache", func(t *testing.T) {
+		t.Parallel()
+
+		unit := t.TempDir()
+		direct := filepath.Join(unit, "tfplan.json")
+		cached := filepath.Join(unit, ".terragrunt-cache", "abc", "tfplan.json")
+		writeTestFile(t, direct, "{}")
+		writeTestFile(t, cached, "{}")
+		writeTestFile(t, filepath.Join(unit, ".terragrunt-cache", "abc", "tfplan"), "")
+		require.NoError(t, os.Chtimes(direct, older, older))
+		require.NoError(t, os.Chtimes(filepath.Join(unit, ".terragrunt-cache", "abc", "tfplan"), older, older))
+
+		path, err := findTerragruntPlanJSON(unit, "tfplan")
+		require.NoError(t, err)
+		assert.Equal(t, cached, path)
+	})
+
+	t.Run("ignores a json older than the plan", func(t *testing.T) {
+		t.Parallel()
+
+		unit := t.TempDir()
+		stale := filepath.Join(unit, "tfplan.json")
+		writeTestFile(t, stale, "{}")
+		writeTestFile(t, filepath.Join(unit, ".terragrunt-cache", "abc", "tfplan"), "")
+		require.NoError(t, os.Chtimes(stale, older, older))
+
+		path, err := findTerragruntPlanJSON(unit, "tfplan")
+		require.NoError(t, err)
+		assert.Empty(t, path)
+	})
+
+	t.Run("finds nothing without a json", func(t *testing.T) {
+		t.Parallel()
+
+		unit := t.TempDir()
+		writeTestFile(t, filepath.Join(unit, "tfplan"), "")
+
+		path, err := findTerragruntPlanJSON(unit, "tfplan")
+		require.NoError(t, err)
+		assert.Empty(t, path)
+	})
+}
+
+func TestLoadTerragruntInputsReportsUnitErrors(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "app", "terragrunt.hcl"), "")
+
+	bin, _ := fakeBinary(t, "terragrunt", "", 1)
+
+	_, err := loadTerragruntInputs(context.Background(), planOptions{terragrunt: terragruntOptions{dir: root, bin: bin}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "terragrunt unit app")
+	assert.Contains(t, err.Error(), "fake terragrunt failed")
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
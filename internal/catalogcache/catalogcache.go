@@ -0,0 +1,111 @@
+package catalogcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/alesr/impact/internal/scw/catalog"
+)
+
+type ProductLister interface {
+	ListAllProducts(ctx context.Context) ([]catalog.Product, error)
+}
+
+// Lister serves the product catalog from disk while it is fresh, so repeated
+// runs do not page through the whole catalog again.
+type Lister struct {
+	lister  ProductLister
+	baseURL string
+	opts    options
+}
+
+type cacheFile struct {
+	FetchedAt time.Time         `json:"fetched_at"`
+	Products  []catalog.Product `json:"products"`
+}
+
+// New caches the products of lister, keyed by the API base URL they come
+// from.
+func New(lister ProductLister, baseURL string, opts ...Option) *Lister {
+	cfg := options{now: time.Now}
+	if dir, err := os.UserCacheDir(); err == nil {
+		cfg.cacheDir = filepath.Join(dir, "impact")
+	}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(&cfg)
+	}
+	return &Lister{lister: lister, baseURL: baseURL, opts: cfg}
+}
+
+func (l *Lister) ListAllProducts(ctx context.Context) ([]catalog.Product, error) {
+	if products, ok := l.readCache(); ok {
+		return products, nil
+	}
+
+	products, err := l.lister.ListAllProducts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	l.writeCache(products)
+	return products, nil
+}
+
+func (l *Lister) cachePath() string {
+	if l.opts.cacheDir == "" || l.opts.ttl <= 0 {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(l.baseURL))
+	return filepath.Join(l.opts.cacheDir, "catalog-"+hex.EncodeToString(sum[:8])+".json")
+}
+
+func (l *Lister) readCache() ([]catalog.Product, bool) {
+	path := l.cachePath()
+	if path == "" {
+		return nil, false
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var cached cacheFile
+	if err := json.Unmarshal(b, &cached); err != nil || cached.Products == nil {
+		return nil, false
+	}
+	if l.opts.now().Sub(cached.FetchedAt) > l.opts.ttl {
+		return nil, false
+	}
+	return cached.Products, true
+}
+
+// writeCache is best effort: a read-only cache directory only costs a full
+// catalog fetch on the next run.
+func (l *Lister) writeCache(products []catalog.Product) {
+	path := l.cachePath()
+	if path == "" {
+		return
+	}
+
+	b, err := json.Marshal(cacheFile{FetchedAt: l.opts.now().UTC(), Products: products})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return
+	}
+	_ = os.Rename(tmp, path)
+}
@@ -0,0 +1,80 @@
+package catalogcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alesr/impact/internal/scw/catalog"
+)
+
+type fakeLister struct {
+	calls    int
+	products []catalog.Product
+	err      error
+}
+
+func (f *fakeLister) ListAllProducts(context.Context) ([]catalog.Product, error) {
+	f.calls++
+	return f.products, f.err
+}
+
+func TestLister(t *testing.T) {
+	t.Parallel()
+
+	products := []catalog.Product{{SKU: "/compute/dev1-s/run_fr-par-1", Locality: catalog.Locality{Zone: "fr-par-1"}}}
+
+	t.Run("caches products on disk until the ttl expires", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+		clock := func() time.Time { return now }
+		lister := &fakeLister{products: products}
+		newLister := func(baseURL string) *Lister {
+			return New(lister, baseURL, WithCacheDir(dir), WithTTL(time.Hour), WithClock(clock))
+		}
+
+		got, err := newLister("https://api.scaleway.com").ListAllProducts(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, products, got)
+
+		got, err = newLister("https://api.scaleway.com").ListAllProducts(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, products, got)
+		assert.Equal(t, 1, lister.calls)
+
+		_, err = newLister("http://127.0.0.1:8787").ListAllProducts(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 2, lister.calls, "each API base URL has its own cache")
+
+		now = now.Add(2 * time.Hour)
+		_, err = newLister("https://api.scaleway.com").ListAllProducts(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 3, lister.calls)
+	})
+
+	t.Run("does not cache without a ttl", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		lister := &fakeLister{products: products}
+		for range 2 {
+			_, err := New(lister, "https://api.scaleway.com", WithCacheDir(dir)).ListAllProducts(context.Background())
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, lister.calls)
+	})
+
+	t.Run("returns lister errors", func(t *testing.T) {
+		t.Parallel()
+
+		lister := &fakeLister{err: errors.New("boom")}
+		_, err := New(lister, "https://api.scaleway.com", WithCacheDir(t.TempDir()), WithTTL(time.Hour)).ListAllProducts(context.Background())
+		require.EqualError(t, err, "boom")
+	})
+}
@@ -0,0 +1,33 @@
+package catalogcache
+
+import "time"
+
+type Option func(*options)
+
+type options struct {
+	cacheDir string
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+// WithCacheDir stores the cached catalog in dir. An empty dir disables the
+// disk cache.
+func WithCacheDir(dir string) Option {
+	return func(opts *options) {
+		opts.cacheDir = dir
+	}
+}
+
+// WithTTL keeps a cached catalog for ttl. Zero, the default, disables the
+// cache.
+func WithTTL(ttl time.Duration) Option {
+	return func(opts *options) {
+		opts.ttl = ttl
+	}
+}
+
+func WithClock(now func() time.Time) Option {
+	return func(opts *options) {
+		opts.now = now
+	}
+}
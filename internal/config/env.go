@@ -1,32 +1,145 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
+	"os"
 
+	"github.com/alesr/impact/internal/pkg/netx"
 	"github.com/caarlos0/env/v11"
+	"github.com/scaleway/scaleway-sdk-go/scw"
+)
+
+const defaultAPIBaseURL = "https://api.scaleway.com"
+
+const (
+	SourceDefault = "default"
+	SourceUnset   = "unset"
 )
 
 type Scaleway struct {
-	APIBaseURL     string `env:"IMPACT_SCW_API_BASE_URL" envDefault:"https://api.scaleway.com"`
+	APIBaseURL     string `env:"IMPACT_SCW_API_BASE_URL"`
 	AccessKey      string `env:"SCW_ACCESS_KEY"`
 	SecretKey      string `env:"SCW_SECRET_KEY"`
 	OrganizationID string `env:"SCW_ORGANIZATION_ID"`
+
+	// AllowInsecureBaseURL accepts http base URLs on any host; loopback
+	// hosts are always accepted.
+	AllowInsecureBaseURL bool   `env:"IMPACT_ALLOW_INSECURE_BASE_URL"`
+	CABundle             string `env:"IMPACT_CA_BUNDLE"`
+	ProxyURL             string `env:"IMPACT_PROXY_URL"`
+}
+
+// Sources records where each Scaleway value came from: "env <VAR>",
+// "profile <name>", SourceDefault or SourceUnset. Profile is the profile that
+// was read, empty when there is no config file.
+type Sources struct {
+	Profile        string
+	APIBaseURL     string
+	AccessKey      string
+	SecretKey      string
+	OrganizationID string
 }
 
-func LoadScalewayFromEnv() (Scaleway, error) {
-	var cfg Scaleway
-	if err := env.Parse(&cfg); err != nil {
-		return Scaleway{}, fmt.Errorf("could not parse env config: %w", err)
+// LoadScaleway reads the profile from the Scaleway CLI config file
+// (~/.config/scw/config.yaml, or SCW_CONFIG_PATH) and overrides it with the
+// environment. The profile is profile when set, then SCW_PROFILE, then the
+// active_profile of the file. A missing config file is only an error when a
+// profile was asked for.
+func LoadScaleway(profile string) (Scaleway, Sources, error) {
+	return loadScaleway(profile, nil, scw.LoadConfig)
+}
+
+// loadScaleway reads the environment from environ, or from the process when
+// environ is nil.
+func loadScaleway(profile string, environ map[string]string, loadConfig func() (*scw.Config, error)) (Scaleway, Sources, error) {
+	if environ == nil {
+		environ = env.ToMap(os.Environ())
+	}
+
+	var fromEnv Scaleway
+	if err := env.ParseWithOptions(&fromEnv, env.Options{Environment: environ}); err != nil {
+		return Scaleway{}, Sources{}, fmt.Errorf("could not parse env config: %w", err)
+	}
+
+	cfg := Scaleway{
+		APIBaseURL:           defaultAPIBaseURL,
+		AllowInsecureBaseURL: fromEnv.AllowInsecureBaseURL,
+		CABundle:             fromEnv.CABundle,
+		ProxyURL:             fromEnv.ProxyURL,
 	}
+	src := Sources{APIBaseURL: SourceDefault, AccessKey: SourceUnset, SecretKey: SourceUnset, OrganizationID: SourceUnset}
+
+	name := profile
+	if name == "" {
+		name = environ[scw.ScwActiveProfileEnv]
+	}
+
+	file, err := loadConfig()
+	var notFound *scw.ConfigFileNotFoundError
+	switch {
+	case errors.As(err, &notFound):
+		if name != "" {
+			return Scaleway{}, Sources{}, fmt.Errorf("could not load profile %q: %w", name, err)
+		}
+	case err != nil:
+		return Scaleway{}, Sources{}, fmt.Errorf("could not load scaleway config file: %w", err)
+	default:
+		if name == "" {
+			name = scw.DefaultProfileName
+			if file.ActiveProfile != nil && *file.ActiveProfile != "" {
+				name = *file.ActiveProfile
+			}
+		}
+		if cfg, err = profileFromConfig(file, name, cfg, &src); err != nil {
+			return Scaleway{}, Sources{}, err
+		}
+		src.Profile = name
+	}
+
+	if fromEnv.APIBaseURL != "" {
+		cfg.APIBaseURL, src.APIBaseURL = fromEnv.APIBaseURL, "env IMPACT_SCW_API_BASE_URL"
+		if err := validateBaseURL(cfg.APIBaseURL, cfg.AllowInsecureBaseURL); err != nil {
+			return Scaleway{}, Sources{}, fmt.Errorf("could not validate IMPACT_SCW_API_BASE_URL: %w", err)
+		}
+	}
+	if fromEnv.AccessKey != "" {
+		cfg.AccessKey, src.AccessKey = fromEnv.AccessKey, "env SCW_ACCESS_KEY"
+	}
+	if fromEnv.SecretKey != "" {
+		cfg.SecretKey, src.SecretKey = fromEnv.SecretKey, "env SCW_SECRET_KEY"
+	}
+	if fromEnv.OrganizationID != "" {
+		cfg.OrganizationID, src.OrganizationID = fromEnv.OrganizationID, "env SCW_ORGANIZATION_ID"
+	}
+	return cfg, src, nil
+}
+
+// InsecureBaseURL reports whether APIBaseURL is plain http on a host other
+// than loopback, which only AllowInsecureBaseURL lets through.
+func (s Scaleway) InsecureBaseURL() bool {
+	baseURL, err := url.Parse(s.APIBaseURL)
+	return err == nil && baseURL.Scheme == "http" && !netx.IsLoopback(baseURL.Hostname())
+}
 
-	baseURL, err := url.Parse(cfg.APIBaseURL)
+// validateBaseURL requires https, except for loopback hosts such as a local
+// API stand-in, or for any host when allowInsecure is set.
+func validateBaseURL(raw string, allowInsecure bool) error {
+	baseURL, err := url.Parse(raw)
 	if err != nil || baseURL.Scheme == "" || baseURL.Host == "" {
-		return Scaleway{}, fmt.Errorf("could not validate IMPACT_SCW_API_BASE_URL: must be a valid absolute URL")
+		return errors.New("must be a valid absolute URL")
 	}
 
-	if baseURL.Scheme != "https" {
-		return Scaleway{}, fmt.Errorf("could not validate IMPACT_SCW_API_BASE_URL: https scheme is required")
+	switch baseURL.Scheme {
+	case "https":
+		return nil
+	case "http":
+		if allowInsecure || netx.IsLoopback(baseURL.Hostname()) {
+			return nil
+		}
+		return errors.New("https scheme is required (set IMPACT_ALLOW_INSECURE_BASE_URL=true to allow http)")
+	default:
+		return errors.New("https scheme is required")
 	}
-	return cfg, nil
 }
@@ -1,20 +1,29 @@
 package config
 
 import (
+	"errors"
+	"path/filepath"
 	"testing"
 
+	"github.com/scaleway/scaleway-sdk-go/scw"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func TestLoadScalewayFromEnv(t *testing.T) {
+func TestLoadScaleway(t *testing.T) {
+	noConfigFile := func(t *testing.T) {
+		t.Setenv("SCW_CONFIG_PATH", filepath.Join(t.TempDir(), "missing.yaml"))
+		t.Setenv("SCW_PROFILE", "")
+	}
+
 	t.Run("returns defaults when env is empty", func(t *testing.T) {
+		noConfigFile(t)
 		t.Setenv("IMPACT_SCW_API_BASE_URL", "")
 		t.Setenv("SCW_ACCESS_KEY", "")
 		t.Setenv("SCW_SECRET_KEY", "")
 		t.Setenv("SCW_ORGANIZATION_ID", "")
 
-		cfg, err := LoadScalewayFromEnv()
+		cfg, _, err := LoadScaleway("")
 		require.NoError(t, err)
 		assert.Equal(t, "https://api.scaleway.com", cfg.APIBaseURL)
 		assert.Empty(t, cfg.AccessKey)
@@ -23,12 +32,13 @@ func TestLoadScalewayFromEnv(t *testing.T) {
 	})
 
 	t.Run("reads configured values from env", func(t *testing.T) {
+		noConfigFile(t)
 		t.Setenv("IMPACT_SCW_API_BASE_URL", "https://example.invalid")
 		t.Setenv("SCW_ACCESS_KEY", "SCWXXXXXXXXXXXXXXXXX")
 		t.Setenv("SCW_SECRET_KEY", "secret")
 		t.Setenv("SCW_ORGANIZATION_ID", "org-id")
 
-		cfg, err := LoadScalewayFromEnv()
+		cfg, _, err := LoadScaleway("")
 		require.NoError(t, err)
 		assert.Equal(t, "https://example.invalid", cfg.APIBaseURL)
 		assert.Equal(t, "SCWXXXXXXXXXXXXXXXXX", cfg.AccessKey)
@@ -37,18 +47,156 @@ func TestLoadScalewayFromEnv(t *testing.T) {
 	})
 
 	t.Run("returns error for invalid base url", func(t *testing.T) {
+		noConfigFile(t)
 		t.Setenv("IMPACT_SCW_API_BASE_URL", "not-a-url")
 
-		_, err := LoadScalewayFromEnv()
+		_, _, err := LoadScaleway("")
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "could not validate IMPACT_SCW_API_BASE_URL")
 	})
 
 	t.Run("returns error for non-https base url", func(t *testing.T) {
+		noConfigFile(t)
 		t.Setenv("IMPACT_SCW_API_BASE_URL", "http://api.scaleway.com")
 
-		_, err := LoadScalewayFromEnv()
+		_, _, err := LoadScaleway("")
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "https scheme is required")
 	})
 }
+
+func TestLoadScalewayLayers(t *testing.T) {
+	t.Parallel()
+
+	str := func(v string) *string { return &v }
+	file := func() (*scw.Config, error) {
+		return &scw.Config{
+			Profile:       scw.Profile{AccessKey: str("SCWROOTXXXXXXXXXXXXX"), SecretKey: str("root-secret")},
+			ActiveProfile: str("bu-a"),
+			Profiles: map[string]*scw.Profile{
+				"bu-a": {DefaultOrganizationID: str("org-a")},
+				"bu-b": {DefaultOrganizationID: str("org-b"), SecretKey: str("secret-b")},
+			},
+		}, nil
+	}
+	missing := func() (*scw.Config, error) {
+		return nil, &scw.ConfigFileNotFoundError{}
+	}
+
+	t.Run("uses the active profile of the file", func(t *testing.T) {
+		t.Parallel()
+
+		cfg, src, err := loadScaleway("", map[string]string{}, file)
+		require.NoError(t, err)
+		assert.Equal(t, Scaleway{APIBaseURL: "https://api.scaleway.com", AccessKey: "SCWROOTXXXXXXXXXXXXX", SecretKey: "root-secret", OrganizationID: "org-a"}, cfg)
+		assert.Equal(t, Sources{Profile: "bu-a", APIBaseURL: SourceDefault, AccessKey: "profile default", SecretKey: "profile default", OrganizationID: "profile bu-a"}, src)
+	})
+
+	t.Run("flag beats SCW_PROFILE and env beats the profile", func(t *testing.T) {
+		t.Parallel()
+
+		environ := map[string]string{"SCW_PROFILE": "bu-a", "SCW_ORGANIZATION_ID": "org-env"}
+
+		cfg, src, err := loadScaleway("bu-b", environ, file)
+		require.NoError(t, err)
+		assert.Equal(t, "secret-b", cfg.SecretKey)
+		assert.Equal(t, "org-env", cfg.OrganizationID)
+		assert.Equal(t, "bu-b", src.Profile)
+		assert.Equal(t, "profile bu-b", src.SecretKey)
+		assert.Equal(t, "profile default", src.AccessKey, "inherited from the root of the file")
+		assert.Equal(t, "env SCW_ORGANIZATION_ID", src.OrganizationID)
+
+		_, src, err = loadScaleway("", environ, file)
+		require.NoError(t, err)
+		assert.Equal(t, "bu-a", src.Profile)
+	})
+
+	t.Run("missing file is only an error for an explicit profile", func(t *testing.T) {
+		t.Parallel()
+
+		_, src, err := loadScaleway("", map[string]string{}, missing)
+		require.NoError(t, err)
+		assert.Empty(t, src.Profile)
+		assert.Equal(t, SourceUnset, src.AccessKey)
+
+		_, _, err = loadScaleway("", map[string]string{"SCW_PROFILE": "bu-a"}, missing)
+		require.ErrorContains(t, err, `could not load profile "bu-a"`)
+
+		_, _, err = loadScaleway("", map[string]string{}, func() (*scw.Config, error) { return nil, errors.New("bad yaml") })
+		require.ErrorContains(t, err, "could not load scaleway config file")
+	})
+}
+
+func TestValidateBaseURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		raw           string
+		allowInsecure bool
+		wantErr       string
+	}{
+		{raw: "https://api.scaleway.com"},
+		{raw: "http://localhost:8080"},
+		{raw: "http://127.0.0.1:8080"},
+		{raw: "http://[::1]:8080"},
+		{raw: "http://mirror.internal", wantErr: "IMPACT_ALLOW_INSECURE_BASE_URL"},
+		{raw: "http://mirror.internal", allowInsecure: true},
+		{raw: "ftp://mirror.internal", allowInsecure: true, wantErr: "https scheme is required"},
+		{raw: "localhost:8080", wantErr: "must be a valid absolute URL"},
+	}
+
+	for _, tt := range tests {
+		err := validateBaseURL(tt.raw, tt.allowInsecure)
+		if tt.wantErr == "" {
+			assert.NoError(t, err, tt.raw)
+			continue
+		}
+		require.Error(t, err, tt.raw)
+		assert.Contains(t, err.Error(), tt.wantErr)
+	}
+}
+
+func TestInsecureBaseURL(t *testing.T) {
+	t.Parallel()
+
+	for raw, want := range map[string]bool{
+		"https://api.scaleway.com": false,
+		"http://localhost:8080":    false,
+		"http://[::1]:8080":        false,
+		"http://mirror.internal":   true,
+		"http://10.0.0.5:8080":     true,
+	} {
+		assert.Equal(t, want, Scaleway{APIBaseURL: raw}.InsecureBaseURL(), raw)
+	}
+}
+
+func TestLoadScalewayTransport(t *testing.T) {
+	t.Parallel()
+
+	missing := func() (*scw.Config, error) { return nil, &scw.ConfigFileNotFoundError{} }
+	str := func(v string) *string { return &v }
+
+	cfg, _, err := loadScaleway("", map[string]string{
+		"IMPACT_SCW_API_BASE_URL":        "http://mirror.internal",
+		"IMPACT_ALLOW_INSECURE_BASE_URL": "true",
+		"IMPACT_CA_BUNDLE":               "/etc/impact/ca.pem",
+		"IMPACT_PROXY_URL":               "http://proxy.internal:3128",
+	}, missing)
+	require.NoError(t, err)
+	assert.Equal(t, "http://mirror.internal", cfg.APIBaseURL)
+	assert.Equal(t, "/etc/impact/ca.pem", cfg.CABundle)
+	assert.Equal(t, "http://proxy.internal:3128", cfg.ProxyURL)
+
+	file := func() (*scw.Config, error) {
+		return &scw.Config{Profile: scw.Profile{APIURL: str("http://mirror.internal")}}, nil
+	}
+	_, _, err = loadScaleway("", map[string]string{}, file)
+	require.ErrorContains(t, err, "IMPACT_ALLOW_INSECURE_BASE_URL")
+
+	cfg, _, err = loadScaleway("", map[string]string{"IMPACT_ALLOW_INSECURE_BASE_URL": "true"}, file)
+	require.NoError(t, err, "the opt-in applies to profile api urls too")
+	assert.Equal(t, "http://mirror.internal", cfg.APIBaseURL)
+
+	_, _, err = loadScaleway("", map[string]string{"IMPACT_ALLOW_INSECURE_BASE_URL": "maybe"}, missing)
+	require.ErrorContains(t, err, "could not parse env config")
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/scaleway/scaleway-sdk-go/scw"
+)
+
+// LoadProfile returns base with the credentials, default organization and API
+// URL of a named profile from the Scaleway CLI config file
+// (~/.config/scw/config.yaml, or SCW_CONFIG_PATH). Fields the profile does not
+// set keep their value from base.
+func LoadProfile(name string, base Scaleway) (Scaleway, error) {
+	cfg, err := scw.LoadConfig()
+	if err != nil {
+		return Scaleway{}, fmt.Errorf("could not load scaleway config file: %w", err)
+	}
+	return profileFromConfig(cfg, name, base, &Sources{})
+}
+
+// profileFromConfig merges the named profile over base and records the
+// fields it set in src. Fields a named profile inherits from the root of the
+// file are labelled "profile default", like the CLI names that profile.
+func profileFromConfig(cfg *scw.Config, name string, base Scaleway, src *Sources) (Scaleway, error) {
+	profile, err := cfg.GetProfile(name)
+	if err != nil {
+		return Scaleway{}, fmt.Errorf("could not load profile %q: %w", name, err)
+	}
+
+	own := cfg.Profiles[name]
+	if name == scw.DefaultProfileName || own == nil {
+		own = &cfg.Profile
+	}
+	label := func(set *string) string {
+		if set != nil && *set != "" {
+			return "profile " + name
+		}
+		return "profile " + scw.DefaultProfileName
+	}
+
+	out := base
+	if profile.AccessKey != nil && *profile.AccessKey != "" {
+		out.AccessKey, src.AccessKey = *profile.AccessKey, label(own.AccessKey)
+	}
+	if profile.SecretKey != nil && *profile.SecretKey != "" {
+		out.SecretKey, src.SecretKey = *profile.SecretKey, label(own.SecretKey)
+	}
+	if profile.DefaultOrganizationID != nil && *profile.DefaultOrganizationID != "" {
+		out.OrganizationID, src.OrganizationID = *profile.DefaultOrganizationID, label(own.DefaultOrganizationID)
+	}
+	if profile.APIURL != nil && *profile.APIURL != "" {
+		out.APIBaseURL, src.APIBaseURL = *profile.APIURL, label(own.APIURL)
+		if err := validateBaseURL(out.APIBaseURL, out.AllowInsecureBaseURL); err != nil {
+			return Scaleway{}, fmt.Errorf("could not validate api_url of profile %q: %w", name, err)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/scaleway/scaleway-sdk-go/scw"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileFromConfig(t *testing.T) {
+	t.Parallel()
+
+	str := func(v string) *string { return &v }
+	cfg := &scw.Config{
+		Profile: scw.Profile{AccessKey: str("SCWROOTXXXXXXXXXXXXX"), SecretKey: str("root-secret")},
+		Profiles: map[string]*scw.Profile{
+			"bu-a": {DefaultOrganizationID: str("org-a"), SecretKey: str("secret-a")},
+			"bad":  {APIURL: str("http://api.example.com")},
+		},
+	}
+	base := Scaleway{APIBaseURL: "https://api.scaleway.com", AccessKey: "SCWENVXXXXXXXXXXXXXX"}
+
+	t.Run("merges the profile over the default profile and base", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := profileFromConfig(cfg, "bu-a", base, &Sources{})
+		require.NoError(t, err)
+		assert.Equal(t, "SCWROOTXXXXXXXXXXXXX", got.AccessKey)
+		assert.Equal(t, "secret-a", got.SecretKey)
+		assert.Equal(t, "org-a", got.OrganizationID)
+		assert.Equal(t, "https://api.scaleway.com", got.APIBaseURL)
+	})
+
+	t.Run("rejects unknown profiles and insecure api urls", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := profileFromConfig(cfg, "missing", base, &Sources{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `could not load profile "missing"`)
+
+		_, err = profileFromConfig(cfg, "bad", base, &Sources{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "https scheme is required")
+	})
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ProjectFileName = ".impact.yaml"
+	userFileName    = "config.yaml"
+)
+
+// Setting is a flag value read from a config file. Lists set the flag once
+// per item.
+type Setting struct {
+	Values []string
+	List   bool
+	Path   string
+	Line   int
+}
+
+func (s Setting) Source() string {
+	return s.Path + ":" + strconv.Itoa(s.Line)
+}
+
+// Settings are flag defaults keyed by flag name: top-level keys apply to
+// every command that has the flag, Commands to a single top-level command.
+type Settings struct {
+	Files    []string
+	Flags    map[string]Setting
+	Commands map[string]map[string]Setting
+}
+
+// DiscoverFiles returns the config files that exist, lowest precedence
+// first: the user file ($XDG_CONFIG_HOME/impact/config.yaml), then the
+// nearest .impact.yaml from dir up to the repository root. Outside a git
+// repository only dir itself is searched.
+func DiscoverFiles(dir string) ([]string, error) {
+	var files []string
+
+	if userDir, err := userConfigDir(); err == nil {
+		path := filepath.Join(userDir, "impact", userFileName)
+		if ok, err := isFile(path); err != nil {
+			return nil, err
+		} else if ok {
+			files = append(files, path)
+		}
+	}
+
+	for _, candidate := range projectDirs(dir) {
+		path := filepath.Join(candidate, ProjectFileName)
+		ok, err := isFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			files = append(files, path)
+			break
+		}
+	}
+	return files, nil
+}
+
+// userConfigDir is $XDG_CONFIG_HOME, or ~/.config when it is unset, on every
+// OS. os.UserConfigDir would look in ~/Library/Application Support on macOS,
+// which is not where the README or the Scaleway CLI keep config files.
+func userConfigDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(dir) {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config"), nil
+}
+
+// projectDirs lists dir and its parents up to the one holding .git, or only
+// dir when none does.
+func projectDirs(dir string) []string {
+	var dirs []string
+	for current := filepath.Clean(dir); ; {
+		dirs = append(dirs, current)
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return dirs
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dirs[:1]
+		}
+		current = parent
+	}
+}
+
+func isFile(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("could not stat config file %s: %w", path, err)
+	}
+	return !info.IsDir(), nil
+}
+
+// LoadSettings reads files in order; later files override earlier ones key
+// by key.
+func LoadSettings(files ...string) (Settings, error) {
+	merged := Settings{Flags: map[string]Setting{}, Commands: map[string]map[string]Setting{}}
+	for _, path := range files {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return Settings{}, fmt.Errorf("could not read config file: %w", err)
+		}
+		s, err := ParseSettings(path, b)
+		if err != nil {
+			return Settings{}, err
+		}
+
+		merged.Files = append(merged.Files, path)
+		for name, setting := range s.Flags {
+			merged.Flags[name] = setting
+		}
+		for command, flags := range s.Commands {
+			if merged.Commands[command] == nil {
+				merged.Commands[command] = map[string]Setting{}
+			}
+			for name, setting := range flags {
+				merged.Commands[command][name] = setting
+			}
+		}
+	}
+	return merged, nil
+}
+
+// ParseSettings decodes one config file. Values must be scalars or lists of
+// scalars; a mapping at the top level is a command section. Whether keys
+// name existing flags is checked by the caller.
+func ParseSettings(path string, b []byte) (Settings, error) {
+	s := Settings{Files: []string{path}, Flags: map[string]Setting{}, Commands: map[string]map[string]Setting{}}
+
+	var doc yaml.Node
+	if err := yaml.NewDecoder(bytes.NewReader(b)).Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return s, nil
+		}
+		return Settings{}, fmt.Errorf("could not decode config file %s: %w", path, err)
+	}
+
+	if len(doc.Content) == 0 {
+		return s, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return Settings{}, fmt.Errorf("could not decode config file %s:%d: top level must be a mapping", path, root.Line)
+	}
+
+	for i := 0; i < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+
+		if value.Kind != yaml.MappingNode {
+			setting, err := parseSetting(path, key.Value, value)
+			if err != nil {
+				return Settings{}, err
+			}
+			s.Flags[key.Value] = setting
+			continue
+		}
+
+		flags := map[string]Setting{}
+		for j := 0; j < len(value.Content); j += 2 {
+			name := value.Content[j].Value
+			setting, err := parseSetting(path, key.Value+"."+name, value.Content[j+1])
+			if err != nil {
+				return Settings{}, err
+			}
+			flags[name] = setting
+		}
+		s.Commands[key.Value] = flags
+	}
+	return s, nil
+}
+
+func parseSetting(path, key string, node *yaml.Node) (Setting, error) {
+	setting := Setting{Path: path, Line: node.Line}
+
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			return Setting{}, fmt.Errorf("could not decode config file %s:%d: %s has no value", path, node.Line, key)
+		}
+		setting.Values = []string{node.Value}
+	case yaml.SequenceNode:
+		setting.List = true
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return Setting{}, fmt.Errorf("could not decode config file %s:%d: %s must be a list of scalars", path, item.Line, key)
+			}
+			setting.Values = append(setting.Values, item.Value)
+		}
+	default:
+		return Setting{}, fmt.Errorf("could not decode config file %s:%d: %s must be a scalar or a list", path, node.Line, key)
+	}
+	return setting, nil
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSettings(t *testing.T) {
+	t.Parallel()
+
+	t.Run("splits top-level flags and command sections", func(t *testing.T) {
+		t.Parallel()
+
+		s, err := ParseSettings("a.yaml", []byte("format: json\nplan:\n  file: [a.json, b.json]\n  max-plan-size: 10\n"))
+		require.NoError(t, err)
+
+		assert.Equal(t, Setting{Values: []string{"json"}, Path: "a.yaml", Line: 1}, s.Flags["format"])
+		assert.Equal(t, Setting{Values: []string{"a.json", "b.json"}, List: true, Path: "a.yaml", Line: 3}, s.Commands["plan"]["file"])
+		assert.Equal(t, "a.yaml:4", s.Commands["plan"]["max-plan-size"].Source())
+	})
+
+	t.Run("accepts empty files", func(t *testing.T) {
+		t.Parallel()
+
+		s, err := ParseSettings("a.yaml", []byte("# nothing yet\n"))
+		require.NoError(t, err)
+		assert.Empty(t, s.Flags)
+	})
+
+	t.Run("reports the line of invalid values", func(t *testing.T) {
+		t.Parallel()
+
+		tests := map[string]string{
+			"- a\n":                        "top level must be a mapping",
+			"format:\n":                    "a.yaml:1: format has no value",
+			"plan:\n  nested:\n    a: 1\n": "a.yaml:3: plan.nested must be a scalar or a list",
+			"file: [[a]]\n":                "a.yaml:1: file must be a list of scalars",
+			"format: [\n":                  "could not decode config file a.yaml",
+		}
+		for input, want := range tests {
+			_, err := ParseSettings("a.yaml", []byte(input))
+			require.Error(t, err, input)
+			assert.Contains(t, err.Error(), want)
+		}
+	})
+}
+
+func TestLoadSettings(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	user := filepath.Join(dir, "user.yaml")
+	project := filepath.Join(dir, "project.yaml")
+	require.NoError(t, os.WriteFile(user, []byte("format: csv\nactual:\n  top: 3\n  depth: zone\n"), 0o600))
+	require.NoError(t, os.WriteFile(project, []byte("actual:\n  depth: region\n"), 0o600))
+
+	s, err := LoadSettings(user, project)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{user, project}, s.Files)
+	assert.Equal(t, []string{"csv"}, s.Flags["format"].Values)
+	assert.Equal(t, []string{"3"}, s.Commands["actual"]["top"].Values)
+	assert.Equal(t, []string{"region"}, s.Commands["actual"]["depth"].Values)
+	assert.Equal(t, project, s.Commands["actual"]["depth"].Path)
+
+	_, err = LoadSettings(filepath.Join(dir, "missing.yaml"))
+	require.ErrorContains(t, err, "could not read config file")
+}
+
+func TestDiscoverFiles(t *testing.T) {
+	root := t.TempDir()
+	xdg := filepath.Join(root, "xdg")
+	repo := filepath.Join(root, "repo")
+	sub := filepath.Join(repo, "a", "b")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(xdg, "impact"), 0o700))
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, ".git"), 0o700))
+	require.NoError(t, os.MkdirAll(sub, 0o700))
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+
+	files, err := DiscoverFiles(sub)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	userFile := filepath.Join(xdg, "impact", "config.yaml")
+	repoFile := filepath.Join(repo, ProjectFileName)
+	nearFile := filepath.Join(repo, "a", ProjectFileName)
+	for _, path := range []string{userFile, repoFile, filepath.Join(root, ProjectFileName)} {
+		require.NoError(t, os.WriteFile(path, nil, 0o600))
+	}
+
+	files, err = DiscoverFiles(sub)
+	require.NoError(t, err)
+	assert.Equal(t, []string{userFile, repoFile}, files, "the search stops at the repository root")
+
+	require.NoError(t, os.WriteFile(nearFile, nil, 0o600))
+	files, err = DiscoverFiles(sub)
+	require.NoError(t, err)
+	assert.Equal(t, []string{userFile, nearFile}, files, "the nearest file wins")
+
+	files, err = DiscoverFiles(root)
+	require.NoError(t, err)
+	assert.Equal(t, []string{userFile, filepath.Join(root, ProjectFileName)}, files, "outside a repository only the directory is searched")
+
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("HOME", root)
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".config", "impact"), 0o700))
+	homeFile := filepath.Join(root, ".config", "impact", "config.yaml")
+	require.NoError(t, os.WriteFile(homeFile, nil, 0o600))
+
+	files, err = DiscoverFiles(sub)
+	require.NoError(t, err)
+	assert.Equal(t, []string{homeFile, nearFile}, files, "~/.config is used without XDG_CONFIG_HOME, on every OS")
+}
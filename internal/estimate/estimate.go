@@ -10,9 +10,12 @@ import (
 	"github.com/alesr/impact/internal/scw/catalog"
 )
 
-const monthlyHours = 730.0
+// MonthlyHours is the number of hours in the average month used to turn hourly
+// catalog figures into monthly ones.
+const MonthlyHours = 730.0
 
 type Row struct {
+	Source       string  `json:"source,omitempty"`
 	Address      string  `json:"address"`
 	Type         string  `json:"type"`
 	Action       string  `json:"action"`
@@ -21,20 +24,52 @@ type Row struct {
 	KgCO2eKnown  bool    `json:"kgco2e_known"`
 	M3WaterMonth float64 `json:"m3_water_month"`
 	M3WaterKnown bool    `json:"m3_water_known"`
+	// Attributes are the plan attributes the row was mapped from. They are
+	// kept for policy rules and left out of the json output.
+	Attributes map[string]any `json:"-"`
 }
 
 type Report struct {
 	Rows        []Row                 `json:"rows"`
 	Unsupported []UnsupportedResource `json:"unsupported"`
 	Totals      Totals                `json:"totals"`
+	Groups      []Group               `json:"groups,omitempty"`
+	Findings    []Finding             `json:"findings,omitempty"`
+}
+
+// Finding is the result of a policy rule that matched part of the report.
+type Finding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Source   string `json:"source,omitempty"`
+	Address  string `json:"address,omitempty"`
+}
+
+// Group holds the subtotals of one source plan in a multi-plan report.
+type Group struct {
+	Source      string `json:"source"`
+	Totals      Totals `json:"totals"`
+	Unsupported int    `json:"unsupported"`
+}
+
+// Input is one set of resource changes tagged with the plan it came from.
+type Input struct {
+	Source  string
+	Changes []plan.ResourceChange
 }
 
 type UnsupportedResource struct {
+	Source  string `json:"source,omitempty"`
 	Address string `json:"address"`
 	Code    string `json:"code"`
 	Reason  string `json:"reason"`
 }
 
+// Totals sums the known rows. UnknownRows counts the rows with unknown
+// footprint data and the resources left unsupported because a value deciding
+// their footprint cannot be evaluated (code unknown_value): both make the
+// sums partial.
 type Totals struct {
 	KgCO2eMonth  float64 `json:"kgco2e_month"`
 	KgCO2eKnown  bool    `json:"kgco2e_known"`
@@ -43,7 +78,15 @@ type Totals struct {
 	UnknownRows  int     `json:"unknown_rows"`
 }
 
-func Build(changes []plan.ResourceChange, products []catalog.Product) Report {
+func Build(changes []plan.ResourceChange, products []catalog.Product, opts ...Option) Report {
+	var cfg options
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(&cfg)
+	}
+
 	report := Report{
 		Rows:        make([]Row, 0, len(changes)),
 		Unsupported: []UnsupportedResource{},
@@ -54,9 +97,13 @@ func Build(changes []plan.ResourceChange, products []catalog.Product) Report {
 	for _, change := range changes {
 		transitions := actionTransitions(change)
 		for _, transition := range transitions {
-			match, err := mapping.Resolve(transition.Change, products)
+			match, err := resolve(transition.Change, products, cfg)
 			if err != nil || (match.Product == nil && len(match.Matches) == 0) {
-				report.Unsupported = append(report.Unsupported, unsupportedFromError(change.Address, err))
+				unsupported := unsupportedFromError(change.Address, err)
+				report.Unsupported = append(report.Unsupported, unsupported)
+				if unsupported.Code == string(mapping.ErrorCodeUnknownValue) {
+					report.Totals.UnknownRows++
+				}
 				continue
 			}
 
@@ -88,6 +135,45 @@ func Build(changes []plan.ResourceChange, products []catalog.Product) Report {
 	return report
 }
 
+// BuildSources estimates several plans against the same catalog snapshot. Rows
+// and unsupported resources are tagged with their source, Groups carries the
+// per-source subtotals and Totals the grand total.
+func BuildSources(inputs []Input, products []catalog.Product, opts ...Option) Report {
+	report := Report{
+		Rows:        []Row{},
+		Unsupported: []UnsupportedResource{},
+		Totals:      Totals{KgCO2eKnown: true, M3WaterKnown: true},
+		Groups:      make([]Group, 0, len(inputs)),
+	}
+
+	for _, input := range inputs {
+		sub := Build(input.Changes, products, opts...)
+
+		for _, row := range sub.Rows {
+			row.Source = input.Source
+			report.Rows = append(report.Rows, row)
+		}
+		for _, unsupported := range sub.Unsupported {
+			unsupported.Source = input.Source
+			report.Unsupported = append(report.Unsupported, unsupported)
+		}
+
+		report.Totals.KgCO2eMonth += sub.Totals.KgCO2eMonth
+		report.Totals.M3WaterMonth += sub.Totals.M3WaterMonth
+		report.Totals.KgCO2eKnown = report.Totals.KgCO2eKnown && sub.Totals.KgCO2eKnown
+		report.Totals.M3WaterKnown = report.Totals.M3WaterKnown && sub.Totals.M3WaterKnown
+		report.Totals.UnknownRows += sub.Totals.UnknownRows
+
+		report.Groups = append(report.Groups, Group{
+			Source:      input.Source,
+			Totals:      sub.Totals,
+			Unsupported: len(sub.Unsupported),
+		})
+	}
+
+	return report
+}
+
 func rowsFromMatch(change plan.ResourceChange, action string, multiplier float64, match mapping.Result) []Row {
 	if len(match.Matches) == 0 {
 		return []Row{rowFromProduct(change, action, multiplier, match.Qty, *match.Product)}
@@ -145,7 +231,15 @@ func rowFromProduct(change plan.ResourceChange, action string, multiplier float6
 		KgCO2eKnown:  kgKnown,
 		M3WaterMonth: m3 * billedQty * unitMultiplier * multiplier,
 		M3WaterKnown: m3Known,
+		Attributes:   changeAttributes(change),
+	}
+}
+
+func changeAttributes(change plan.ResourceChange) map[string]any {
+	if change.After != nil {
+		return change.After
 	}
+	return change.Before
 }
 
 type actionTransition struct {
@@ -204,12 +298,15 @@ func afterChange(change plan.ResourceChange) plan.ResourceChange {
 	return after
 }
 
+// Attribute maps are nil when terraform reports no state on that side of the
+// change. A non-nil empty map still counts as data because the plan parser
+// drops attributes the mapping layer does not read.
 func hasBeforeData(change plan.ResourceChange) bool {
-	return len(change.Before) > 0
+	return change.Before != nil
 }
 
 func hasAfterData(change plan.ResourceChange) bool {
-	return len(change.After) > 0
+	return change.After != nil
 }
 
 func normalizeQtyByUnitSize(qty float64, size uint64) float64 {
@@ -223,7 +320,7 @@ func normalizeQtyByUnitSize(qty float64, size uint64) float64 {
 func unitToMonthMultiplier(unit string) float64 {
 	switch strings.ToLower(unit) {
 	case "hour":
-		return monthlyHours
+		return MonthlyHours
 	case "month":
 		return 1
 	case "year":
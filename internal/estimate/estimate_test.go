@@ -240,3 +240,54 @@ func TestBuild(t *testing.T) {
 		assert.False(t, report.Totals.M3WaterKnown)
 	})
 }
+
+func TestBuildSources(t *testing.T) {
+	t.Parallel()
+
+	products := []catalog.Product{{
+		SKU:             "/compute/dev1_m/test",
+		ProductCategory: "instances",
+		Locality:        catalog.Locality{Zone: "fr-par-2"},
+		UnitOfMeasure:   catalog.UnitOfMeasure{Unit: "month", Size: 1},
+		EnvironmentalImpactEstimation: &catalog.EnvironmentalEstimation{
+			KgCO2Equivalent: float64ptr(2),
+			M3WaterUsage:    float64ptr(0.5),
+		},
+	}}
+
+	server := plan.ResourceChange{
+		Address: "scaleway_instance_server.web",
+		Type:    "scaleway_instance_server",
+		Actions: []string{"create"},
+		After:   map[string]any{"zone": "fr-par-2", "type": "DEV1-M"},
+	}
+	unsupported := plan.ResourceChange{
+		Address: "scaleway_instance_ip.ip",
+		Type:    "scaleway_instance_ip",
+		Actions: []string{"create"},
+		After:   map[string]any{},
+	}
+
+	report := BuildSources([]Input{
+		{Source: "network/plan.json", Changes: []plan.ResourceChange{server, unsupported}},
+		{Source: "app/plan.json", Changes: []plan.ResourceChange{server, server}},
+	}, products)
+
+	require.Len(t, report.Rows, 3)
+	assert.Equal(t, "network/plan.json", report.Rows[0].Source)
+	assert.Equal(t, "app/plan.json", report.Rows[2].Source)
+
+	require.Len(t, report.Unsupported, 1)
+	assert.Equal(t, "network/plan.json", report.Unsupported[0].Source)
+
+	require.Len(t, report.Groups, 2)
+	assert.Equal(t, "network/plan.json", report.Groups[0].Source)
+	assert.InDelta(t, 2.0, report.Groups[0].Totals.KgCO2eMonth, 1e-9)
+	assert.Equal(t, 1, report.Groups[0].Unsupported)
+	assert.InDelta(t, 4.0, report.Groups[1].Totals.KgCO2eMonth, 1e-9)
+
+	assert.InDelta(t, 6.0, report.Totals.KgCO2eMonth, 1e-9)
+	assert.InDelta(t, 1.5, report.Totals.M3WaterMonth, 1e-9)
+	assert.True(t, report.Totals.KgCO2eKnown)
+	assert.True(t, report.Totals.M3WaterKnown)
+}
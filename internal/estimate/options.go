@@ -0,0 +1,32 @@
+package estimate
+
+type Option func(*options)
+
+type options struct {
+	skus        map[string]string
+	quantities  map[string]float64
+	defaultZone string
+}
+
+// WithSKUs pins the catalog product of resources, keyed as described in
+// LoadMappingFile.
+func WithSKUs(skus map[string]string) Option {
+	return func(opts *options) {
+		opts.skus = skus
+	}
+}
+
+// WithQuantities sets the quantity of resources, keyed as described in
+// LoadMappingFile. It is how usage-based resources get estimated.
+func WithQuantities(quantities map[string]float64) Option {
+	return func(opts *options) {
+		opts.quantities = quantities
+	}
+}
+
+// WithDefaultZone places resources that have neither a zone nor a region.
+func WithDefaultZone(zone string) Option {
+	return func(opts *options) {
+		opts.defaultZone = zone
+	}
+}
@@ -0,0 +1,142 @@
+package estimate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alesr/impact/internal/mapping"
+	"github.com/alesr/impact/internal/plan"
+	"github.com/alesr/impact/internal/scw/catalog"
+)
+
+// LoadMappingFile reads a yaml map of resource to catalog SKU. A key is a
+// resource address, an address without its instance key, or a resource type;
+// the most specific key wins.
+func LoadMappingFile(path string) (map[string]string, error) {
+	skus := map[string]string{}
+	if err := decodeFile(path, "mapping", &skus); err != nil {
+		return nil, err
+	}
+	for key, sku := range skus {
+		if strings.TrimSpace(sku) == "" {
+			return nil, fmt.Errorf("could not validate mapping file %s: %s has no sku", path, key)
+		}
+	}
+	return skus, nil
+}
+
+// LoadUsageFile reads a yaml map of resource to quantity, in the unit of its
+// catalog product (GB for storage, instances for hourly products). Keys are
+// the same as in LoadMappingFile.
+func LoadUsageFile(path string) (map[string]float64, error) {
+	quantities := map[string]float64{}
+	if err := decodeFile(path, "usage", &quantities); err != nil {
+		return nil, err
+	}
+	for key, qty := range quantities {
+		if qty < 0 {
+			return nil, fmt.Errorf("could not validate usage file %s: %s must not be negative", path, key)
+		}
+	}
+	return quantities, nil
+}
+
+func decodeFile(path, kind string, out any) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read %s file: %w", kind, err)
+	}
+	if err := yaml.NewDecoder(bytes.NewReader(b)).Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("could not decode %s file %s: %w", kind, path, err)
+	}
+	return nil
+}
+
+// resolve maps change to catalog products, then applies the SKU and quantity
+// overrides of opts on top. A value that cannot be evaluated stays an error
+// whatever the overrides, since the count or locality it hides is unknown.
+func resolve(change plan.ResourceChange, products []catalog.Product, opts options) (mapping.Result, error) {
+	if opts.defaultZone != "" && change.Zone == "" && change.Region == "" {
+		change.Zone = opts.defaultZone
+	}
+
+	match, err := mapping.Resolve(change, products)
+
+	var mappingErr *mapping.Error
+	if errors.As(err, &mappingErr) && mappingErr.Code == mapping.ErrorCodeUnknownValue {
+		return match, err
+	}
+
+	sku, pinned := lookup(opts.skus, change)
+	qty, counted := lookup(opts.quantities, change)
+	if !pinned && !counted {
+		return match, err
+	}
+
+	usageBased := mappingErr != nil && mappingErr.Code == mapping.ErrorCodeRequiresUsageInput
+
+	if !pinned {
+		if err != nil {
+			if usageBased {
+				return mapping.Result{}, &mapping.Error{Code: mapping.ErrorCodeRequiresUsageInput, Reason: "usage-based resource requires a sku in the mapping file"}
+			}
+			return match, err
+		}
+		if len(match.Matches) == 0 {
+			match.Qty = qty
+		}
+		for i := range match.Matches {
+			match.Matches[i].Qty = qty
+		}
+		return match, nil
+	}
+
+	product := findSKU(products, sku)
+	if product == nil {
+		return mapping.Result{}, &mapping.Error{Code: mapping.ErrorCodeNoCatalogMatch, Reason: fmt.Sprintf("no catalog product with sku %q from the mapping file", sku)}
+	}
+
+	switch {
+	case counted:
+	case usageBased:
+		return mapping.Result{}, &mapping.Error{Code: mapping.ErrorCodeRequiresUsageInput, Reason: "usage-based resource requires a quantity in the usage file"}
+	case err == nil && match.Product != nil:
+		qty = match.Qty
+	default:
+		qty = 1
+	}
+	return mapping.Result{Product: product, Qty: qty}, nil
+}
+
+// lookup finds the value for change by address, by address without its
+// instance key, then by resource type.
+func lookup[T any](values map[string]T, change plan.ResourceChange) (T, bool) {
+	keys := []string{change.Address}
+	if i := strings.LastIndex(change.Address, "["); i > 0 && strings.HasSuffix(change.Address, "]") {
+		keys = append(keys, change.Address[:i])
+	}
+	keys = append(keys, change.Type)
+
+	for _, key := range keys {
+		if v, ok := values[key]; ok {
+			return v, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func findSKU(products []catalog.Product, sku string) *catalog.Product {
+	for i := range products {
+		if products[i].SKU == sku {
+			return &products[i]
+		}
+	}
+	return nil
+}
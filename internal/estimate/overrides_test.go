@@ -0,0 +1,141 @@
+package estimate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alesr/impact/internal/mapping"
+	"github.com/alesr/impact/internal/plan"
+	"github.com/alesr/impact/internal/scw/catalog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildOverrides(t *testing.T) {
+	t.Parallel()
+
+	product := func(sku, zone, unit string, kg float64) catalog.Product {
+		return catalog.Product{
+			SKU:                           sku,
+			ProductCategory:               "instances",
+			Locality:                      catalog.Locality{Zone: zone},
+			UnitOfMeasure:                 catalog.UnitOfMeasure{Unit: unit, Size: 1},
+			EnvironmentalImpactEstimation: &catalog.EnvironmentalEstimation{KgCO2Equivalent: float64ptr(kg), M3WaterUsage: float64ptr(0)},
+		}
+	}
+	products := []catalog.Product{
+		product("/compute/dev1_m/fr-par-1", "fr-par-1", "hour", 0.001),
+		product("/compute/dev1_m/nl-ams-1", "nl-ams-1", "hour", 0.002),
+		product("/storage/object/standard/fr-par", "", "month", 0.01),
+	}
+	pool := plan.ResourceChange{Address: "scaleway_k8s_pool.apps", Type: "scaleway_k8s_pool", Actions: []string{"create"}, After: map[string]any{"zone": "fr-par-1", "node_type": "DEV1-M", "size": 3.0}}
+	bucket := plan.ResourceChange{Address: "scaleway_object_bucket.assets[0]", Type: "scaleway_object_bucket", Actions: []string{"create"}, After: map[string]any{"region": "fr-par"}}
+
+	t.Run("pins the sku and keeps the mapped quantity", func(t *testing.T) {
+		t.Parallel()
+
+		rep := Build([]plan.ResourceChange{pool}, products, WithSKUs(map[string]string{"scaleway_k8s_pool": "/compute/dev1_m/nl-ams-1"}))
+		require.Len(t, rep.Rows, 1)
+		assert.Equal(t, "/compute/dev1_m/nl-ams-1", rep.Rows[0].SKU)
+		assert.InDelta(t, 0.002*3*MonthlyHours, rep.Rows[0].KgCO2eMonth, 1e-9)
+	})
+
+	t.Run("usage quantities replace the mapped quantity", func(t *testing.T) {
+		t.Parallel()
+
+		rep := Build([]plan.ResourceChange{pool}, products, WithQuantities(map[string]float64{"scaleway_k8s_pool.apps": 5}))
+		require.Len(t, rep.Rows, 1)
+		assert.InDelta(t, 0.001*5*MonthlyHours, rep.Rows[0].KgCO2eMonth, 1e-9)
+	})
+
+	t.Run("estimates usage-based resources with a sku and a quantity", func(t *testing.T) {
+		t.Parallel()
+
+		skus := WithSKUs(map[string]string{"scaleway_object_bucket": "/storage/object/standard/fr-par"})
+		quantities := WithQuantities(map[string]float64{"scaleway_object_bucket.assets": 500, "scaleway_object_bucket": 1})
+
+		rep := Build([]plan.ResourceChange{bucket}, products, skus, quantities)
+		require.Len(t, rep.Rows, 1)
+		assert.Equal(t, "/storage/object/standard/fr-par", rep.Rows[0].SKU)
+		assert.InDelta(t, 5.0, rep.Rows[0].KgCO2eMonth, 1e-9, "the address without its index beats the type")
+
+		rep = Build([]plan.ResourceChange{bucket}, products, skus)
+		require.Len(t, rep.Unsupported, 1)
+		assert.Equal(t, UnsupportedResource{Address: bucket.Address, Code: string(mapping.ErrorCodeRequiresUsageInput), Reason: "usage-based resource requires a quantity in the usage file"}, rep.Unsupported[0])
+
+		rep = Build([]plan.ResourceChange{bucket}, products, quantities)
+		require.Len(t, rep.Unsupported, 1)
+		assert.Equal(t, "usage-based resource requires a sku in the mapping file", rep.Unsupported[0].Reason)
+	})
+
+	t.Run("reports a sku missing from the catalog", func(t *testing.T) {
+		t.Parallel()
+
+		rep := Build([]plan.ResourceChange{pool}, products, WithSKUs(map[string]string{"scaleway_k8s_pool.apps": "/nope"}))
+		require.Len(t, rep.Unsupported, 1)
+		assert.Equal(t, string(mapping.ErrorCodeNoCatalogMatch), rep.Unsupported[0].Code)
+		assert.Contains(t, rep.Unsupported[0].Reason, `"/nope"`)
+	})
+
+	t.Run("keeps unknown values unsupported when the sku is pinned", func(t *testing.T) {
+		t.Parallel()
+
+		unknownCount := plan.ResourceChange{Address: "scaleway_k8s_pool.apps", Type: "scaleway_k8s_pool", Actions: []string{"create"}, After: map[string]any{}, Unknown: []string{"count"}}
+
+		rep := Build([]plan.ResourceChange{unknownCount}, products,
+			WithSKUs(map[string]string{"scaleway_k8s_pool": "/compute/dev1_m/fr-par-1"}),
+			WithQuantities(map[string]float64{"scaleway_k8s_pool": 3}),
+		)
+		assert.Empty(t, rep.Rows)
+		require.Len(t, rep.Unsupported, 1)
+		assert.Equal(t, string(mapping.ErrorCodeUnknownValue), rep.Unsupported[0].Code)
+		assert.Equal(t, 1, rep.Totals.UnknownRows)
+	})
+
+	t.Run("places resources without a zone in the default zone", func(t *testing.T) {
+		t.Parallel()
+
+		server := plan.ResourceChange{Address: "scaleway_instance_server.web", Type: "scaleway_instance_server", Actions: []string{"create"}, After: map[string]any{"type": "DEV1-M"}}
+
+		rep := Build([]plan.ResourceChange{server}, products, WithDefaultZone("nl-ams-1"))
+		require.Len(t, rep.Rows, 1)
+		assert.Equal(t, "/compute/dev1_m/nl-ams-1", rep.Rows[0].SKU)
+
+		server.After = map[string]any{"type": "DEV1-M", "zone": "fr-par-1"}
+		rep = Build([]plan.ResourceChange{server}, products, WithDefaultZone("nl-ams-1"))
+		require.Len(t, rep.Rows, 1)
+		assert.Equal(t, "/compute/dev1_m/fr-par-1", rep.Rows[0].SKU, "a zone set on the resource wins")
+	})
+}
+
+func TestLoadOverrideFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	skus, err := LoadMappingFile(write("mapping.yaml", "scaleway_container: /serverless/containers/fr-par\n"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"scaleway_container": "/serverless/containers/fr-par"}, skus)
+
+	quantities, err := LoadUsageFile(write("usage.yaml", "scaleway_object_bucket.assets: 500\n"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"scaleway_object_bucket.assets": 500}, quantities)
+
+	_, err = LoadMappingFile(write("empty-sku.yaml", "scaleway_container: ''\n"))
+	require.ErrorContains(t, err, "scaleway_container has no sku")
+
+	_, err = LoadUsageFile(write("negative.yaml", "scaleway_object_bucket: -1\n"))
+	require.ErrorContains(t, err, "must not be negative")
+
+	_, err = LoadUsageFile(write("bad.yaml", "scaleway_object_bucket: lots\n"))
+	require.ErrorContains(t, err, "could not decode usage file")
+
+	_, err = LoadMappingFile(filepath.Join(dir, "missing.yaml"))
+	require.ErrorContains(t, err, "could not read mapping file")
+}
@@ -0,0 +1,235 @@
+// Package fakeapi serves the Scaleway product catalog, environmental
+// footprint and account project endpoints from fixtures, for tests and
+// local runs without credentials.
+package fakeapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+const defaultPageSize = 100
+
+type Server struct {
+	fixtures Fixtures
+	opts     options
+	requests atomic.Int64
+	mux      *http.ServeMux
+}
+
+func New(fixtures Fixtures, opts ...Option) *Server {
+	var cfg options
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(&cfg)
+	}
+	if cfg.failures.Status == 0 {
+		cfg.failures.Status = http.StatusServiceUnavailable
+	}
+
+	s := &Server{fixtures: fixtures, opts: cfg, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /product-catalog/v2alpha1/public-catalog/products", s.listProducts)
+	s.mux.HandleFunc("GET /environmental-footprint/v1alpha1/data/query", s.authenticated(s.queryImpactData))
+	s.mux.HandleFunc("GET /account/v3/projects", s.authenticated(s.listProjects))
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		writeError(w, http.StatusNotFound, "not_found", "resource is not found")
+	})
+	return s
+}
+
+// Requests returns the number of requests served so far.
+func (s *Server) Requests() int {
+	return int(s.requests.Load())
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := int(s.requests.Add(1))
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	defer func() {
+		if s.opts.log != nil {
+			fmt.Fprintf(s.opts.log, "%s %s %d\n", r.Method, r.URL.RequestURI(), rec.status)
+		}
+	}()
+
+	if s.opts.latency > 0 {
+		select {
+		case <-time.After(s.opts.latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if f := s.opts.failures; n <= f.First || (f.Every > 0 && n%f.Every == 0) {
+		if f.Status == http.StatusTooManyRequests {
+			rec.Header().Set("Retry-After", "1")
+		}
+		writeError(rec, f.Status, "injected_error", fmt.Sprintf("injected failure on request %d", n))
+		return
+	}
+
+	s.mux.ServeHTTP(rec, r)
+}
+
+func (s *Server) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Auth-Token") == "" {
+			writeError(w, http.StatusUnauthorized, "denied_authentication", "authentication is denied")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) listProducts(w http.ResponseWriter, r *http.Request) {
+	page, err := paginate(r, len(s.fixtures.Products), s.opts.maxPageSize)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_arguments", err.Error())
+		return
+	}
+
+	writeJSON(w, map[string]any{
+		"products":    s.fixtures.Products[page.start:page.end],
+		"total_count": len(s.fixtures.Products),
+	})
+}
+
+func (s *Server) listProjects(w http.ResponseWriter, r *http.Request) {
+	projects := s.fixtures.Projects
+	if org := r.URL.Query().Get("organization_id"); org != "" {
+		projects = slices.DeleteFunc(slices.Clone(projects), func(p Project) bool {
+			return p.OrganizationID != "" && p.OrganizationID != org
+		})
+	}
+
+	page, err := paginate(r, len(projects), s.opts.maxPageSize)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_arguments", err.Error())
+		return
+	}
+
+	writeJSON(w, map[string]any{
+		"projects":    projects[page.start:page.end],
+		"total_count": len(projects),
+	})
+}
+
+// queryImpactData filters the impact fixture by project, region and zone and
+// recomputes the totals. Requested dates replace the fixture dates.
+func (s *Server) queryImpactData(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("organization_id") == "" {
+		writeError(w, http.StatusBadRequest, "invalid_arguments", "organization_id is required")
+		return
+	}
+
+	data := filterImpact(s.fixtures.Impact, query["project_ids"], query["regions"], query["zones"])
+	if v := query.Get("start_date"); v != "" {
+		data.StartDate = v
+	}
+	if v := query.Get("end_date"); v != "" {
+		data.EndDate = v
+	}
+	writeJSON(w, data)
+}
+
+func filterImpact(data ImpactData, projectIDs, regions, zones []string) ImpactData {
+	keep := func(filter []string, v string) bool {
+		return len(filter) == 0 || slices.Contains(filter, v)
+	}
+
+	out := ImpactData{StartDate: data.StartDate, EndDate: data.EndDate, Projects: []ProjectImpact{}}
+	for _, project := range data.Projects {
+		if !keep(projectIDs, project.ProjectID) {
+			continue
+		}
+		p := ProjectImpact{ProjectID: project.ProjectID, Regions: []RegionImpact{}}
+		for _, region := range project.Regions {
+			if !keep(regions, region.Region) {
+				continue
+			}
+			rg := RegionImpact{Region: region.Region, Zones: []ZoneImpact{}}
+			for _, zone := range region.Zones {
+				if !keep(zones, zone.Zone) {
+					continue
+				}
+				z := ZoneImpact{Zone: zone.Zone, SKUs: zone.SKUs}
+				for _, sku := range zone.SKUs {
+					z.TotalZoneImpact = add(z.TotalZoneImpact, sku.TotalSKUImpact)
+				}
+				rg.Zones = append(rg.Zones, z)
+				rg.TotalRegionImpact = add(rg.TotalRegionImpact, z.TotalZoneImpact)
+			}
+			p.Regions = append(p.Regions, rg)
+			p.TotalProjectImpact = add(p.TotalProjectImpact, rg.TotalRegionImpact)
+		}
+		out.Projects = append(out.Projects, p)
+		out.TotalImpact = add(out.TotalImpact, p.TotalProjectImpact)
+	}
+	return out
+}
+
+func add(a, b Impact) Impact {
+	return Impact{KgCO2Equivalent: a.KgCO2Equivalent + b.KgCO2Equivalent, M3WaterUsage: a.M3WaterUsage + b.M3WaterUsage}
+}
+
+type pageRange struct {
+	start, end int
+}
+
+func paginate(r *http.Request, total, maxPageSize int) (pageRange, error) {
+	page, err := queryInt(r, "page", 1)
+	if err != nil {
+		return pageRange{}, err
+	}
+	size, err := queryInt(r, "page_size", defaultPageSize)
+	if err != nil {
+		return pageRange{}, err
+	}
+	if maxPageSize > 0 && size > maxPageSize {
+		size = maxPageSize
+	}
+
+	start := min((page-1)*size, total)
+	return pageRange{start: start, end: min(start+size, total)}, nil
+}
+
+func queryInt(r *http.Request, key string, fallback int) (int, error) {
+	raw := r.URL.Query().Get(key)
+	if raw == "" {
+		return fallback, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil || v < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer", key)
+	}
+	return v, nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError answers in the error format of the Scaleway API.
+func writeError(w http.ResponseWriter, status int, kind, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"type": kind, "message": message})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
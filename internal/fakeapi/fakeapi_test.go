@@ -0,0 +1,178 @@
+package fakeapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alesr/impact/internal/scw/account"
+	"github.com/alesr/impact/internal/scw/catalog"
+	"github.com/alesr/impact/internal/scw/footprint"
+)
+
+const (
+	testAccessKey = "SCWXXXXXXXXXXXXXXXXX"
+	testSecretKey = "00000000-0000-0000-0000-000000000000"
+	testOrg       = "00000000-0000-0000-0000-000000000000"
+)
+
+func startServer(t *testing.T, opts ...Option) (*Server, string) {
+	t.Helper()
+
+	fake := New(DefaultFixtures(), opts...)
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	return fake, srv.URL
+}
+
+func TestCatalogPagination(t *testing.T) {
+	t.Parallel()
+
+	fake, url := startServer(t, WithMaxPageSize(1))
+
+	client, err := catalog.NewClient(catalog.WithBaseURL(url))
+	require.NoError(t, err)
+
+	products, err := client.ListAllProducts(context.Background())
+	require.NoError(t, err)
+	require.Len(t, products, 3)
+	assert.Equal(t, "/compute/pop2_hc_2c_4g/run_fr-par-2", products[0].SKU)
+	require.NotNil(t, products[0].EnvironmentalImpactEstimation.KgCO2Equivalent)
+	assert.InDelta(t, 0.0125, *products[0].EnvironmentalImpactEstimation.KgCO2Equivalent, 1e-6)
+	assert.Equal(t, 3, fake.Requests())
+}
+
+func TestQueryImpactData(t *testing.T) {
+	t.Parallel()
+
+	_, url := startServer(t)
+
+	client, err := footprint.NewClient(testAccessKey, testSecretKey, footprint.WithBaseURL(url))
+	require.NoError(t, err)
+
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("returns every project with computed totals", func(t *testing.T) {
+		t.Parallel()
+
+		resp, err := client.QueryImpactData(context.Background(), footprint.QueryImpactDataRequest{OrganizationID: testOrg, StartDate: &start, EndDate: &end})
+		require.NoError(t, err)
+
+		assert.Equal(t, start, resp.StartDate)
+		assert.Equal(t, end, resp.EndDate)
+		assert.InDelta(t, 13.3, resp.TotalImpact.KgCO2Equivalent, 1e-4)
+		require.Len(t, resp.Projects, 2)
+		assert.InDelta(t, 4.0, resp.Projects[1].TotalProjectImpact.KgCO2Equivalent, 1e-4)
+	})
+
+	t.Run("filters by project and region", func(t *testing.T) {
+		t.Parallel()
+
+		resp, err := client.QueryImpactData(context.Background(), footprint.QueryImpactDataRequest{
+			OrganizationID: testOrg,
+			ProjectIDs:     []string{"22222222-2222-2222-2222-222222222222"},
+			Regions:        []string{"nl-ams"},
+		})
+		require.NoError(t, err)
+
+		require.Len(t, resp.Projects, 1)
+		require.Len(t, resp.Projects[0].Regions, 1)
+		assert.InDelta(t, 1.1, resp.TotalImpact.KgCO2Equivalent, 1e-4)
+	})
+}
+
+func TestListProjects(t *testing.T) {
+	t.Parallel()
+
+	_, url := startServer(t, WithMaxPageSize(1))
+
+	client, err := account.NewClient(testAccessKey, testSecretKey, account.WithBaseURL(url))
+	require.NoError(t, err)
+
+	projects, err := client.ListProjects(context.Background(), testOrg)
+	require.NoError(t, err)
+	assert.Equal(t, []account.Project{
+		{ID: "11111111-1111-1111-1111-111111111111", Name: "web"},
+		{ID: "22222222-2222-2222-2222-222222222222", Name: "batch"},
+	}, projects)
+
+	projects, err = client.ListProjects(context.Background(), "other-org")
+	require.NoError(t, err)
+	assert.Empty(t, projects)
+}
+
+func TestServerErrors(t *testing.T) {
+	t.Parallel()
+
+	get := func(t *testing.T, url string, header http.Header) *http.Response {
+		t.Helper()
+
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		req.Header = header
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	t.Run("requires a token on authenticated endpoints", func(t *testing.T) {
+		t.Parallel()
+
+		_, url := startServer(t)
+		resp := get(t, url+"/environmental-footprint/v1alpha1/data/query?organization_id=org", nil)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		resp = get(t, url+"/nope", nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("injects failures", func(t *testing.T) {
+		t.Parallel()
+
+		_, url := startServer(t, WithFailures(Failures{Status: http.StatusTooManyRequests, First: 1, Every: 3}))
+		path := url + "/product-catalog/v2alpha1/public-catalog/products"
+
+		var statuses []int
+		var last *http.Response
+		for range 6 {
+			last = get(t, path, nil)
+			statuses = append(statuses, last.StatusCode)
+		}
+		assert.Equal(t, []int{429, 200, 429, 200, 200, 429}, statuses)
+		assert.Equal(t, "1", last.Header.Get("Retry-After"))
+	})
+
+	t.Run("delays responses", func(t *testing.T) {
+		t.Parallel()
+
+		_, url := startServer(t, WithLatency(50*time.Millisecond))
+		began := time.Now()
+		get(t, url+"/product-catalog/v2alpha1/public-catalog/products", nil)
+		assert.GreaterOrEqual(t, time.Since(began), 50*time.Millisecond)
+	})
+}
+
+func TestLoadFixtures(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "products.json"), []byte(`[{"sku": "custom"}]`), 0o600))
+
+	fixtures, err := LoadFixtures(dir)
+	require.NoError(t, err)
+	require.Len(t, fixtures.Products, 1)
+	assert.Len(t, fixtures.Projects, 2, "missing files keep the built-in fixtures")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "impact.json"), []byte(`{"projects": 1}`), 0o600))
+	_, err = LoadFixtures(dir)
+	require.ErrorContains(t, err, "could not decode fixture impact.json")
+}
@@ -0,0 +1,129 @@
+package fakeapi
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+)
+
+//go:embed fixtures/*.json
+var defaultFixtures embed.FS
+
+const (
+	productsFile = "products.json"
+	impactFile   = "impact.json"
+	projectsFile = "projects.json"
+)
+
+// Fixtures hold the data served by the fake API, in the JSON shape of the
+// real API. Impact totals are computed from the SKU leaves, so fixtures only
+// need total_sku_impact.
+type Fixtures struct {
+	Products []json.RawMessage
+	Impact   ImpactData
+	Projects []Project
+}
+
+type Impact struct {
+	KgCO2Equivalent float64 `json:"kg_co2_equivalent"`
+	M3WaterUsage    float64 `json:"m3_water_usage"`
+}
+
+type ImpactData struct {
+	StartDate   string          `json:"start_date,omitempty"`
+	EndDate     string          `json:"end_date,omitempty"`
+	TotalImpact Impact          `json:"total_impact"`
+	Projects    []ProjectImpact `json:"projects"`
+}
+
+type ProjectImpact struct {
+	ProjectID          string         `json:"project_id"`
+	TotalProjectImpact Impact         `json:"total_project_impact"`
+	Regions            []RegionImpact `json:"regions"`
+}
+
+type RegionImpact struct {
+	Region            string       `json:"region"`
+	TotalRegionImpact Impact       `json:"total_region_impact"`
+	Zones             []ZoneImpact `json:"zones"`
+}
+
+type ZoneImpact struct {
+	Zone            string      `json:"zone"`
+	TotalZoneImpact Impact      `json:"total_zone_impact"`
+	SKUs            []SKUImpact `json:"skus"`
+}
+
+type SKUImpact struct {
+	SKU             string `json:"sku"`
+	ServiceCategory string `json:"service_category"`
+	ProductCategory string `json:"product_category"`
+	TotalSKUImpact  Impact `json:"total_sku_impact"`
+}
+
+type Project struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	OrganizationID string `json:"organization_id"`
+}
+
+// DefaultFixtures returns the fixtures built into the binary: a few instance
+// products in fr-par and nl-ams, one month of measured impact for two
+// projects, and their names.
+func DefaultFixtures() Fixtures {
+	fixtures, err := loadFixtures(defaultFixtures, "fixtures", Fixtures{})
+	if err != nil {
+		panic(fmt.Sprintf("fakeapi: invalid built-in fixtures: %v", err))
+	}
+	return fixtures
+}
+
+// LoadFixtures reads products.json, impact.json and projects.json from dir.
+// Missing files keep the built-in fixtures.
+func LoadFixtures(dir string) (Fixtures, error) {
+	return loadFixtures(os.DirFS(dir), ".", DefaultFixtures())
+}
+
+func loadFixtures(fsys fs.FS, dir string, base Fixtures) (Fixtures, error) {
+	fixtures := base
+
+	var products []json.RawMessage
+	if ok, err := readFixture(fsys, dir, productsFile, &products); err != nil {
+		return Fixtures{}, err
+	} else if ok {
+		fixtures.Products = products
+	}
+
+	var impact ImpactData
+	if ok, err := readFixture(fsys, dir, impactFile, &impact); err != nil {
+		return Fixtures{}, err
+	} else if ok {
+		fixtures.Impact = impact
+	}
+
+	var projects []Project
+	if ok, err := readFixture(fsys, dir, projectsFile, &projects); err != nil {
+		return Fixtures{}, err
+	} else if ok {
+		fixtures.Projects = projects
+	}
+	return fixtures, nil
+}
+
+func readFixture(fsys fs.FS, dir, name string, v any) (bool, error) {
+	b, err := fs.ReadFile(fsys, path.Join(dir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("could not read fixture %s: %w", name, err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return false, fmt.Errorf("could not decode fixture %s: %w", name, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,51 @@
+package fakeapi
+
+import (
+	"io"
+	"time"
+)
+
+type Option func(*options)
+
+type options struct {
+	latency     time.Duration
+	failures    Failures
+	maxPageSize int
+	log         io.Writer
+}
+
+// Failures injects API errors. Request n (counted from 1 across all
+// endpoints) fails when n <= First or when Every divides n.
+type Failures struct {
+	Status int
+	First  int
+	Every  int
+}
+
+// WithLatency delays every response.
+func WithLatency(latency time.Duration) Option {
+	return func(opts *options) {
+		opts.latency = latency
+	}
+}
+
+func WithFailures(failures Failures) Option {
+	return func(opts *options) {
+		opts.failures = failures
+	}
+}
+
+// WithMaxPageSize caps the page size of list endpoints, so clients walk
+// through several pages even for small fixtures.
+func WithMaxPageSize(size int) Option {
+	return func(opts *options) {
+		opts.maxPageSize = size
+	}
+}
+
+// WithLog writes one line per request to w.
+func WithLog(w io.Writer) Option {
+	return func(opts *options) {
+		opts.log = w
+	}
+}
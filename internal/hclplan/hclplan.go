@@ -0,0 +1,401 @@
+package hclplan
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/alesr/impact/internal/plan"
+)
+
+const maxLocalPasses = 8
+
+var rootSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "resource", LabelNames: []string{"type", "name"}},
+		{Type: "variable", LabelNames: []string{"name"}},
+		{Type: "locals"},
+		{Type: "provider", LabelNames: []string{"name"}},
+	},
+}
+
+var variableSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{{Name: "default"}},
+}
+
+type module struct {
+	resources []*hcl.Block
+	providers []*hcl.Block
+	variables map[string]cty.Value
+	locals    map[string]hcl.Expression
+}
+
+// ParseDir reads the *.tf files of a single terraform module and returns one
+// create change per resource instance. Values come from literals, variable
+// defaults, terraform.tfvars, *.auto.tfvars and locals built from those.
+// Anything else (resource references, function calls, data sources) is
+// reported through ResourceChange.Unknown.
+func ParseDir(dir string) ([]plan.ResourceChange, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, fmt.Errorf("could not list terraform files: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("could not read terraform files: no *.tf files in %s", dir)
+	}
+	slices.Sort(files)
+
+	parser := hclparse.NewParser()
+	mod := module{variables: map[string]cty.Value{}, locals: map[string]hcl.Expression{}}
+
+	for _, path := range files {
+		file, diags := parser.ParseHCLFile(path)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("could not parse %s: %s", path, diags.Error())
+		}
+
+		content, _, diags := file.Body.PartialContent(rootSchema)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("could not parse %s: %s", path, diags.Error())
+		}
+
+		for _, block := range content.Blocks {
+			switch block.Type {
+			case "resource":
+				mod.resources = append(mod.resources, block)
+			case "provider":
+				mod.providers = append(mod.providers, block)
+			case "variable":
+				mod.variables[block.Labels[0]] = variableDefault(block)
+			case "locals":
+				attrs, diags := block.Body.JustAttributes()
+				if diags.HasErrors() {
+					return nil, fmt.Errorf("could not parse locals in %s: %s", path, diags.Error())
+				}
+				for name, attr := range attrs {
+					mod.locals[name] = attr.Expr
+				}
+			}
+		}
+	}
+
+	if err := applyVarFiles(parser, dir, mod.variables); err != nil {
+		return nil, err
+	}
+
+	ctx := &hcl.EvalContext{Variables: map[string]cty.Value{"var": cty.ObjectVal(mod.variables)}}
+	ctx.Variables["local"] = cty.ObjectVal(evalLocals(ctx, mod.locals))
+
+	defaults := providerDefaults(ctx, mod.providers)
+
+	changes := make([]plan.ResourceChange, 0, len(mod.resources))
+	for _, block := range mod.resources {
+		changes = append(changes, resourceChanges(ctx, block, defaults)...)
+	}
+	return changes, nil
+}
+
+func variableDefault(block *hcl.Block) cty.Value {
+	content, _, diags := block.Body.PartialContent(variableSchema)
+	if diags.HasErrors() {
+		return cty.DynamicVal
+	}
+
+	attr, ok := content.Attributes["default"]
+	if !ok {
+		return cty.DynamicVal
+	}
+
+	v, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() {
+		return cty.DynamicVal
+	}
+	return v
+}
+
+// applyVarFiles overrides variable defaults the same way terraform loads
+// terraform.tfvars followed by *.auto.tfvars in lexical order.
+func applyVarFiles(parser *hclparse.Parser, dir string, variables map[string]cty.Value) error {
+	paths := []string{filepath.Join(dir, "terraform.tfvars")}
+	autoFiles, err := filepath.Glob(filepath.Join(dir, "*.auto.tfvars"))
+	if err != nil {
+		return fmt.Errorf("could not list tfvars files: %w", err)
+	}
+	slices.Sort(autoFiles)
+	paths = append(paths, autoFiles...)
+
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		file, diags := parser.ParseHCLFile(path)
+		if diags.HasErrors() {
+			return fmt.Errorf("could not parse %s: %s", path, diags.Error())
+		}
+
+		attrs, diags := file.Body.JustAttributes()
+		if diags.HasErrors() {
+			return fmt.Errorf("could not parse %s: %s", path, diags.Error())
+		}
+
+		for name, attr := range attrs {
+			if _, declared := variables[name]; !declared {
+				continue
+			}
+			v, diags := attr.Expr.Value(nil)
+			if diags.HasErrors() {
+				v = cty.DynamicVal
+			}
+			variables[name] = v
+		}
+	}
+	return nil
+}
+
+// evalLocals resolves locals in a few passes so locals may refer to each
+// other; whatever is still unresolved afterwards is unknown.
+func evalLocals(ctx *hcl.EvalContext, exprs map[string]hcl.Expression) map[string]cty.Value {
+	values := make(map[string]cty.Value, len(exprs))
+	for name := range exprs {
+		values[name] = cty.DynamicVal
+	}
+
+	for range maxLocalPasses {
+		progress := false
+		ctx.Variables["local"] = cty.ObjectVal(values)
+
+		for name, expr := range exprs {
+			if values[name].IsWhollyKnown() {
+				continue
+			}
+			v, diags := expr.Value(ctx)
+			if diags.HasErrors() || !v.IsWhollyKnown() {
+				continue
+			}
+			values[name] = v
+			progress = true
+		}
+
+		if !progress {
+			break
+		}
+	}
+	return values
+}
+
+// locality holds the zone and region of the scaleway provider. Unknown lists
+// the ones set to an expression that cannot be evaluated.
+type locality struct {
+	zone    string
+	region  string
+	unknown []string
+}
+
+func providerDefaults(ctx *hcl.EvalContext, providers []*hcl.Block) locality {
+	var defaults locality
+	for _, block := range providers {
+		if block.Labels[0] != "scaleway" {
+			continue
+		}
+
+		attrs, _ := block.Body.JustAttributes()
+		for _, key := range []string{"zone", "region"} {
+			attr, ok := attrs[key]
+			if !ok {
+				continue
+			}
+			v, ok := evalString(ctx, attr.Expr)
+			if !ok {
+				defaults.unknown = append(defaults.unknown, key)
+				continue
+			}
+			if key == "zone" {
+				defaults.zone = v
+			} else {
+				defaults.region = v
+			}
+		}
+	}
+	return defaults
+}
+
+func resourceChanges(ctx *hcl.EvalContext, block *hcl.Block, defaults locality) []plan.ResourceChange {
+	resourceType, name := block.Labels[0], block.Labels[1]
+	address := resourceType + "." + name
+
+	attrs := map[string]*hclsyntax.Attribute{}
+	if body, ok := block.Body.(*hclsyntax.Body); ok {
+		attrs = body.Attributes
+	}
+
+	newChange := func(address string, instanceCtx *hcl.EvalContext) plan.ResourceChange {
+		after, unknown := evalAttributes(instanceCtx, attrs)
+
+		// A provider default that cannot be evaluated leaves the locality
+		// unknown unless the resource sets its own.
+		for _, key := range defaults.unknown {
+			if _, set := after[key]; !set && !slices.Contains(unknown, key) {
+				unknown = append(unknown, key)
+			}
+		}
+		slices.Sort(unknown)
+
+		return plan.ResourceChange{
+			Address: address,
+			Type:    resourceType,
+			Actions: []string{"create"},
+			After:   after,
+			Zone:    defaults.zone,
+			Region:  defaults.region,
+			Unknown: unknown,
+		}
+	}
+
+	if countAttr, ok := attrs["count"]; ok {
+		count, known := evalCount(ctx, countAttr.Expr)
+		if !known {
+			return []plan.ResourceChange{unknownChange(address, resourceType, defaults, "count")}
+		}
+
+		changes := make([]plan.ResourceChange, 0, count)
+		for i := range count {
+			instanceCtx := ctx.NewChild()
+			instanceCtx.Variables = map[string]cty.Value{
+				"count": cty.ObjectVal(map[string]cty.Value{"index": cty.NumberIntVal(int64(i))}),
+			}
+			changes = append(changes, newChange(fmt.Sprintf("%s[%d]", address, i), instanceCtx))
+		}
+		return changes
+	}
+
+	if forEachAttr, ok := attrs["for_each"]; ok {
+		items, known := evalForEach(ctx, forEachAttr.Expr)
+		if !known {
+			return []plan.ResourceChange{unknownChange(address, resourceType, defaults, "for_each")}
+		}
+
+		changes := make([]plan.ResourceChange, 0, len(items))
+		for _, item := range items {
+			instanceCtx := ctx.NewChild()
+			instanceCtx.Variables = map[string]cty.Value{
+				"each": cty.ObjectVal(map[string]cty.Value{"key": cty.StringVal(item.key), "value": item.value}),
+			}
+			changes = append(changes, newChange(fmt.Sprintf("%s[%q]", address, item.key), instanceCtx))
+		}
+		return changes
+	}
+
+	return []plan.ResourceChange{newChange(address, ctx)}
+}
+
+func unknownChange(address, resourceType string, defaults locality, key string) plan.ResourceChange {
+	return plan.ResourceChange{
+		Address: address,
+		Type:    resourceType,
+		Actions: []string{"create"},
+		After:   map[string]any{},
+		Zone:    defaults.zone,
+		Region:  defaults.region,
+		Unknown: []string{key},
+	}
+}
+
+// evalAttributes evaluates the top-level attributes of a resource. Primitive
+// values are kept, null values are dropped as if unset, and anything that
+// cannot be evaluated is listed as unknown.
+func evalAttributes(ctx *hcl.EvalContext, attrs map[string]*hclsyntax.Attribute) (map[string]any, []string) {
+	after := map[string]any{}
+	var unknown []string
+
+	for name, attr := range attrs {
+		if name == "count" || name == "for_each" {
+			continue
+		}
+
+		v, diags := attr.Expr.Value(ctx)
+		if diags.HasErrors() || !v.IsWhollyKnown() {
+			unknown = append(unknown, name)
+			continue
+		}
+		if v.IsNull() {
+			continue
+		}
+
+		switch v.Type() {
+		case cty.String:
+			after[name] = v.AsString()
+		case cty.Number:
+			f, _ := v.AsBigFloat().Float64()
+			after[name] = f
+		case cty.Bool:
+			after[name] = v.True()
+		}
+	}
+
+	slices.Sort(unknown)
+	return after, unknown
+}
+
+func evalString(ctx *hcl.EvalContext, expr hcl.Expression) (string, bool) {
+	v, diags := expr.Value(ctx)
+	if diags.HasErrors() || !v.IsWhollyKnown() || v.IsNull() || v.Type() != cty.String {
+		return "", false
+	}
+	return v.AsString(), true
+}
+
+func evalCount(ctx *hcl.EvalContext, expr hcl.Expression) (int, bool) {
+	v, diags := expr.Value(ctx)
+	if diags.HasErrors() || !v.IsWhollyKnown() || v.IsNull() || v.Type() != cty.Number {
+		return 0, false
+	}
+
+	bf := v.AsBigFloat()
+	if !bf.IsInt() || bf.Sign() < 0 {
+		return 0, false
+	}
+	n, _ := bf.Int64()
+	return int(n), true
+}
+
+type forEachItem struct {
+	key   string
+	value cty.Value
+}
+
+func evalForEach(ctx *hcl.EvalContext, expr hcl.Expression) ([]forEachItem, bool) {
+	v, diags := expr.Value(ctx)
+	if diags.HasErrors() || !v.IsWhollyKnown() || v.IsNull() {
+		return nil, false
+	}
+
+	ty := v.Type()
+	if !ty.IsMapType() && !ty.IsObjectType() && !ty.IsSetType() && !ty.IsListType() && !ty.IsTupleType() {
+		return nil, false
+	}
+
+	isMap := ty.IsMapType() || ty.IsObjectType()
+	items := make([]forEachItem, 0, v.LengthInt())
+	for it := v.ElementIterator(); it.Next(); {
+		k, elem := it.Element()
+		if isMap {
+			items = append(items, forEachItem{key: k.AsString(), value: elem})
+			continue
+		}
+		if elem.Type() != cty.String {
+			return nil, false
+		}
+		items = append(items, forEachItem{key: elem.AsString(), value: elem})
+	}
+
+	slices.SortFunc(items, func(a, b forEachItem) int { return strings.Compare(a.key, b.key) })
+	return slices.CompactFunc(items, func(a, b forEachItem) bool { return a.key == b.key }), true
+}
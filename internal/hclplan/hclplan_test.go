@@ -0,0 +1,194 @@
+package hclplan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDir(t *testing.T) {
+	t.Parallel()
+
+	t.Run("evaluates literals, variables, locals and count", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		writeFile(t, dir, "main.tf", `
+provider "scaleway" {
+  zone   = var.zone
+  region = "fr-par"
+}
+
+variable "zone" {
+  default = "fr-par-1"
+}
+
+variable "node_type" {
+  type    = string
+  default = "DEV1-S"
+}
+
+variable "replicas" {
+  default = 1
+}
+
+locals {
+  pool_size = var.replicas + 2
+}
+
+resource "scaleway_instance_server" "web" {
+  count = 2
+  type  = "DEV1-M"
+  name  = "web-${count.index}"
+  ip_id = scaleway_instance_ip.web.id
+}
+
+resource "scaleway_k8s_pool" "pool" {
+  node_type = var.node_type
+  size      = local.pool_size
+}
+
+resource "scaleway_instance_ip" "web" {}
+`)
+		writeFile(t, dir, "terraform.tfvars", `node_type = "GP1-XS"`)
+
+		changes, err := ParseDir(dir)
+		require.NoError(t, err)
+		require.Len(t, changes, 4)
+
+		assert.Equal(t, "scaleway_instance_server.web[0]", changes[0].Address)
+		assert.Equal(t, "scaleway_instance_server.web[1]", changes[1].Address)
+		assert.Equal(t, []string{"create"}, changes[0].Actions)
+		assert.Equal(t, "DEV1-M", changes[0].After["type"])
+		assert.Equal(t, "web-1", changes[1].After["name"])
+		assert.Equal(t, []string{"ip_id"}, changes[0].Unknown)
+		assert.Equal(t, "fr-par-1", changes[0].Zone)
+		assert.Equal(t, "fr-par", changes[0].Region)
+
+		assert.Equal(t, "scaleway_k8s_pool.pool", changes[2].Address)
+		assert.Equal(t, "GP1-XS", changes[2].After["node_type"])
+		assert.Equal(t, 3.0, changes[2].After["size"])
+		assert.Empty(t, changes[2].Unknown)
+
+		assert.Equal(t, "scaleway_instance_ip.web", changes[3].Address)
+		assert.NotNil(t, changes[3].After)
+	})
+
+	t.Run("marks values that need runtime data as unknown", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		writeFile(t, dir, "main.tf", `
+variable "node_type" {}
+
+data "scaleway_k8s_version" "latest" {
+  name = "latest"
+}
+
+resource "scaleway_rdb_instance" "db" {
+  node_type = var.node_type
+}
+
+resource "scaleway_instance_server" "workers" {
+  count = length(data.scaleway_k8s_version.latest.available_cnis)
+  type  = "DEV1-S"
+}
+
+resource "scaleway_lb" "edge" {
+  for_each = toset(["a", "b"])
+  type     = "LB-S"
+}
+
+resource "scaleway_block_volume" "data" {
+  for_each   = { small = 10, large = 50 }
+  size_in_gb = each.value
+}
+`)
+
+		changes, err := ParseDir(dir)
+		require.NoError(t, err)
+		require.Len(t, changes, 5)
+
+		assert.Equal(t, "scaleway_rdb_instance.db", changes[0].Address)
+		assert.Equal(t, []string{"node_type"}, changes[0].Unknown)
+
+		assert.Equal(t, "scaleway_instance_server.workers", changes[1].Address)
+		assert.Equal(t, []string{"count"}, changes[1].Unknown)
+
+		assert.Equal(t, "scaleway_lb.edge", changes[2].Address)
+		assert.Equal(t, []string{"for_each"}, changes[2].Unknown)
+
+		assert.Equal(t, `scaleway_block_volume.data["large"]`, changes[3].Address)
+		assert.Equal(t, 50.0, changes[3].After["size_in_gb"])
+		assert.Equal(t, `scaleway_block_volume.data["small"]`, changes[4].Address)
+	})
+
+	t.Run("marks the locality unknown when the provider zone cannot be evaluated", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		writeFile(t, dir, "main.tf", `
+provider "scaleway" {
+  zone   = data.scaleway_config.main.zone
+  region = "fr-par"
+}
+
+resource "scaleway_instance_server" "web" {
+  type = "DEV1-S"
+}
+
+resource "scaleway_instance_server" "pinned" {
+  type = "DEV1-S"
+  zone = "nl-ams-1"
+}
+
+resource "scaleway_instance_server" "dynamic" {
+  type = "DEV1-S"
+  zone = lookup(var.zones, "web")
+}
+`)
+
+		changes, err := ParseDir(dir)
+		require.NoError(t, err)
+		require.Len(t, changes, 3)
+
+		assert.Equal(t, "scaleway_instance_server.web", changes[0].Address)
+		assert.Empty(t, changes[0].Zone)
+		assert.Equal(t, "fr-par", changes[0].Region)
+		assert.Equal(t, []string{"zone"}, changes[0].Unknown)
+
+		assert.Equal(t, "scaleway_instance_server.pinned", changes[1].Address)
+		assert.Equal(t, "nl-ams-1", changes[1].After["zone"])
+		assert.Empty(t, changes[1].Unknown)
+
+		assert.Equal(t, "scaleway_instance_server.dynamic", changes[2].Address)
+		assert.Equal(t, []string{"zone"}, changes[2].Unknown)
+	})
+
+	t.Run("returns error without terraform files", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := ParseDir(t.TempDir())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no *.tf files")
+	})
+
+	t.Run("returns error for invalid syntax", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		writeFile(t, dir, "main.tf", `resource "scaleway_lb" {`)
+
+		_, err := ParseDir(dir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "could not parse")
+	})
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+}
@@ -2,6 +2,7 @@ package mapping
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/alesr/impact/internal/plan"
@@ -27,8 +28,22 @@ const (
 	ErrorCodeNoCatalogMatch           ErrorCode = "no_catalog_match"
 	ErrorCodeIgnoredNonImpact         ErrorCode = "ignored_non_impact"
 	ErrorCodeRequiresUsageInput       ErrorCode = "requires_usage_input"
+	ErrorCodeUnknownValue             ErrorCode = "unknown_value"
 )
 
+// sizingAttributes lists, per resource type, the attributes that select the
+// product or its quantity. An unknown value for any of them makes the
+// estimate unknown rather than silently falling back to a default.
+var sizingAttributes = map[string][]string{
+	"scaleway_instance_server":  {"type"},
+	"scaleway_baremetal_server": {"type"},
+	"scaleway_k8s_pool":         {"node_type", "size"},
+	"scaleway_lb":               {"type"},
+	"scaleway_block_volume":     {"size_in_gb"},
+	"scaleway_rdb_instance":     {"node_type"},
+	"scaleway_redis_cluster":    {"node_type", "cluster_size"},
+}
+
 type Error struct {
 	Code   ErrorCode
 	Reason string
@@ -60,6 +75,10 @@ func Resolve(change plan.ResourceChange, products []catalog.Product) (Result, er
 	if region == "" {
 		region = change.Region
 	}
+	if err := unknownValueError(change); err != nil {
+		return Result{}, err
+	}
+
 	rawResourceType := strings.TrimSpace(getString(attrs, "type"))
 	rawNodeType := strings.TrimSpace(getString(attrs, "node_type"))
 	resourceTypeToken := normalizeToken(rawResourceType)
@@ -161,6 +180,22 @@ func Resolve(change plan.ResourceChange, products []catalog.Product) (Result, er
 	}
 }
 
+func unknownValueError(change plan.ResourceChange) error {
+	if len(change.Unknown) == 0 {
+		return nil
+	}
+
+	// A zone or region that cannot be evaluated would otherwise fall back to
+	// the provider default or to any locality.
+	keys := append([]string{"count", "for_each", "zone", "region"}, sizingAttributes[change.Type]...)
+	for _, key := range keys {
+		if slices.Contains(change.Unknown, key) {
+			return &Error{Code: ErrorCodeUnknownValue, Reason: fmt.Sprintf("%s cannot be evaluated statically", key)}
+		}
+	}
+	return nil
+}
+
 func normalizeCount(value float64) int {
 	if value < 1 {
 		return 1
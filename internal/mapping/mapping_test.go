@@ -181,3 +181,47 @@ func TestResolve(t *testing.T) {
 		assert.Equal(t, ErrorCodeMissingRequiredAttribute, mappingErr.Code)
 	})
 }
+
+func TestResolveUnknownValues(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		change   plan.ResourceChange
+		wantCode ErrorCode
+	}{
+		{
+			name:     "unknown sizing attribute",
+			change:   plan.ResourceChange{Type: "scaleway_k8s_pool", After: map[string]any{"node_type": "DEV1-M"}, Unknown: []string{"size"}},
+			wantCode: ErrorCodeUnknownValue,
+		},
+		{
+			name:     "unknown count",
+			change:   plan.ResourceChange{Type: "scaleway_instance_server", After: map[string]any{"type": "DEV1-M"}, Unknown: []string{"count"}},
+			wantCode: ErrorCodeUnknownValue,
+		},
+		{
+			name:     "unknown zone",
+			change:   plan.ResourceChange{Type: "scaleway_instance_server", After: map[string]any{"type": "DEV1-M"}, Zone: "fr-par-1", Unknown: []string{"zone"}},
+			wantCode: ErrorCodeUnknownValue,
+		},
+		{
+			name:     "unknown attribute irrelevant to sizing",
+			change:   plan.ResourceChange{Type: "scaleway_instance_server", After: map[string]any{"zone": "fr-par-1", "type": "DEV1-M"}, Unknown: []string{"ip_id"}},
+			wantCode: ErrorCodeNoCatalogMatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Resolve(tt.change, nil)
+			require.Error(t, err)
+
+			var mappingErr *Error
+			require.ErrorAs(t, err, &mappingErr)
+			assert.Equal(t, tt.wantCode, mappingErr.Code)
+		})
+	}
+}
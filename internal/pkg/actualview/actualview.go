@@ -0,0 +1,130 @@
+package actualview
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alesr/impact/internal/scw/footprint"
+)
+
+type Depth int
+
+const (
+	DepthProject Depth = iota
+	DepthRegion
+	DepthZone
+	DepthSKU
+)
+
+type SortKey int
+
+const (
+	SortByCO2 SortKey = iota
+	SortByWater
+)
+
+// Line is one flattened entry of the impact tree. Fields below the depth it
+// was flattened at are empty.
+type Line struct {
+	// OrganizationID is only set by FlattenOrgs.
+	OrganizationID  string
+	ProjectID       string
+	ProjectName     string
+	Region          string
+	Zone            string
+	SKU             string
+	ServiceCategory string
+	ProductCategory string
+	Impact          footprint.TotalImpact
+}
+
+func ParseDepth(raw string) (Depth, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", "project":
+		return DepthProject, nil
+	case "region":
+		return DepthRegion, nil
+	case "zone":
+		return DepthZone, nil
+	case "sku":
+		return DepthSKU, nil
+	default:
+		return 0, fmt.Errorf("could not parse depth %q (use project, region, zone or sku)", raw)
+	}
+}
+
+func ParseSortKey(raw string) (SortKey, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", "co2":
+		return SortByCO2, nil
+	case "water":
+		return SortByWater, nil
+	default:
+		return 0, fmt.Errorf("could not parse sort key %q (use co2 or water)", raw)
+	}
+}
+
+// Flatten walks the impact tree down to depth. A node with no children above
+// depth is kept as a single line, so the lines always add up to the total.
+func Flatten(rep *footprint.QueryImpactDataResponse, depth Depth) []Line {
+	var lines []Line
+
+	for _, project := range rep.Projects {
+		if depth == DepthProject || len(project.Regions) == 0 {
+			lines = append(lines, Line{ProjectID: project.ProjectID, ProjectName: project.ProjectName, Impact: project.TotalProjectImpact})
+			continue
+		}
+		for _, region := range project.Regions {
+			if depth == DepthRegion || len(region.Zones) == 0 {
+				lines = append(lines, Line{ProjectID: project.ProjectID, ProjectName: project.ProjectName, Region: region.Region, Impact: region.TotalRegionImpact})
+				continue
+			}
+			for _, zone := range region.Zones {
+				if depth == DepthZone || len(zone.SKUs) == 0 {
+					lines = append(lines, Line{ProjectID: project.ProjectID, ProjectName: project.ProjectName, Region: region.Region, Zone: zone.Zone, Impact: zone.TotalZoneImpact})
+					continue
+				}
+				for _, sku := range zone.SKUs {
+					lines = append(lines, Line{
+						ProjectID:       project.ProjectID,
+						ProjectName:     project.ProjectName,
+						Region:          region.Region,
+						Zone:            zone.Zone,
+						SKU:             sku.SKU,
+						ServiceCategory: sku.ServiceCategory,
+						ProductCategory: sku.ProductCategory,
+						Impact:          sku.TotalSKUImpact,
+					})
+				}
+			}
+		}
+	}
+	return lines
+}
+
+// SortLines orders lines by descending impact, keeping tree order for ties.
+func SortLines(lines []Line, key SortKey) {
+	sort.SliceStable(lines, func(i, j int) bool {
+		if key == SortByWater {
+			return lines[i].Impact.M3WaterUsage > lines[j].Impact.M3WaterUsage
+		}
+		return lines[i].Impact.KgCO2Equivalent > lines[j].Impact.KgCO2Equivalent
+	})
+}
+
+// ProjectLabel returns the project name when it is known, the ID otherwise.
+func ProjectLabel(id, name string) string {
+	if name != "" {
+		return name
+	}
+	return id
+}
+
+// Share returns v as a percentage of total, or 0 when total is 0.
+func Share(v, total float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return v / total * 100
+}
@@ -0,0 +1,96 @@
+package actualview
+
+import (
+	"testing"
+
+	"github.com/alesr/impact/internal/scw/footprint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testResponse() *footprint.QueryImpactDataResponse {
+	return &footprint.QueryImpactDataResponse{
+		TotalImpact: footprint.TotalImpact{KgCO2Equivalent: 10, M3WaterUsage: 1},
+		Projects: []footprint.ProjectImpact{
+			{
+				ProjectID:          "p1",
+				TotalProjectImpact: footprint.TotalImpact{KgCO2Equivalent: 8, M3WaterUsage: 0.2},
+				Regions: []footprint.RegionImpact{{
+					Region:            "fr-par",
+					TotalRegionImpact: footprint.TotalImpact{KgCO2Equivalent: 8, M3WaterUsage: 0.2},
+					Zones: []footprint.ZoneImpact{{
+						Zone:            "fr-par-1",
+						TotalZoneImpact: footprint.TotalImpact{KgCO2Equivalent: 8, M3WaterUsage: 0.2},
+						SKUs: []footprint.SKUImpact{
+							{SKU: "a", TotalSKUImpact: footprint.TotalImpact{KgCO2Equivalent: 3, M3WaterUsage: 0.15}},
+							{SKU: "b", TotalSKUImpact: footprint.TotalImpact{KgCO2Equivalent: 5, M3WaterUsage: 0.05}},
+						},
+					}},
+				}},
+			},
+			{ProjectID: "p2", TotalProjectImpact: footprint.TotalImpact{KgCO2Equivalent: 2, M3WaterUsage: 0.8}},
+		},
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stops at the requested depth", func(t *testing.T) {
+		t.Parallel()
+
+		lines := Flatten(testResponse(), DepthRegion)
+		require.Len(t, lines, 2)
+		assert.Equal(t, Line{ProjectID: "p1", Region: "fr-par", Impact: footprint.TotalImpact{KgCO2Equivalent: 8, M3WaterUsage: 0.2}}, lines[0])
+		assert.Equal(t, "p2", lines[1].ProjectID)
+		assert.Empty(t, lines[1].Region)
+	})
+
+	t.Run("keeps childless nodes at sku depth", func(t *testing.T) {
+		t.Parallel()
+
+		lines := Flatten(testResponse(), DepthSKU)
+		require.Len(t, lines, 3)
+		assert.Equal(t, "a", lines[0].SKU)
+		assert.Equal(t, "b", lines[1].SKU)
+		assert.Equal(t, "p2", lines[2].ProjectID)
+	})
+}
+
+func TestSortLines(t *testing.T) {
+	t.Parallel()
+
+	lines := Flatten(testResponse(), DepthSKU)
+
+	SortLines(lines, SortByCO2)
+	assert.Equal(t, []string{"b", "a", ""}, []string{lines[0].SKU, lines[1].SKU, lines[2].SKU})
+
+	SortLines(lines, SortByWater)
+	assert.Equal(t, []string{"p2", "p1", "p1"}, []string{lines[0].ProjectID, lines[1].ProjectID, lines[2].ProjectID})
+	assert.Equal(t, "a", lines[1].SKU)
+}
+
+func TestParseDepth(t *testing.T) {
+	t.Parallel()
+
+	depth, err := ParseDepth("Zone")
+	require.NoError(t, err)
+	assert.Equal(t, DepthZone, depth)
+
+	_, err = ParseDepth("org")
+	require.Error(t, err)
+}
+
+func TestShare(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 25.0, Share(2.5, 10))
+	assert.Equal(t, 0.0, Share(1, 0))
+}
+
+func TestProjectLabel(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "web", ProjectLabel("p1", "web"))
+	assert.Equal(t, "p1", ProjectLabel("p1", ""))
+}
@@ -0,0 +1,162 @@
+package actualview
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/alesr/impact/internal/scw/footprint"
+)
+
+// Delta compares one entry of the impact tree across two periods. Current and
+// Previous are monthly rates (see MonthlyScale), so periods of different
+// lengths compare fairly. The percentage fields are nil when the previous
+// value is zero.
+type Delta struct {
+	ProjectID      string                `json:"project_id,omitempty"`
+	ProjectName    string                `json:"project_name,omitempty"`
+	Region         string                `json:"region,omitempty"`
+	Zone           string                `json:"zone,omitempty"`
+	SKU            string                `json:"sku,omitempty"`
+	Current        footprint.TotalImpact `json:"current"`
+	Previous       footprint.TotalImpact `json:"previous"`
+	KgCO2eDelta    float64               `json:"kgco2e_delta"`
+	KgCO2ePercent  *float64              `json:"kgco2e_percent,omitempty"`
+	M3WaterDelta   float64               `json:"m3_water_delta"`
+	M3WaterPercent *float64              `json:"m3_water_percent,omitempty"`
+}
+
+type Comparison struct {
+	Current  Window  `json:"current"`
+	Previous Window  `json:"previous"`
+	Total    Delta   `json:"total"`
+	Projects []Delta `json:"projects"`
+	Regions  []Delta `json:"regions"`
+	SKUs     []Delta `json:"skus"`
+}
+
+// PreviousWindow returns the period right before w. Whole calendar months
+// map to the same number of months before, anything else to a window of the
+// same duration.
+func PreviousWindow(w Window) Window {
+	if isMonthStart(w.Start) && isMonthStart(w.End) {
+		months := (w.End.Year()-w.Start.Year())*12 + int(w.End.Month()-w.Start.Month())
+		return Window{Start: w.Start.AddDate(0, -months, 0), End: w.Start}
+	}
+	return Window{Start: w.Start.Add(-w.End.Sub(w.Start)), End: w.Start}
+}
+
+func isMonthStart(t time.Time) bool {
+	return t.Day() == 1 && t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0
+}
+
+// Compare computes deltas at the project, region and SKU levels from the
+// monthly rate of each window. Entries present in only one period are
+// compared against zero. Each level is sorted by the absolute delta of key,
+// largest movers first.
+func Compare(current, previous *footprint.QueryImpactDataResponse, currentWindow, previousWindow Window, key SortKey) Comparison {
+	currentScale, previousScale := MonthlyScale(currentWindow), MonthlyScale(previousWindow)
+	lines := func(depth Depth) []Delta {
+		return compareLines(scaleLines(Flatten(current, depth), currentScale), scaleLines(Flatten(previous, depth), previousScale), key)
+	}
+
+	return Comparison{
+		Current:  currentWindow,
+		Previous: previousWindow,
+		Total:    newDelta(Line{}, ScaleImpact(current.TotalImpact, currentScale), ScaleImpact(previous.TotalImpact, previousScale)),
+		Projects: lines(DepthProject),
+		Regions:  lines(DepthRegion),
+		SKUs:     lines(DepthSKU),
+	}
+}
+
+func scaleLines(lines []Line, scale float64) []Line {
+	for i := range lines {
+		lines[i].Impact = ScaleImpact(lines[i].Impact, scale)
+	}
+	return lines
+}
+
+type lineKey struct {
+	project, region, zone, sku string
+}
+
+func compareLines(current, previous []Line, key SortKey) []Delta {
+	type pair struct {
+		line              Line
+		current, previous footprint.TotalImpact
+	}
+
+	var order []lineKey
+	pairs := map[lineKey]*pair{}
+	add := func(line Line, isCurrent bool) {
+		k := lineKey{line.ProjectID, line.Region, line.Zone, line.SKU}
+		p, ok := pairs[k]
+		if !ok {
+			p = &pair{line: line}
+			pairs[k] = p
+			order = append(order, k)
+		}
+		if isCurrent {
+			p.current = addImpact(p.current, line.Impact)
+		} else {
+			p.previous = addImpact(p.previous, line.Impact)
+		}
+	}
+
+	for _, line := range current {
+		add(line, true)
+	}
+	for _, line := range previous {
+		add(line, false)
+	}
+
+	deltas := make([]Delta, 0, len(order))
+	for _, k := range order {
+		p := pairs[k]
+		deltas = append(deltas, newDelta(p.line, p.current, p.previous))
+	}
+
+	sort.SliceStable(deltas, func(i, j int) bool {
+		return math.Abs(deltaValue(deltas[i], key)) > math.Abs(deltaValue(deltas[j], key))
+	})
+	return deltas
+}
+
+func newDelta(line Line, current, previous footprint.TotalImpact) Delta {
+	return Delta{
+		ProjectID:      line.ProjectID,
+		ProjectName:    line.ProjectName,
+		Region:         line.Region,
+		Zone:           line.Zone,
+		SKU:            line.SKU,
+		Current:        current,
+		Previous:       previous,
+		KgCO2eDelta:    current.KgCO2Equivalent - previous.KgCO2Equivalent,
+		KgCO2ePercent:  percentChange(current.KgCO2Equivalent, previous.KgCO2Equivalent),
+		M3WaterDelta:   current.M3WaterUsage - previous.M3WaterUsage,
+		M3WaterPercent: percentChange(current.M3WaterUsage, previous.M3WaterUsage),
+	}
+}
+
+func deltaValue(d Delta, key SortKey) float64 {
+	if key == SortByWater {
+		return d.M3WaterDelta
+	}
+	return d.KgCO2eDelta
+}
+
+func percentChange(current, previous float64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	v := (current - previous) / previous * 100
+	return &v
+}
+
+func addImpact(a, b footprint.TotalImpact) footprint.TotalImpact {
+	return footprint.TotalImpact{
+		KgCO2Equivalent: a.KgCO2Equivalent + b.KgCO2Equivalent,
+		M3WaterUsage:    a.M3WaterUsage + b.M3WaterUsage,
+	}
+}
@@ -0,0 +1,91 @@
+package actualview
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alesr/impact/internal/estimate"
+	"github.com/alesr/impact/internal/scw/footprint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviousWindow(t *testing.T) {
+	t.Parallel()
+
+	t.Run("calendar months", func(t *testing.T) {
+		t.Parallel()
+
+		prev := PreviousWindow(Window{Start: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)})
+		assert.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), prev.Start)
+		assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), prev.End)
+	})
+
+	t.Run("arbitrary range keeps its duration", func(t *testing.T) {
+		t.Parallel()
+
+		prev := PreviousWindow(Window{Start: time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), End: time.Date(2026, 3, 17, 0, 0, 0, 0, time.UTC)})
+		assert.Equal(t, time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), prev.Start)
+		assert.Equal(t, time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), prev.End)
+	})
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	sku := func(name string, kg float64) footprint.SKUImpact {
+		return footprint.SKUImpact{SKU: name, TotalSKUImpact: footprint.TotalImpact{KgCO2Equivalent: kg}}
+	}
+	project := func(id string, kg float64, skus ...footprint.SKUImpact) footprint.ProjectImpact {
+		return footprint.ProjectImpact{
+			ProjectID:          id,
+			TotalProjectImpact: footprint.TotalImpact{KgCO2Equivalent: kg},
+			Regions: []footprint.RegionImpact{{
+				Region:            "fr-par",
+				TotalRegionImpact: footprint.TotalImpact{KgCO2Equivalent: kg},
+				Zones:             []footprint.ZoneImpact{{Zone: "fr-par-1", TotalZoneImpact: footprint.TotalImpact{KgCO2Equivalent: kg}, SKUs: skus}},
+			}},
+		}
+	}
+
+	current := &footprint.QueryImpactDataResponse{
+		TotalImpact: footprint.TotalImpact{KgCO2Equivalent: 15},
+		Projects:    []footprint.ProjectImpact{project("steady", 5, sku("a", 5)), project("new", 10, sku("b", 10))},
+	}
+	// The previous window is twice as long, so its totals are doubled: the
+	// deltas below are between monthly rates.
+	previous := &footprint.QueryImpactDataResponse{
+		TotalImpact: footprint.TotalImpact{KgCO2Equivalent: 24},
+		Projects:    []footprint.ProjectImpact{project("steady", 8, sku("a", 8)), project("gone", 16, sku("c", 16))},
+	}
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	month := time.Duration(estimate.MonthlyHours) * time.Hour
+	currentWindow := Window{Start: start, End: start.Add(month)}
+	previousWindow := Window{Start: start.Add(-2 * month), End: start}
+
+	cmp := Compare(current, previous, currentWindow, previousWindow, SortByCO2)
+
+	assert.Equal(t, 15.0, cmp.Total.Current.KgCO2Equivalent)
+	assert.Equal(t, 12.0, cmp.Total.Previous.KgCO2Equivalent)
+
+	assert.Equal(t, 3.0, cmp.Total.KgCO2eDelta)
+	require.NotNil(t, cmp.Total.KgCO2ePercent)
+	assert.Equal(t, 25.0, *cmp.Total.KgCO2ePercent)
+
+	require.Len(t, cmp.Projects, 3)
+	assert.Equal(t, "new", cmp.Projects[0].ProjectID)
+	assert.Nil(t, cmp.Projects[0].KgCO2ePercent)
+	assert.Equal(t, "gone", cmp.Projects[1].ProjectID)
+	assert.Equal(t, -8.0, cmp.Projects[1].KgCO2eDelta)
+	require.NotNil(t, cmp.Projects[1].KgCO2ePercent)
+	assert.Equal(t, -100.0, *cmp.Projects[1].KgCO2ePercent)
+	assert.Equal(t, "steady", cmp.Projects[2].ProjectID)
+
+	require.Len(t, cmp.Regions, 3)
+	assert.Equal(t, "fr-par", cmp.Regions[0].Region)
+
+	require.Len(t, cmp.SKUs, 3)
+	assert.Equal(t, "b", cmp.SKUs[0].SKU)
+	assert.Equal(t, "a", cmp.SKUs[2].SKU)
+	assert.Equal(t, 1.0, cmp.SKUs[2].KgCO2eDelta)
+}
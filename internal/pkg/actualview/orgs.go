@@ -0,0 +1,60 @@
+package actualview
+
+import (
+	"time"
+
+	"github.com/alesr/impact/internal/scw/footprint"
+)
+
+// OrgImpact is the measured impact of one organization in a combined report.
+type OrgImpact struct {
+	OrganizationID string
+	Profile        string
+	Response       *footprint.QueryImpactDataResponse
+}
+
+// OrgReport combines the responses of several organizations queried for the
+// same period.
+type OrgReport struct {
+	StartDate     time.Time
+	EndDate       time.Time
+	TotalImpact   footprint.TotalImpact
+	Organizations []OrgImpact
+}
+
+// CombineOrgs sums the organization totals. The period spans the earliest
+// start and the latest end reported by the API.
+func CombineOrgs(orgs []OrgImpact) OrgReport {
+	rep := OrgReport{Organizations: orgs}
+	first := true
+	for _, org := range orgs {
+		if org.Response == nil {
+			continue
+		}
+		if first || org.Response.StartDate.Before(rep.StartDate) {
+			rep.StartDate = org.Response.StartDate
+		}
+		if org.Response.EndDate.After(rep.EndDate) {
+			rep.EndDate = org.Response.EndDate
+		}
+		rep.TotalImpact = addImpact(rep.TotalImpact, org.Response.TotalImpact)
+		first = false
+	}
+	return rep
+}
+
+// FlattenOrgs flattens every organization to depth and tags the lines with
+// their organization.
+func FlattenOrgs(rep OrgReport, depth Depth) []Line {
+	var lines []Line
+	for _, org := range rep.Organizations {
+		if org.Response == nil {
+			continue
+		}
+		for _, line := range Flatten(org.Response, depth) {
+			line.OrganizationID = org.OrganizationID
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
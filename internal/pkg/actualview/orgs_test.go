@@ -0,0 +1,37 @@
+package actualview
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alesr/impact/internal/scw/footprint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCombineOrgs(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	rep := CombineOrgs([]OrgImpact{
+		{OrganizationID: "org-a", Response: testResponse()},
+		{OrganizationID: "org-b", Response: &footprint.QueryImpactDataResponse{
+			StartDate:   start,
+			EndDate:     end,
+			TotalImpact: footprint.TotalImpact{KgCO2Equivalent: 1, M3WaterUsage: 1},
+			Projects:    []footprint.ProjectImpact{{ProjectID: "pb", TotalProjectImpact: footprint.TotalImpact{KgCO2Equivalent: 1, M3WaterUsage: 1}}},
+		}},
+	})
+
+	assert.Equal(t, testResponse().TotalImpact.KgCO2Equivalent+1, rep.TotalImpact.KgCO2Equivalent)
+	assert.Equal(t, end, rep.EndDate)
+
+	lines := FlattenOrgs(rep, DepthProject)
+	require.NotEmpty(t, lines)
+	assert.Equal(t, "org-a", lines[0].OrganizationID)
+	last := lines[len(lines)-1]
+	assert.Equal(t, "org-b", last.OrganizationID)
+	assert.Equal(t, "pb", last.ProjectID)
+}
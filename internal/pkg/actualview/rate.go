@@ -0,0 +1,29 @@
+package actualview
+
+import (
+	"github.com/alesr/impact/internal/estimate"
+	"github.com/alesr/impact/internal/scw/footprint"
+)
+
+// MonthlyScale returns the factor that turns a total measured over w into a
+// rate per month of estimate.MonthlyHours, the month plan estimates use. It
+// returns 0 for an empty or inverted window.
+func MonthlyScale(w Window) float64 {
+	hours := w.End.Sub(w.Start).Hours()
+	if hours <= 0 {
+		return 0
+	}
+	return estimate.MonthlyHours / hours
+}
+
+func ScaleImpact(impact footprint.TotalImpact, factor float64) footprint.TotalImpact {
+	return footprint.TotalImpact{
+		KgCO2Equivalent: impact.KgCO2Equivalent * factor,
+		M3WaterUsage:    impact.M3WaterUsage * factor,
+	}
+}
+
+// ResponseWindow returns the period a response covers.
+func ResponseWindow(rep *footprint.QueryImpactDataResponse) Window {
+	return Window{Start: rep.StartDate, End: rep.EndDate}
+}
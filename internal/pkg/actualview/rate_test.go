@@ -0,0 +1,28 @@
+package actualview
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alesr/impact/internal/scw/footprint"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonthlyScale(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.InDelta(t, 730.0/(90*24), MonthlyScale(Window{Start: start, End: start.AddDate(0, 0, 90)}), 1e-12)
+	assert.InDelta(t, 1, MonthlyScale(Window{Start: start, End: start.Add(730 * time.Hour)}), 1e-12)
+	assert.Zero(t, MonthlyScale(Window{Start: start, End: start}))
+	assert.Zero(t, MonthlyScale(Window{}))
+}
+
+func TestScaleImpact(t *testing.T) {
+	t.Parallel()
+
+	scaled := ScaleImpact(footprint.TotalImpact{KgCO2Equivalent: 3, M3WaterUsage: 0.3}, 0.5)
+	assert.InDelta(t, 1.5, scaled.KgCO2Equivalent, 1e-12)
+	assert.InDelta(t, 0.15, scaled.M3WaterUsage, 1e-12)
+}
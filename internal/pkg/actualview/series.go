@@ -0,0 +1,122 @@
+package actualview
+
+import (
+	"strings"
+	"time"
+
+	"github.com/alesr/impact/internal/scw/footprint"
+)
+
+const monthLayout = "2006-01"
+
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// Window is a half-open [Start, End) query period.
+type Window struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// MonthImpact holds the measured impact of one calendar month, or of the
+// part of it that falls inside the requested range.
+type MonthImpact struct {
+	Month       string                `json:"month"`
+	StartDate   time.Time             `json:"start_date"`
+	EndDate     time.Time             `json:"end_date"`
+	TotalImpact footprint.TotalImpact `json:"total_impact"`
+	Projects    []ProjectTotal        `json:"projects"`
+}
+
+type ProjectTotal struct {
+	ProjectID   string                `json:"project_id"`
+	ProjectName string                `json:"project_name,omitempty"`
+	Impact      footprint.TotalImpact `json:"impact"`
+}
+
+// MonthWindows splits [start, end) on calendar month boundaries in the
+// location of start. The first and last windows may be partial months.
+func MonthWindows(start, end time.Time) []Window {
+	var windows []Window
+	for cur := start; cur.Before(end); {
+		next := time.Date(cur.Year(), cur.Month()+1, 1, 0, 0, 0, 0, cur.Location())
+		if next.After(end) {
+			next = end
+		}
+		windows = append(windows, Window{Start: cur, End: next})
+		cur = next
+	}
+	return windows
+}
+
+func NewMonthImpact(w Window, resp *footprint.QueryImpactDataResponse) MonthImpact {
+	month := MonthImpact{
+		Month:       w.Start.Format(monthLayout),
+		StartDate:   w.Start,
+		EndDate:     w.End,
+		TotalImpact: resp.TotalImpact,
+		Projects:    make([]ProjectTotal, 0, len(resp.Projects)),
+	}
+	for _, project := range resp.Projects {
+		month.Projects = append(month.Projects, ProjectTotal{ProjectID: project.ProjectID, ProjectName: project.ProjectName, Impact: project.TotalProjectImpact})
+	}
+	return month
+}
+
+// ProjectIDs returns every project seen in the series, in order of first
+// appearance.
+func ProjectIDs(months []MonthImpact) []string {
+	var ids []string
+	seen := map[string]struct{}{}
+	for _, month := range months {
+		for _, project := range month.Projects {
+			if _, ok := seen[project.ProjectID]; ok {
+				continue
+			}
+			seen[project.ProjectID] = struct{}{}
+			ids = append(ids, project.ProjectID)
+		}
+	}
+	return ids
+}
+
+// ProjectImpact returns the impact of projectID in month, or zero when the
+// project has no data that month.
+func (m MonthImpact) ProjectImpact(projectID string) footprint.TotalImpact {
+	for _, project := range m.Projects {
+		if project.ProjectID == projectID {
+			return project.Impact
+		}
+	}
+	return footprint.TotalImpact{}
+}
+
+// Value returns the impact figure selected by key.
+func Value(impact footprint.TotalImpact, key SortKey) float64 {
+	if key == SortByWater {
+		return impact.M3WaterUsage
+	}
+	return impact.KgCO2Equivalent
+}
+
+// Sparkline renders values as block characters scaled between the smallest
+// and largest value.
+func Sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	lo, hi := values[0], values[0]
+	for _, v := range values[1:] {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		idx := 0
+		if hi > lo {
+			idx = int((v - lo) / (hi - lo) * float64(len(sparkTicks)-1))
+		}
+		b.WriteRune(sparkTicks[idx])
+	}
+	return b.String()
+}
@@ -0,0 +1,60 @@
+package actualview
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alesr/impact/internal/scw/footprint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonthWindows(t *testing.T) {
+	t.Parallel()
+
+	t.Run("splits on calendar months with partial edges", func(t *testing.T) {
+		t.Parallel()
+
+		windows := MonthWindows(
+			time.Date(2025, 11, 15, 0, 0, 0, 0, time.UTC),
+			time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC),
+		)
+
+		require.Len(t, windows, 4)
+		assert.Equal(t, time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), windows[0].End)
+		assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), windows[2].Start)
+		assert.Equal(t, time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC), windows[3].End)
+	})
+
+	t.Run("returns nothing for an empty range", func(t *testing.T) {
+		t.Parallel()
+
+		day := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		assert.Empty(t, MonthWindows(day, day))
+	})
+}
+
+func TestNewMonthImpact(t *testing.T) {
+	t.Parallel()
+
+	w := Window{Start: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)}
+	month := NewMonthImpact(w, &footprint.QueryImpactDataResponse{
+		TotalImpact: footprint.TotalImpact{KgCO2Equivalent: 3},
+		Projects:    []footprint.ProjectImpact{{ProjectID: "p1", TotalProjectImpact: footprint.TotalImpact{KgCO2Equivalent: 3}}},
+	})
+
+	assert.Equal(t, "2026-03", month.Month)
+	assert.Equal(t, 3.0, month.ProjectImpact("p1").KgCO2Equivalent)
+	assert.Equal(t, footprint.TotalImpact{}, month.ProjectImpact("p2"))
+
+	other := MonthImpact{Projects: []ProjectTotal{{ProjectID: "p2"}, {ProjectID: "p1"}}}
+	assert.Equal(t, []string{"p1", "p2"}, ProjectIDs([]MonthImpact{month, other}))
+}
+
+func TestSparkline(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "", Sparkline(nil))
+	assert.Equal(t, "▁▁▁", Sparkline([]float64{2, 2, 2}))
+	assert.Equal(t, "▁▄█", Sparkline([]float64{0, 5, 10}))
+}
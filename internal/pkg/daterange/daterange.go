@@ -0,0 +1,130 @@
+package daterange
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layout is the format of absolute dates on the command line and in reports.
+const Layout = "2006-01-02"
+
+var (
+	relativePattern = regexp.MustCompile(`^-(\d+)([dwmy])$`)
+	lastDaysPattern = regexp.MustCompile(`^last-(\d+)d$`)
+	quarterPattern  = regexp.MustCompile(`^(\d{4})-[qQ]([1-4])$`)
+	monthPattern    = regexp.MustCompile(`^\d{4}-\d{2}$`)
+	yearPattern     = regexp.MustCompile(`^\d{4}$`)
+)
+
+// ParseDate parses an absolute date (YYYY-MM-DD or RFC3339) or one relative
+// to now: "now", "today" or -N followed by d, w, m or y, counted back from
+// midnight UTC today (for example -90d or -3m).
+func ParseDate(raw string, now time.Time) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, errors.New("could not parse date: date is empty")
+	}
+
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse(Layout, raw); err == nil {
+		return t.UTC(), nil
+	}
+
+	today := startOfDay(now)
+	switch strings.ToLower(raw) {
+	case "now":
+		return now.UTC().Truncate(time.Second), nil
+	case "today":
+		return today, nil
+	}
+
+	if m := relativePattern.FindStringSubmatch(strings.ToLower(raw)); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("could not parse relative date %q: %w", raw, err)
+		}
+		switch m[2] {
+		case "d":
+			return today.AddDate(0, 0, -n), nil
+		case "w":
+			return today.AddDate(0, 0, -7*n), nil
+		case "m":
+			return today.AddDate(0, -n, 0), nil
+		default:
+			return today.AddDate(-n, 0, 0), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("could not validate date %q (use YYYY-MM-DD, RFC3339, now, today or a relative date such as -90d, -4w, -3m, -1y)", raw)
+}
+
+// ParsePeriod resolves a named period to a half-open [start, end) range:
+// last-month, this-month, last-Nd, ytd, a year (2026), a quarter (2026-Q2)
+// or a month (2026-03). Periods that reach past now end at now.
+func ParsePeriod(raw string, now time.Time) (time.Time, time.Time, error) {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	now = now.UTC().Truncate(time.Second)
+	today := startOfDay(now)
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	var start, end time.Time
+	switch {
+	case value == "last-month":
+		start, end = thisMonth.AddDate(0, -1, 0), thisMonth
+	case value == "this-month":
+		start, end = thisMonth, now
+	case value == "ytd":
+		start, end = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.UTC), now
+	case lastDaysPattern.MatchString(value):
+		days, err := strconv.Atoi(lastDaysPattern.FindStringSubmatch(value)[1])
+		if err != nil || days == 0 {
+			return time.Time{}, time.Time{}, fmt.Errorf("could not parse period %q: day count must be positive", raw)
+		}
+		start, end = today.AddDate(0, 0, -days), today
+	case quarterPattern.MatchString(value):
+		m := quarterPattern.FindStringSubmatch(value)
+		year, _ := strconv.Atoi(m[1])
+		quarter, _ := strconv.Atoi(m[2])
+		start = time.Date(year, time.Month(3*(quarter-1)+1), 1, 0, 0, 0, 0, time.UTC)
+		end = start.AddDate(0, 3, 0)
+	case monthPattern.MatchString(value):
+		t, err := time.Parse("2006-01", value)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("could not parse period %q: %w", raw, err)
+		}
+		start, end = t, t.AddDate(0, 1, 0)
+	case yearPattern.MatchString(value):
+		year, _ := strconv.Atoi(value)
+		start = time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+		end = start.AddDate(1, 0, 0)
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("could not parse period %q (use last-month, this-month, last-Nd, ytd, YYYY, YYYY-Qn or YYYY-MM)", raw)
+	}
+
+	if !start.Before(now) {
+		return time.Time{}, time.Time{}, fmt.Errorf("could not resolve period %q: it starts in the future", raw)
+	}
+	if end.After(now) {
+		end = now
+	}
+	return start, end, nil
+}
+
+// Validate checks that start is before end.
+func Validate(start, end time.Time) error {
+	if !start.Before(end) {
+		return fmt.Errorf("could not validate date range: start %s must be before end %s", start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
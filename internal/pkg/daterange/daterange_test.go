@@ -0,0 +1,103 @@
+package daterange
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2026, 5, 14, 10, 30, 0, 0, time.UTC)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseDate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"2026-01-02", date(2026, 1, 2)},
+		{"2026-01-02T03:04:05+02:00", time.Date(2026, 1, 2, 1, 4, 5, 0, time.UTC)},
+		{"now", now},
+		{"today", date(2026, 5, 14)},
+		{"-90d", date(2026, 2, 13)},
+		{"-2w", date(2026, 4, 30)},
+		{"-3m", date(2026, 2, 14)},
+		{"-1Y", date(2025, 5, 14)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseDate(tt.input, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for _, input := range []string{"", "90d", "-3h", "yesterday"} {
+		_, err := ParseDate(input, now)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestParsePeriod(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input      string
+		start, end time.Time
+	}{
+		{"last-month", date(2026, 4, 1), date(2026, 5, 1)},
+		{"this-month", date(2026, 5, 1), now},
+		{"last-30d", date(2026, 4, 14), date(2026, 5, 14)},
+		{"ytd", date(2026, 1, 1), now},
+		{"2026-Q1", date(2026, 1, 1), date(2026, 4, 1)},
+		{"2026-q2", date(2026, 4, 1), now},
+		{"2026-03", date(2026, 3, 1), date(2026, 4, 1)},
+		{"2025", date(2025, 1, 1), date(2026, 1, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			start, end, err := ParsePeriod(tt.input, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.start, start)
+			assert.Equal(t, tt.end, end)
+		})
+	}
+
+	t.Run("rejects unknown and future periods", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, err := ParsePeriod("last-week", now)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "use last-month")
+
+		_, _, err = ParsePeriod("2026-Q3", now)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "starts in the future")
+
+		_, _, err = ParsePeriod("last-0d", now)
+		require.Error(t, err)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, Validate(date(2026, 1, 1), date(2026, 2, 1)))
+	require.NoError(t, Validate(date(2024, 1, 1), date(2026, 1, 1)))
+
+	err := Validate(date(2026, 2, 1), date(2026, 1, 1))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be before end")
+}
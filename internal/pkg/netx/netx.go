@@ -0,0 +1,13 @@
+package netx
+
+import "net"
+
+// IsLoopback reports whether host, as returned by url.URL.Hostname, is
+// localhost or a loopback IP address.
+func IsLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,25 @@
+package netx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsLoopback(t *testing.T) {
+	t.Parallel()
+
+	for host, want := range map[string]bool{
+		"localhost":        true,
+		"127.0.0.1":        true,
+		"127.8.9.10":       true,
+		"::1":              true,
+		"":                 false,
+		"example.com":      false,
+		"localhost.evil":   false,
+		"10.0.0.1":         false,
+		"api.scaleway.com": false,
+	} {
+		assert.Equal(t, want, IsLoopback(host), host)
+	}
+}
@@ -0,0 +1,461 @@
+package plan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// mappingAttributes lists the resource attributes read by the mapping layer,
+// plus project_id which reconcile uses to scope its footprint query.
+// Everything else in before/after is skipped while decoding.
+var mappingAttributes = map[string]struct{}{
+	"zone":         {},
+	"region":       {},
+	"type":         {},
+	"node_type":    {},
+	"size":         {},
+	"size_in_gb":   {},
+	"cluster_size": {},
+	"project_id":   {},
+}
+
+func decodePlan(r io.Reader) ([]ResourceChange, error) {
+	dec := json.NewDecoder(r)
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+
+	var (
+		changes       = []ResourceChange{}
+		defaultZone   string
+		defaultRegion string
+	)
+
+	for dec.More() {
+		key, err := readKey(dec)
+		if err != nil {
+			return nil, err
+		}
+
+		switch key {
+		case "format_version":
+			var version string
+			if err := dec.Decode(&version); err != nil {
+				return nil, fmt.Errorf("invalid format_version: %w", err)
+			}
+			if err := validateFormatVersion(version); err != nil {
+				return nil, err
+			}
+		case "variables":
+			vars, err := decodeVariables(dec, "zone", "region")
+			if err != nil {
+				return nil, err
+			}
+			defaultZone, defaultRegion = vars["zone"], vars["region"]
+		case "resource_changes":
+			if changes, err = decodeResourceChanges(dec, changes); err != nil {
+				return nil, err
+			}
+		case "values":
+			// State JSON (`terraform show -json` without a plan file) lists
+			// the current resources under values instead of changes.
+			if changes, err = decodeStateValues(dec, changes); err != nil {
+				return nil, fmt.Errorf("invalid state values: %w", err)
+			}
+		default:
+			if err := skipValue(dec); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+
+	for i := range changes {
+		changes[i].Zone = defaultZone
+		changes[i].Region = defaultRegion
+	}
+	return changes, nil
+}
+
+func validateFormatVersion(version string) error {
+	major, _, _ := strings.Cut(version, ".")
+	if major != "0" && major != "1" {
+		return fmt.Errorf("unsupported plan format_version %q", version)
+	}
+	return nil
+}
+
+func decodeVariables(dec *json.Decoder, keys ...string) (map[string]string, error) {
+	out := make(map[string]string, len(keys))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok == nil {
+		return out, nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("invalid variables: expected object")
+	}
+
+	for dec.More() {
+		name, err := readKey(dec)
+		if err != nil {
+			return nil, err
+		}
+
+		if !slices.Contains(keys, name) {
+			if err := skipValue(dec); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var variable struct {
+			Value any `json:"value"`
+		}
+		if err := dec.Decode(&variable); err != nil {
+			return nil, fmt.Errorf("invalid variable %q: %w", name, err)
+		}
+		if s, ok := variable.Value.(string); ok {
+			out[name] = s
+		}
+	}
+	return out, expectDelim(dec, '}')
+}
+
+func decodeStateValues(dec *json.Decoder, changes []ResourceChange) ([]ResourceChange, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok == nil {
+		return changes, nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, errors.New("expected object")
+	}
+
+	for dec.More() {
+		key, err := readKey(dec)
+		if err != nil {
+			return nil, err
+		}
+		if key != "root_module" {
+			if err := skipValue(dec); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if changes, err = decodeStateModule(dec, changes); err != nil {
+			return nil, err
+		}
+	}
+	return changes, expectDelim(dec, '}')
+}
+
+// decodeStateModule appends the managed resources of a state module and of
+// its child modules as no-op changes whose before and after both hold the
+// current values.
+func decodeStateModule(dec *json.Decoder, changes []ResourceChange) ([]ResourceChange, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok == nil {
+		return changes, nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, errors.New("invalid module: expected object")
+	}
+
+	for dec.More() {
+		key, err := readKey(dec)
+		if err != nil {
+			return nil, err
+		}
+
+		switch key {
+		case "resources":
+			changes, err = decodeArray(dec, changes, decodeStateResource)
+		case "child_modules":
+			changes, err = decodeArray(dec, changes, decodeStateModule)
+		default:
+			err = skipValue(dec)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return changes, expectDelim(dec, '}')
+}
+
+func decodeStateResource(dec *json.Decoder, changes []ResourceChange) ([]ResourceChange, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok == nil {
+		return changes, nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, errors.New("invalid resource: expected object")
+	}
+
+	var (
+		change = ResourceChange{Actions: []string{"no-op"}}
+		mode   string
+	)
+	for dec.More() {
+		key, err := readKey(dec)
+		if err != nil {
+			return nil, err
+		}
+
+		switch key {
+		case "address":
+			err = dec.Decode(&change.Address)
+		case "mode":
+			err = dec.Decode(&mode)
+		case "type":
+			err = dec.Decode(&change.Type)
+		case "values":
+			change.After, err = decodeAttributes(dec)
+		default:
+			err = skipValue(dec)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid resource %q: %w", change.Address, err)
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+
+	if mode != "managed" {
+		return changes, nil
+	}
+	change.Before = maps.Clone(change.After)
+	return append(changes, change), nil
+}
+
+// decodeArray calls decodeItem for every element of a JSON array.
+func decodeArray(dec *json.Decoder, changes []ResourceChange, decodeItem func(*json.Decoder, []ResourceChange) ([]ResourceChange, error)) ([]ResourceChange, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok == nil {
+		return changes, nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return nil, errors.New("expected array")
+	}
+
+	for dec.More() {
+		if changes, err = decodeItem(dec, changes); err != nil {
+			return nil, err
+		}
+	}
+	return changes, expectDelim(dec, ']')
+}
+
+func decodeResourceChanges(dec *json.Decoder, changes []ResourceChange) ([]ResourceChange, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok == nil {
+		return changes, nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return nil, fmt.Errorf("invalid resource_changes: expected array")
+	}
+
+	for dec.More() {
+		change, ok, err := decodeResourceChange(dec)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			changes = append(changes, change)
+		}
+	}
+	return changes, expectDelim(dec, ']')
+}
+
+func decodeResourceChange(dec *json.Decoder) (ResourceChange, bool, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return ResourceChange{}, false, err
+	}
+	if tok == nil {
+		return ResourceChange{}, false, nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return ResourceChange{}, false, fmt.Errorf("invalid resource change: expected object")
+	}
+
+	change := ResourceChange{Actions: []string{}}
+	for dec.More() {
+		key, err := readKey(dec)
+		if err != nil {
+			return ResourceChange{}, false, err
+		}
+
+		switch key {
+		case "address":
+			err = dec.Decode(&change.Address)
+		case "type":
+			err = dec.Decode(&change.Type)
+		case "change":
+			err = decodeChange(dec, &change)
+		default:
+			err = skipValue(dec)
+		}
+		if err != nil {
+			return ResourceChange{}, false, fmt.Errorf("invalid resource change %q: %w", change.Address, err)
+		}
+	}
+	return change, true, expectDelim(dec, '}')
+}
+
+func decodeChange(dec *json.Decoder, change *ResourceChange) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return errors.New("invalid change: expected object")
+	}
+
+	for dec.More() {
+		key, err := readKey(dec)
+		if err != nil {
+			return err
+		}
+
+		switch key {
+		case "actions":
+			var actions []string
+			if err := dec.Decode(&actions); err != nil {
+				return err
+			}
+			change.Actions = append(change.Actions, actions...)
+		case "before":
+			if change.Before, err = decodeAttributes(dec); err != nil {
+				return err
+			}
+		case "after":
+			if change.After, err = decodeAttributes(dec); err != nil {
+				return err
+			}
+		default:
+			if err := skipValue(dec); err != nil {
+				return err
+			}
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+// decodeAttributes keeps only mappingAttributes. A null object decodes to a
+// nil map, while a present object always decodes to a non-nil map, so callers
+// can still tell that the resource had state on that side of the change.
+func decodeAttributes(dec *json.Decoder) (map[string]any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok == nil {
+		return nil, nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, errors.New("invalid attributes: expected object")
+	}
+
+	attrs := map[string]any{}
+	for dec.More() {
+		key, err := readKey(dec)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := mappingAttributes[key]; !ok {
+			if err := skipValue(dec); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return nil, err
+		}
+		attrs[key] = v
+	}
+	return attrs, expectDelim(dec, '}')
+}
+
+func readKey(dec *json.Decoder) (string, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := tok.(string)
+	if !ok {
+		return "", fmt.Errorf("invalid object key %v", tok)
+	}
+	return key, nil
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("unexpected token %v (want %q)", tok, want)
+	}
+	return nil
+}
+
+// skipValue consumes the next value without materializing it.
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		if delim, ok := tok.(json.Delim); ok {
+			switch delim {
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+		}
+
+		if depth == 0 {
+			return nil
+		}
+	}
+}
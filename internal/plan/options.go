@@ -0,0 +1,26 @@
+package plan
+
+type Option func(*options)
+
+type options struct {
+	maxBytes int64
+}
+
+// WithMaxBytes caps the size of the plan document. Zero or a negative value
+// disables the cap.
+func WithMaxBytes(maxBytes int64) Option {
+	return func(opts *options) {
+		opts.maxBytes = maxBytes
+	}
+}
+
+func newOptions(opts []Option) options {
+	cfg := options{maxBytes: maxPlanFileBytes}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(&cfg)
+	}
+	return cfg
+}
@@ -1,14 +1,18 @@
 package plan
 
 import (
+	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-
-	tfjson "github.com/hashicorp/terraform-json"
 )
 
 const maxPlanFileBytes = 50 << 20
 
+var zipMagic = []byte("PK\x03\x04")
+
 type ResourceChange struct {
 	Address string
 	Type    string
@@ -17,89 +21,92 @@ type ResourceChange struct {
 	After   map[string]any
 	Zone    string
 	Region  string
+	// Unknown lists attributes whose value could not be determined, for
+	// example when resources are read statically from HCL.
+	Unknown []string
 }
 
-func ParseFile(filePath string) ([]ResourceChange, error) {
+func ParseFile(filePath string, opts ...Option) ([]ResourceChange, error) {
+	cfg := newOptions(opts)
+
 	info, err := os.Stat(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("could not read plan file: %w", err)
 	}
-	if info.Size() > maxPlanFileBytes {
-		return nil, fmt.Errorf("could not read plan file: file too large (%d bytes > %d bytes)", info.Size(), maxPlanFileBytes)
+	if cfg.maxBytes > 0 && info.Size() > cfg.maxBytes {
+		return nil, fmt.Errorf("could not read plan file: file too large (%d bytes > %d bytes)", info.Size(), cfg.maxBytes)
 	}
 
-	b, err := os.ReadFile(filePath)
+	f, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("could not read plan file: %w", err)
 	}
-	return ParseBytes(b)
+	defer f.Close()
+
+	return ParseReader(bufio.NewReader(f), opts...)
 }
 
-func ParseBytes(data []byte) ([]ResourceChange, error) {
-	if len(data) > maxPlanFileBytes {
-		return nil, fmt.Errorf("could not decode terraform plan json: payload too large (%d bytes > %d bytes)", len(data), maxPlanFileBytes)
+func ParseBytes(data []byte, opts ...Option) ([]ResourceChange, error) {
+	cfg := newOptions(opts)
+
+	if cfg.maxBytes > 0 && int64(len(data)) > cfg.maxBytes {
+		return nil, fmt.Errorf("could not decode terraform plan json: payload too large (%d bytes > %d bytes)", len(data), cfg.maxBytes)
 	}
+	return ParseReader(bytes.NewReader(data), opts...)
+}
 
-	plan := new(tfjson.Plan)
-	if err := plan.UnmarshalJSON(data); err != nil {
-		return nil, fmt.Errorf("could not decode terraform plan json: %w", err)
+// ParseReader decodes a terraform plan json document as a stream, so memory
+// use depends on the number of resource changes rather than the document size.
+func ParseReader(r io.Reader, opts ...Option) ([]ResourceChange, error) {
+	cfg := newOptions(opts)
+
+	if cfg.maxBytes > 0 {
+		r = &limitedReader{r: r, remaining: cfg.maxBytes, max: cfg.maxBytes}
 	}
 
-	changes := make([]ResourceChange, 0, len(plan.ResourceChanges))
-	defaultZone := planVariableString(plan, "zone")
-	defaultRegion := planVariableString(plan, "region")
-
-	for _, rc := range plan.ResourceChanges {
-		if rc == nil {
-			continue
-		}
-
-		before := map[string]any{}
-		after := map[string]any{}
-		actions := []string{}
-
-		if rc.Change != nil {
-			before = anyToMap(rc.Change.Before)
-			after = anyToMap(rc.Change.After)
-			for _, action := range rc.Change.Actions {
-				actions = append(actions, string(action))
-			}
-		}
-
-		changes = append(changes, ResourceChange{
-			Address: rc.Address,
-			Type:    rc.Type,
-			Actions: actions,
-			Before:  before,
-			After:   after,
-			Zone:    defaultZone,
-			Region:  defaultRegion,
-		})
+	changes, err := decodePlan(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode terraform plan json: %w", err)
 	}
 	return changes, nil
 }
 
-func anyToMap(v any) map[string]any {
-	m, ok := v.(map[string]any)
-	if !ok || m == nil {
-		return map[string]any{}
-	}
-	return m
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	max       int64
 }
 
-func planVariableString(plan *tfjson.Plan, key string) string {
-	if plan == nil || plan.Variables == nil {
-		return ""
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, fmt.Errorf("payload too large (> %d bytes)", l.max)
+	}
+
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
 	}
 
-	v, ok := plan.Variables[key]
-	if !ok || v == nil || v.Value == nil {
-		return ""
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, fmt.Errorf("payload too large (> %d bytes)", l.max)
+	}
+	return n, err
+}
+
+// IsBinaryFile reports whether filePath is a binary plan saved with
+// `terraform plan -out`, which is a zip archive rather than json.
+func IsBinaryFile(filePath string) (bool, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return false, fmt.Errorf("could not read plan file: %w", err)
 	}
+	defer f.Close()
 
-	s, ok := v.Value.(string)
-	if !ok {
-		return ""
+	header := make([]byte, len(zipMagic))
+	n, err := io.ReadFull(f, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("could not read plan file: %w", err)
 	}
-	return s
+	return bytes.Equal(header[:n], zipMagic), nil
 }
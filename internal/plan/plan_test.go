@@ -3,6 +3,7 @@ package plan
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -101,4 +102,144 @@ func TestParseFile(t *testing.T) {
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "file too large")
 	})
+
+	t.Run("reads the resources of a state file", func(t *testing.T) {
+		t.Parallel()
+
+		changes, err := ParseFile(filepath.Join("testdata", "simple_state.json"))
+		require.NoError(t, err)
+		require.Len(t, changes, 3, "data sources are skipped")
+
+		assert.Equal(t, "scaleway_instance_server.web", changes[0].Address)
+		assert.Equal(t, []string{"no-op"}, changes[0].Actions)
+		assert.Equal(t, map[string]any{"type": "DEV1-S", "zone": "fr-par-1", "project_id": "0a3d8e5c-0000-4000-8000-000000000001"}, changes[0].After)
+		assert.Equal(t, changes[0].After, changes[0].Before)
+
+		assert.Equal(t, "module.storage.scaleway_block_volume.data[0]", changes[1].Address)
+		assert.Equal(t, 50.0, changes[1].After["size_in_gb"])
+
+		assert.Equal(t, "module.storage.module.db.scaleway_rdb_instance.main", changes[2].Address)
+		assert.Equal(t, "scaleway_rdb_instance", changes[2].Type)
+		assert.Equal(t, "DB-DEV-S", changes[2].After["node_type"])
+	})
+
+	t.Run("honours configured size cap", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "plan.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"resource_changes": [], "padding": "`+strings.Repeat("x", 256)+`"}`), 0o600))
+
+		_, err := ParseFile(path, WithMaxBytes(128))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "file too large")
+
+		changes, err := ParseFile(path, WithMaxBytes(0))
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+}
+
+func TestParseReader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("keeps only mapping attributes and skips unrelated sections", func(t *testing.T) {
+		t.Parallel()
+
+		data := `{
+			"format_version": "1.2",
+			"planned_values": {"root_module": {"resources": [{"address": "x", "values": {"a": [1, 2, {"b": null}]}}]}},
+			"resource_changes": [
+				{
+					"address": "scaleway_instance_server.web",
+					"type": "scaleway_instance_server",
+					"change": {
+						"actions": ["update"],
+						"before": {"type": "DEV1-S", "user_data": {"cloud-init": "..."}, "tags": ["a", "b"]},
+						"after": {"type": "DEV1-M", "zone": "fr-par-2", "root_volume": [{"size_in_gb": 20}]},
+						"after_unknown": {"id": true}
+					}
+				},
+				{
+					"address": "scaleway_instance_ip.public",
+					"type": "scaleway_instance_ip",
+					"change": {"actions": ["create"], "before": null, "after": {"tags": []}}
+				}
+			],
+			"variables": {"zone": {"value": "fr-par-1"}, "big": {"value": {"nested": [1, 2, 3]}}}
+		}`
+
+		changes, err := ParseReader(strings.NewReader(data))
+		require.NoError(t, err)
+		require.Len(t, changes, 2)
+
+		assert.Equal(t, []string{"update"}, changes[0].Actions)
+		assert.Equal(t, map[string]any{"type": "DEV1-S"}, changes[0].Before)
+		assert.Equal(t, map[string]any{"type": "DEV1-M", "zone": "fr-par-2"}, changes[0].After)
+		assert.Equal(t, "fr-par-1", changes[0].Zone)
+
+		assert.Nil(t, changes[1].Before)
+		assert.NotNil(t, changes[1].After)
+		assert.Empty(t, changes[1].After)
+	})
+
+	t.Run("rejects unsupported format version", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := ParseReader(strings.NewReader(`{"format_version": "2.0", "resource_changes": []}`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported plan format_version")
+	})
+
+	t.Run("returns error for truncated json", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := ParseReader(strings.NewReader(`{"resource_changes": [{"address": "x"`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "could not decode terraform plan json")
+	})
+
+	t.Run("enforces configured size cap on streams", func(t *testing.T) {
+		t.Parallel()
+
+		data := `{"resource_changes": [], "padding": "` + strings.Repeat("x", 256) + `"}`
+
+		_, err := ParseReader(strings.NewReader(data), WithMaxBytes(64))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "payload too large")
+
+		changes, err := ParseReader(strings.NewReader(data), WithMaxBytes(0))
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+}
+
+func TestIsBinaryFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	binaryPath := filepath.Join(dir, "tfplan")
+	require.NoError(t, os.WriteFile(binaryPath, []byte("PK\x03\x04rest-of-archive"), 0o600))
+
+	jsonPath := filepath.Join(dir, "plan.json")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`{"format_version": "1.2"}`), 0o600))
+
+	emptyPath := filepath.Join(dir, "empty")
+	require.NoError(t, os.WriteFile(emptyPath, nil, 0o600))
+
+	binary, err := IsBinaryFile(binaryPath)
+	require.NoError(t, err)
+	assert.True(t, binary)
+
+	binary, err = IsBinaryFile(jsonPath)
+	require.NoError(t, err)
+	assert.False(t, binary)
+
+	binary, err = IsBinaryFile(emptyPath)
+	require.NoError(t, err)
+	assert.False(t, binary)
+
+	_, err = IsBinaryFile(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
 }
@@ -0,0 +1,256 @@
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alesr/impact/internal/estimate"
+	"github.com/alesr/impact/internal/mapping"
+)
+
+// Limits are upper bounds on the monthly delta of a report or a slice of it.
+// Nil fields are not enforced.
+type Limits struct {
+	MaxKgCO2eDelta *float64 `yaml:"max_kgco2e_delta" json:"max_kgco2e_delta,omitempty"`
+	MaxM3Delta     *float64 `yaml:"max_m3_delta" json:"max_m3_delta,omitempty"`
+	MaxUnknownRows *int     `yaml:"max_unknown_rows" json:"max_unknown_rows,omitempty"`
+}
+
+// Policy holds report-wide limits, limits scoped to terraform modules
+// (matched on the module path prefix of each row address, nested modules
+// included) and to resource types, and free-form rules.
+type Policy struct {
+	Limits        `yaml:",inline"`
+	Modules       map[string]Limits `yaml:"modules" json:"modules,omitempty"`
+	ResourceTypes map[string]Limits `yaml:"resource_types" json:"resource_types,omitempty"`
+	Rules         []Rule            `yaml:"rules" json:"rules,omitempty"`
+}
+
+type Violation struct {
+	Scope  string  `json:"scope"`
+	Limit  string  `json:"limit"`
+	Actual float64 `json:"actual"`
+	Max    float64 `json:"max"`
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s %s exceeds limit %s", v.Scope, v.Limit, formatValue(v.Actual), formatValue(v.Max))
+}
+
+func (l Limits) IsZero() bool {
+	return l.MaxKgCO2eDelta == nil && l.MaxM3Delta == nil && l.MaxUnknownRows == nil
+}
+
+func (p Policy) IsZero() bool {
+	return p.Limits.IsZero() && len(p.Modules) == 0 && len(p.ResourceTypes) == 0 && len(p.Rules) == 0
+}
+
+func LoadFile(path string) (Policy, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Policy{}, fmt.Errorf("could not read policy file: %w", err)
+	}
+
+	var p Policy
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+		return Policy{}, fmt.Errorf("could not decode policy file %s: %w", path, err)
+	}
+
+	if err := p.Validate(); err != nil {
+		return Policy{}, fmt.Errorf("could not validate policy file %s: %w", path, err)
+	}
+	return p, nil
+}
+
+func (p Policy) Validate() error {
+	if err := p.Limits.validate(); err != nil {
+		return err
+	}
+	for name, limits := range p.Modules {
+		if !strings.HasPrefix(name, "module.") {
+			return fmt.Errorf("modules: key %q must be a module path such as module.network", name)
+		}
+		if err := limits.validate(); err != nil {
+			return fmt.Errorf("modules[%s]: %w", name, err)
+		}
+	}
+	for name, limits := range p.ResourceTypes {
+		if err := limits.validate(); err != nil {
+			return fmt.Errorf("resource_types[%s]: %w", name, err)
+		}
+	}
+
+	names := make(map[string]struct{}, len(p.Rules))
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if err := rule.compile(); err != nil {
+			return fmt.Errorf("rules[%d]: %w", i, err)
+		}
+		if _, dup := names[rule.Name]; dup {
+			return fmt.Errorf("rules[%d]: duplicate rule name %q", i, rule.Name)
+		}
+		names[rule.Name] = struct{}{}
+	}
+	return nil
+}
+
+func (l Limits) validate() error {
+	if l.MaxUnknownRows != nil && *l.MaxUnknownRows < 0 {
+		return errors.New("max_unknown_rows must not be negative")
+	}
+	return nil
+}
+
+// Evaluate checks the report against every limit and returns the violations
+// in a stable order: report totals first, then modules, then resource types.
+func Evaluate(p Policy, rep estimate.Report) []Violation {
+	var violations []Violation
+
+	violations = append(violations, p.Limits.check("total", sum{
+		kg:      rep.Totals.KgCO2eMonth,
+		m3:      rep.Totals.M3WaterMonth,
+		unknown: rep.Totals.UnknownRows,
+	})...)
+
+	for _, name := range sortedKeys(p.Modules) {
+		inModule := func(address string) bool {
+			module := ModulePath(address)
+			return module == name || strings.HasPrefix(module, name+".") || strings.HasPrefix(module, name+"[")
+		}
+		total := sumRows(rep, func(row estimate.Row) bool { return inModule(row.Address) }, inModule)
+		violations = append(violations, p.Modules[name].check("modules["+name+"]", total)...)
+	}
+
+	for _, name := range sortedKeys(p.ResourceTypes) {
+		total := sumRows(rep, func(row estimate.Row) bool { return row.Type == name }, func(address string) bool { return resourceType(address) == name })
+		violations = append(violations, p.ResourceTypes[name].check("resource_types["+name+"]", total)...)
+	}
+
+	return violations
+}
+
+// ModulePath returns the module part of a resource address, for example
+// module.app.module.db for module.app.module.db.scaleway_rdb_instance.main,
+// or an empty string for root module resources. Instance keys stay part of
+// the module, dots inside them included: module.net["eu.fr"].
+func ModulePath(address string) string {
+	parts := addressParts(address)
+	end := 0
+	for end+1 < len(parts) && parts[end] == "module" {
+		end += 2
+	}
+	return strings.Join(parts[:end], ".")
+}
+
+// addressParts splits a resource address on the dots that separate its
+// parts, leaving the dots of bracketed and quoted instance keys alone.
+func addressParts(address string) []string {
+	var (
+		parts   []string
+		start   int
+		depth   int
+		quoted  bool
+		escaped bool
+	)
+	for i := 0; i < len(address); i++ {
+		switch c := address[i]; {
+		case escaped:
+			escaped = false
+		case quoted && c == '\\':
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == '.' && depth == 0:
+			parts = append(parts, address[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, address[start:])
+}
+
+type sum struct {
+	kg      float64
+	m3      float64
+	unknown int
+}
+
+// resourceType returns the type part of a resource address, for example
+// scaleway_rdb_instance for module.app.scaleway_rdb_instance.main.
+func resourceType(address string) string {
+	parts := addressParts(address)
+	end := 0
+	for end+1 < len(parts) && parts[end] == "module" {
+		end += 2
+	}
+	return parts[end]
+}
+
+// sumRows sums the rows that match. Resources left unsupported with code
+// unknown_value whose address matches count as unknown rows, as in the
+// report totals.
+func sumRows(rep estimate.Report, match func(estimate.Row) bool, matchAddress func(string) bool) sum {
+	var total sum
+	for _, unsupported := range rep.Unsupported {
+		if unsupported.Code == string(mapping.ErrorCodeUnknownValue) && matchAddress(unsupported.Address) {
+			total.unknown++
+		}
+	}
+	for _, row := range rep.Rows {
+		if !match(row) {
+			continue
+		}
+		if row.KgCO2eKnown {
+			total.kg += row.KgCO2eMonth
+		}
+		if row.M3WaterKnown {
+			total.m3 += row.M3WaterMonth
+		}
+		if !row.KgCO2eKnown || !row.M3WaterKnown {
+			total.unknown++
+		}
+	}
+	return total
+}
+
+func (l Limits) check(scope string, total sum) []Violation {
+	var violations []Violation
+
+	if l.MaxKgCO2eDelta != nil && total.kg > *l.MaxKgCO2eDelta {
+		violations = append(violations, Violation{Scope: scope, Limit: "kgCO2e/month delta", Actual: total.kg, Max: *l.MaxKgCO2eDelta})
+	}
+	if l.MaxM3Delta != nil && total.m3 > *l.MaxM3Delta {
+		violations = append(violations, Violation{Scope: scope, Limit: "m3 water/month delta", Actual: total.m3, Max: *l.MaxM3Delta})
+	}
+	if l.MaxUnknownRows != nil && total.unknown > *l.MaxUnknownRows {
+		violations = append(violations, Violation{Scope: scope, Limit: "unknown rows", Actual: float64(total.unknown), Max: float64(*l.MaxUnknownRows)})
+	}
+	return violations
+}
+
+func sortedKeys(m map[string]Limits) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,175 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alesr/impact/internal/estimate"
+	"github.com/alesr/impact/internal/mapping"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func float64ptr(v float64) *float64 { return &v }
+
+func intptr(v int) *int { return &v }
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	rep := estimate.Report{
+		Rows: []estimate.Row{
+			{Address: "scaleway_instance_server.web", Type: "scaleway_instance_server", KgCO2eMonth: 4, KgCO2eKnown: true, M3WaterMonth: 0.1, M3WaterKnown: true},
+			{Address: "module.data.scaleway_rdb_instance.main", Type: "scaleway_rdb_instance", KgCO2eMonth: 6, KgCO2eKnown: true, M3WaterMonth: 0.2, M3WaterKnown: true},
+			{Address: "module.data.module.cache.scaleway_redis_cluster.main", Type: "scaleway_redis_cluster", KgCO2eMonth: 3, KgCO2eKnown: true, M3WaterKnown: false},
+		},
+		Totals: estimate.Totals{KgCO2eMonth: 13, KgCO2eKnown: true, M3WaterMonth: 0.3, UnknownRows: 1},
+	}
+
+	t.Run("module limits cover every instance of a module", func(t *testing.T) {
+		t.Parallel()
+
+		rep := estimate.Report{Rows: []estimate.Row{
+			{Address: `module.net["eu.fr"].scaleway_instance_server.x`, Type: "scaleway_instance_server", KgCO2eMonth: 2, KgCO2eKnown: true, M3WaterKnown: true},
+			{Address: `module.net["eu.nl"].scaleway_instance_server.x`, Type: "scaleway_instance_server", KgCO2eMonth: 3, KgCO2eKnown: true, M3WaterKnown: true},
+			{Address: `module.network.scaleway_instance_server.x`, Type: "scaleway_instance_server", KgCO2eMonth: 100, KgCO2eKnown: true, M3WaterKnown: true},
+		}}
+
+		p := Policy{Modules: map[string]Limits{
+			"module.net":          {MaxKgCO2eDelta: float64ptr(4)},
+			`module.net["eu.fr"]`: {MaxKgCO2eDelta: float64ptr(1)},
+		}}
+
+		violations := Evaluate(p, rep)
+		require.Len(t, violations, 2)
+		assert.Equal(t, 5.0, violations[0].Actual)
+		assert.Equal(t, `modules[module.net["eu.fr"]]`, violations[1].Scope)
+		assert.Equal(t, 2.0, violations[1].Actual)
+	})
+
+	t.Run("passes when within limits", func(t *testing.T) {
+		t.Parallel()
+
+		p := Policy{Limits: Limits{MaxKgCO2eDelta: float64ptr(20), MaxM3Delta: float64ptr(1), MaxUnknownRows: intptr(1)}}
+		assert.Empty(t, Evaluate(p, rep))
+	})
+
+	t.Run("reports total, module and resource type violations in order", func(t *testing.T) {
+		t.Parallel()
+
+		p := Policy{
+			Limits: Limits{MaxKgCO2eDelta: float64ptr(10), MaxUnknownRows: intptr(0)},
+			Modules: map[string]Limits{
+				"module.data":       {MaxKgCO2eDelta: float64ptr(8)},
+				"module.data.cache": {MaxKgCO2eDelta: float64ptr(1)},
+			},
+			ResourceTypes: map[string]Limits{
+				"scaleway_instance_server": {MaxKgCO2eDelta: float64ptr(5)},
+				"scaleway_rdb_instance":    {MaxM3Delta: float64ptr(0.1)},
+			},
+		}
+
+		violations := Evaluate(p, rep)
+		require.Len(t, violations, 4)
+
+		assert.Equal(t, "total", violations[0].Scope)
+		assert.Equal(t, "kgCO2e/month delta", violations[0].Limit)
+		assert.Equal(t, 13.0, violations[0].Actual)
+
+		assert.Equal(t, "total", violations[1].Scope)
+		assert.Equal(t, "unknown rows", violations[1].Limit)
+
+		assert.Equal(t, "modules[module.data]", violations[2].Scope)
+		assert.Equal(t, 9.0, violations[2].Actual)
+
+		assert.Equal(t, "resource_types[scaleway_rdb_instance]", violations[3].Scope)
+		assert.Equal(t, "resource_types[scaleway_rdb_instance]: m3 water/month delta 0.2 exceeds limit 0.1", violations[3].String())
+	})
+}
+
+func TestEvaluateUnknownValues(t *testing.T) {
+	t.Parallel()
+
+	rep := estimate.Report{
+		Unsupported: []estimate.UnsupportedResource{
+			{Address: "module.app.scaleway_instance_server.web", Code: string(mapping.ErrorCodeUnknownValue)},
+			{Address: "scaleway_iam_policy.ci", Code: string(mapping.ErrorCodeNotImplemented)},
+		},
+		Totals: estimate.Totals{KgCO2eKnown: true, M3WaterKnown: true, UnknownRows: 1},
+	}
+	p := Policy{
+		Limits:        Limits{MaxUnknownRows: intptr(0)},
+		Modules:       map[string]Limits{"module.app": {MaxUnknownRows: intptr(0)}},
+		ResourceTypes: map[string]Limits{"scaleway_instance_server": {MaxUnknownRows: intptr(0)}, "scaleway_iam_policy": {MaxUnknownRows: intptr(0)}},
+	}
+
+	violations := Evaluate(p, rep)
+	require.Len(t, violations, 3)
+	assert.Equal(t, "total", violations[0].Scope)
+	assert.Equal(t, "modules[module.app]", violations[1].Scope)
+	assert.Equal(t, "resource_types[scaleway_instance_server]", violations[2].Scope)
+}
+
+func TestModulePath(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "", ModulePath("scaleway_lb.edge"))
+	assert.Equal(t, "module.app", ModulePath("module.app.scaleway_lb.edge"))
+	assert.Equal(t, "module.app.module.db", ModulePath(`module.app.module.db.scaleway_rdb_instance.main["a"]`))
+	assert.Equal(t, `module.net["eu.fr"]`, ModulePath(`module.net["eu.fr"].scaleway_instance_server.x`))
+	assert.Equal(t, `module.net["a\"].b"].module.db[0]`, ModulePath(`module.net["a\"].b"].module.db[0].scaleway_rdb_instance.main["x.y"]`))
+	assert.Equal(t, "", ModulePath(`scaleway_instance_server.x["module.y"]`))
+}
+
+func TestLoadFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("loads limits", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "policy.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(`
+max_kgco2e_delta: 50
+max_unknown_rows: 0
+modules:
+  module.data:
+    max_m3_delta: 0.5
+resource_types:
+  scaleway_k8s_pool:
+    max_kgco2e_delta: 20
+`), 0o600))
+
+		p, err := LoadFile(path)
+		require.NoError(t, err)
+		require.NotNil(t, p.MaxKgCO2eDelta)
+		assert.Equal(t, 50.0, *p.MaxKgCO2eDelta)
+		require.NotNil(t, p.MaxUnknownRows)
+		assert.Equal(t, 0, *p.MaxUnknownRows)
+		assert.Nil(t, p.MaxM3Delta)
+		require.NotNil(t, p.Modules["module.data"].MaxM3Delta)
+		require.NotNil(t, p.ResourceTypes["scaleway_k8s_pool"].MaxKgCO2eDelta)
+	})
+
+	t.Run("rejects unknown fields", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "policy.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("max_kg: 1\n"), 0o600))
+
+		_, err := LoadFile(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "could not decode policy file")
+	})
+
+	t.Run("rejects invalid module keys", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "policy.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("modules:\n  data:\n    max_m3_delta: 1\n"), 0o600))
+
+		_, err := LoadFile(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must be a module path")
+	})
+}
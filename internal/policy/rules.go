@@ -0,0 +1,220 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+
+	"github.com/alesr/impact/internal/estimate"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityNote    = "note"
+)
+
+const (
+	TargetRow         = "row"
+	TargetUnsupported = "unsupported"
+	TargetTotals      = "totals"
+)
+
+// Rule is an expr (https://expr-lang.org) condition evaluated against every
+// row, every unsupported resource or the report totals, depending on Target.
+// A finding with Message is recorded each time the condition is true.
+type Rule struct {
+	Name     string `yaml:"name" json:"name"`
+	Severity string `yaml:"severity" json:"severity"`
+	Target   string `yaml:"target" json:"target"`
+	When     string `yaml:"when" json:"when"`
+	Message  string `yaml:"message" json:"message"`
+
+	program *vm.Program
+}
+
+type rowEnv struct {
+	Source       string         `expr:"source"`
+	Address      string         `expr:"address"`
+	Module       string         `expr:"module"`
+	Type         string         `expr:"type"`
+	Action       string         `expr:"action"`
+	SKU          string         `expr:"sku"`
+	KgCO2eMonth  float64        `expr:"kgco2e_month"`
+	KgCO2eKnown  bool           `expr:"kgco2e_known"`
+	M3WaterMonth float64        `expr:"m3_water_month"`
+	M3WaterKnown bool           `expr:"m3_water_known"`
+	Attributes   map[string]any `expr:"attributes"`
+}
+
+type unsupportedEnv struct {
+	Source  string `expr:"source"`
+	Address string `expr:"address"`
+	Module  string `expr:"module"`
+	Code    string `expr:"code"`
+	Reason  string `expr:"reason"`
+}
+
+type totalsEnv struct {
+	KgCO2eMonth  float64 `expr:"kgco2e_month"`
+	KgCO2eKnown  bool    `expr:"kgco2e_known"`
+	M3WaterMonth float64 `expr:"m3_water_month"`
+	M3WaterKnown bool    `expr:"m3_water_known"`
+	UnknownRows  int     `expr:"unknown_rows"`
+	Rows         int     `expr:"rows"`
+	Unsupported  int     `expr:"unsupported"`
+}
+
+// compile checks the rule and prepares its program. Severity defaults to
+// error and target to row.
+func (r *Rule) compile() error {
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	if r.When == "" {
+		return errors.New("when is required")
+	}
+	if r.Message == "" {
+		return errors.New("message is required")
+	}
+
+	switch r.Severity {
+	case "":
+		r.Severity = SeverityError
+	case SeverityError, SeverityWarning, SeverityNote:
+	default:
+		return fmt.Errorf("severity %q is not one of error, warning or note", r.Severity)
+	}
+
+	var env any
+	switch r.Target {
+	case "", TargetRow:
+		r.Target = TargetRow
+		env = rowEnv{}
+	case TargetUnsupported:
+		env = unsupportedEnv{}
+	case TargetTotals:
+		env = totalsEnv{}
+	default:
+		return fmt.Errorf("target %q is not one of row, unsupported or totals", r.Target)
+	}
+
+	program, err := expr.Compile(r.When, expr.Env(env), expr.AsBool())
+	if err != nil {
+		return fmt.Errorf("could not compile when: %w", err)
+	}
+	r.program = program
+	return nil
+}
+
+// EvaluateRules runs every rule against the report and returns the findings
+// in rule order. Rules must come from LoadFile or have been validated; the
+// error only reports rules that do not compile.
+func EvaluateRules(rules []Rule, rep estimate.Report) ([]estimate.Finding, error) {
+	var findings []estimate.Finding
+
+	for i := range rules {
+		rule := &rules[i]
+		if rule.program == nil {
+			if err := rule.compile(); err != nil {
+				return nil, fmt.Errorf("could not compile rule %q: %w", rule.Name, err)
+			}
+		}
+
+		switch rule.Target {
+		case TargetRow:
+			for _, row := range rep.Rows {
+				env := rowEnv{
+					Source:       row.Source,
+					Address:      row.Address,
+					Module:       ModulePath(row.Address),
+					Type:         row.Type,
+					Action:       row.Action,
+					SKU:          row.SKU,
+					KgCO2eMonth:  row.KgCO2eMonth,
+					KgCO2eKnown:  row.KgCO2eKnown,
+					M3WaterMonth: row.M3WaterMonth,
+					M3WaterKnown: row.M3WaterKnown,
+					Attributes:   row.Attributes,
+				}
+				if f, ok := rule.evaluate(env, row.Source, row.Address); ok {
+					findings = append(findings, f)
+				}
+			}
+
+		case TargetUnsupported:
+			for _, unsupported := range rep.Unsupported {
+				env := unsupportedEnv{
+					Source:  unsupported.Source,
+					Address: unsupported.Address,
+					Module:  ModulePath(unsupported.Address),
+					Code:    unsupported.Code,
+					Reason:  unsupported.Reason,
+				}
+				if f, ok := rule.evaluate(env, unsupported.Source, unsupported.Address); ok {
+					findings = append(findings, f)
+				}
+			}
+
+		case TargetTotals:
+			env := totalsEnv{
+				KgCO2eMonth:  rep.Totals.KgCO2eMonth,
+				KgCO2eKnown:  rep.Totals.KgCO2eKnown,
+				M3WaterMonth: rep.Totals.M3WaterMonth,
+				M3WaterKnown: rep.Totals.M3WaterKnown,
+				UnknownRows:  rep.Totals.UnknownRows,
+				Rows:         len(rep.Rows),
+				Unsupported:  len(rep.Unsupported),
+			}
+			if f, ok := rule.evaluate(env, "", ""); ok {
+				findings = append(findings, f)
+			}
+		}
+	}
+
+	return findings, nil
+}
+
+// evaluate runs the rule against one subject and returns its finding, if
+// any. A rule that fails at runtime, for example on an attribute the resource
+// does not have, yields a warning for that subject instead of stopping the
+// other rules and subjects.
+func (r *Rule) evaluate(env any, source, address string) (estimate.Finding, bool) {
+	out, err := expr.Run(r.program, env)
+	if err != nil {
+		msg, _, _ := strings.Cut(err.Error(), "\n")
+		f := r.finding(source, address)
+		f.Severity = SeverityWarning
+		f.Message = "could not evaluate rule: " + msg
+		return f, true
+	}
+	matched, _ := out.(bool)
+	if !matched {
+		return estimate.Finding{}, false
+	}
+	return r.finding(source, address), true
+}
+
+func (r *Rule) finding(source, address string) estimate.Finding {
+	return estimate.Finding{
+		Rule:     r.Name,
+		Severity: r.Severity,
+		Message:  r.Message,
+		Source:   source,
+		Address:  address,
+	}
+}
+
+// ErrorCount returns the number of findings with error severity.
+func ErrorCount(findings []estimate.Finding) int {
+	var n int
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			n++
+		}
+	}
+	return n
+}
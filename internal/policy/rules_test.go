@@ -0,0 +1,135 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alesr/impact/internal/estimate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateRules(t *testing.T) {
+	t.Parallel()
+
+	rep := estimate.Report{
+		Rows: []estimate.Row{
+			{Source: "dev", Address: "scaleway_instance_server.gpu", Type: "scaleway_instance_server", SKU: "/compute/gpu_3070_s/run_par2", Attributes: map[string]any{"type": "GPU-3070-S"}},
+			{Source: "prod", Address: "scaleway_instance_server.gpu", Type: "scaleway_instance_server", Attributes: map[string]any{"type": "GPU-3070-S"}},
+			{Source: "dev", Address: "module.cache.scaleway_redis_cluster.main", Type: "scaleway_redis_cluster", Attributes: map[string]any{"cluster_size": 5.0}},
+		},
+		Unsupported: []estimate.UnsupportedResource{{Address: "scaleway_x.y", Code: "unsupported_type", Reason: "not implemented"}},
+		Totals:      estimate.Totals{KgCO2eMonth: 12, KgCO2eKnown: true},
+	}
+
+	p := Policy{Rules: []Rule{
+		{Name: "no-gpu-in-dev", When: `source == "dev" && attributes.type startsWith "GPU"`, Message: "GPU instances are not allowed in dev"},
+		{Name: "redis-size", Severity: SeverityWarning, When: `type == "scaleway_redis_cluster" && (attributes.cluster_size ?? 1) > 3`, Message: "needs justification"},
+		{Name: "no-unsupported", Target: TargetUnsupported, When: "true", Message: "resource is not supported"},
+		{Name: "big-change", Severity: SeverityNote, Target: TargetTotals, When: "kgco2e_month > 10 && unsupported > 0", Message: "large change"},
+	}}
+	require.NoError(t, p.Validate())
+
+	findings, err := EvaluateRules(p.Rules, rep)
+	require.NoError(t, err)
+
+	assert.Equal(t, []estimate.Finding{
+		{Rule: "no-gpu-in-dev", Severity: SeverityError, Message: "GPU instances are not allowed in dev", Source: "dev", Address: "scaleway_instance_server.gpu"},
+		{Rule: "redis-size", Severity: SeverityWarning, Message: "needs justification", Source: "dev", Address: "module.cache.scaleway_redis_cluster.main"},
+		{Rule: "no-unsupported", Severity: SeverityError, Message: "resource is not supported", Address: "scaleway_x.y"},
+		{Rule: "big-change", Severity: SeverityNote, Message: "large change"},
+	}, findings)
+	assert.Equal(t, 2, ErrorCount(findings))
+}
+
+func TestEvaluateRulesRuntimeError(t *testing.T) {
+	t.Parallel()
+
+	rules := []Rule{
+		{Name: "size", When: "attributes.cluster_size > 3", Message: "too big"},
+		{Name: "all", Severity: SeverityNote, When: "true", Message: "seen"},
+	}
+	rep := estimate.Report{Rows: []estimate.Row{
+		{Address: "scaleway_lb.edge", Attributes: map[string]any{"type": "LB-S"}},
+		{Address: "scaleway_redis_cluster.main", Attributes: map[string]any{"cluster_size": 5.0}},
+	}}
+
+	findings, err := EvaluateRules(rules, rep)
+	require.NoError(t, err)
+	require.Len(t, findings, 4)
+
+	assert.Equal(t, "size", findings[0].Rule)
+	assert.Equal(t, SeverityWarning, findings[0].Severity)
+	assert.Equal(t, "scaleway_lb.edge", findings[0].Address)
+	assert.Contains(t, findings[0].Message, "could not evaluate rule: ")
+	assert.NotContains(t, findings[0].Message, "\n")
+
+	assert.Equal(t, estimate.Finding{Rule: "size", Severity: SeverityError, Message: "too big", Address: "scaleway_redis_cluster.main"}, findings[1])
+	assert.Equal(t, "all", findings[2].Rule, "later rules still run")
+	assert.Equal(t, 1, ErrorCount(findings))
+}
+
+func TestLoadFileRules(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "valid rule",
+			content: "rules:\n  - name: gpu\n    severity: warning\n    when: sku contains \"gpu\"\n    message: gpu\n",
+		},
+		{
+			name:    "invalid expression",
+			content: "rules:\n  - name: gpu\n    when: sku ==\n    message: gpu\n",
+			wantErr: "could not compile when",
+		},
+		{
+			name:    "unknown field for target",
+			content: "rules:\n  - name: gpu\n    target: totals\n    when: sku == \"x\"\n    message: gpu\n",
+			wantErr: "could not compile when",
+		},
+		{
+			name:    "non boolean expression",
+			content: "rules:\n  - name: gpu\n    when: kgco2e_month\n    message: gpu\n",
+			wantErr: "could not compile when",
+		},
+		{
+			name:    "invalid severity",
+			content: "rules:\n  - name: gpu\n    severity: fatal\n    when: \"true\"\n    message: gpu\n",
+			wantErr: `severity "fatal"`,
+		},
+		{
+			name:    "duplicate names",
+			content: "rules:\n  - name: gpu\n    when: \"true\"\n    message: a\n  - name: gpu\n    when: \"true\"\n    message: b\n",
+			wantErr: `duplicate rule name "gpu"`,
+		},
+		{
+			name:    "missing message",
+			content: "rules:\n  - name: gpu\n    when: \"true\"\n",
+			wantErr: "message is required",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "policy.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+
+			p, err := LoadFile(path)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, p.Rules, 1)
+			assert.Equal(t, TargetRow, p.Rules[0].Target)
+		})
+	}
+}
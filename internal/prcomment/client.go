@@ -0,0 +1,164 @@
+package prcomment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Marker is a hidden html comment that identifies the sticky comment, so
+// later runs update it instead of posting a new one.
+const Marker = "<!-- impact:plan-comment -->"
+
+const (
+	commentsPerPage = 100
+	maxCommentPages = 50
+)
+
+type Client struct {
+	target     Target
+	httpClient *http.Client
+	userAgent  string
+}
+
+type comment struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+}
+
+func NewClient(target Target, opts ...Option) *Client {
+	var cfg options
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(&cfg)
+	}
+
+	httpClient := cfg.httpClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: cfg.timeout}
+	}
+
+	return &Client{target: target, httpClient: httpClient, userAgent: cfg.userAgent}
+}
+
+// Upsert creates the sticky comment with body, or replaces the body of the
+// existing one. The marker is prepended to body.
+func (c *Client) Upsert(ctx context.Context, body string) error {
+	body = Marker + "\n" + body
+
+	existing, err := c.findComment(ctx)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		if err := c.do(ctx, http.MethodPost, c.commentsURL(), body, nil); err != nil {
+			return fmt.Errorf("could not create comment: %w", err)
+		}
+		return nil
+	}
+
+	method := http.MethodPatch
+	if c.target.Provider == ProviderGitLab {
+		method = http.MethodPut
+	}
+	if err := c.do(ctx, method, c.commentURL(existing.ID), body, nil); err != nil {
+		return fmt.Errorf("could not update comment %d: %w", existing.ID, err)
+	}
+	return nil
+}
+
+func (c *Client) findComment(ctx context.Context) (*comment, error) {
+	for page := 1; page <= maxCommentPages; page++ {
+		endpoint := fmt.Sprintf("%s?per_page=%d&page=%d", c.commentsURL(), commentsPerPage, page)
+
+		var comments []comment
+		if err := c.do(ctx, http.MethodGet, endpoint, "", &comments); err != nil {
+			return nil, fmt.Errorf("could not list comments: %w", err)
+		}
+
+		for _, cm := range comments {
+			if strings.Contains(cm.Body, Marker) {
+				return &cm, nil
+			}
+		}
+
+		if len(comments) < commentsPerPage {
+			break
+		}
+	}
+	return nil, nil
+}
+
+func (c *Client) commentsURL() string {
+	if c.target.Provider == ProviderGitLab {
+		return fmt.Sprintf("%s/projects/%s/merge_requests/%d/notes", c.target.BaseURL, url.PathEscape(c.target.Repo), c.target.Number)
+	}
+	return fmt.Sprintf("%s/repos/%s/issues/%d/comments", c.target.BaseURL, c.target.Repo, c.target.Number)
+}
+
+func (c *Client) commentURL(id int64) string {
+	if c.target.Provider == ProviderGitLab {
+		return fmt.Sprintf("%s/%d", c.commentsURL(), id)
+	}
+	return fmt.Sprintf("%s/repos/%s/issues/comments/%d", c.target.BaseURL, c.target.Repo, id)
+}
+
+func (c *Client) do(ctx context.Context, method, endpoint, body string, out any) error {
+	var reqBody io.Reader
+	if body != "" {
+		b, err := json.Marshal(map[string]string{"body": body})
+		if err != nil {
+			return fmt.Errorf("could not encode request: %w", err)
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
+	if err != nil {
+		return fmt.Errorf("could not build request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	switch c.target.Provider {
+	case ProviderGitLab:
+		req.Header.Set("PRIVATE-TOKEN", c.target.Token)
+	default:
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("Authorization", "Bearer "+c.target.Token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: unexpected status %d: %s", method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("could not decode response: %w", err)
+	}
+	return nil
+}
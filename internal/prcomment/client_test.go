@@ -0,0 +1,136 @@
+package prcomment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedRequest struct {
+	method string
+	path   string
+	body   string
+	header http.Header
+}
+
+func stubServer(t *testing.T, list func(page string) string) (*httptest.Server, func() []recordedRequest) {
+	t.Helper()
+
+	var (
+		mu       sync.Mutex
+		requests []recordedRequest
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Body string `json:"body"`
+		}
+		if r.Body != nil {
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+		}
+
+		mu.Lock()
+		requests = append(requests, recordedRequest{method: r.Method, path: r.URL.EscapedPath(), body: payload.Body, header: r.Header.Clone()})
+		mu.Unlock()
+
+		if r.Method == http.MethodGet {
+			fmt.Fprint(w, list(r.URL.Query().Get("page")))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id": 1}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, func() []recordedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]recordedRequest(nil), requests...)
+	}
+}
+
+func TestClientUpsert(t *testing.T) {
+	t.Parallel()
+
+	t.Run("creates a github comment when none has the marker", func(t *testing.T) {
+		t.Parallel()
+
+		srv, requests := stubServer(t, func(string) string { return `[{"id": 5, "body": "lgtm"}]` })
+
+		client := NewClient(Target{Provider: ProviderGitHub, BaseURL: srv.URL, Repo: "acme/infra", Number: 42, Token: "tok"})
+		require.NoError(t, client.Upsert(context.Background(), "report"))
+
+		got := requests()
+		require.Len(t, got, 2)
+		assert.Equal(t, http.MethodGet, got[0].method)
+		assert.Equal(t, "/repos/acme/infra/issues/42/comments", got[0].path)
+		assert.Equal(t, "Bearer tok", got[0].header.Get("Authorization"))
+		assert.Equal(t, http.MethodPost, got[1].method)
+		assert.Equal(t, Marker+"\nreport", got[1].body)
+	})
+
+	t.Run("updates the github comment found on a later page", func(t *testing.T) {
+		t.Parallel()
+
+		srv, requests := stubServer(t, func(page string) string {
+			if page == "1" {
+				comments := make([]string, commentsPerPage)
+				for i := range comments {
+					comments[i] = fmt.Sprintf(`{"id": %d, "body": "other"}`, i+1)
+				}
+				return "[" + strings.Join(comments, ",") + "]"
+			}
+			return `[{"id": 777, "body": "` + Marker + `\nold"}]`
+		})
+
+		client := NewClient(Target{Provider: ProviderGitHub, BaseURL: srv.URL, Repo: "acme/infra", Number: 42, Token: "tok"})
+		require.NoError(t, client.Upsert(context.Background(), "new"))
+
+		got := requests()
+		require.Len(t, got, 3)
+		assert.Equal(t, http.MethodPatch, got[2].method)
+		assert.Equal(t, "/repos/acme/infra/issues/comments/777", got[2].path)
+		assert.Equal(t, Marker+"\nnew", got[2].body)
+	})
+
+	t.Run("updates the gitlab note", func(t *testing.T) {
+		t.Parallel()
+
+		srv, requests := stubServer(t, func(string) string {
+			return `[{"id": 9, "body": "` + Marker + `"}]`
+		})
+
+		client := NewClient(Target{Provider: ProviderGitLab, BaseURL: srv.URL, Repo: "group/infra", Number: 3, Token: "tok"})
+		require.NoError(t, client.Upsert(context.Background(), "new"))
+
+		got := requests()
+		require.Len(t, got, 2)
+		assert.Equal(t, "/projects/group%2Finfra/merge_requests/3/notes", got[0].path)
+		assert.Equal(t, "tok", got[0].header.Get("PRIVATE-TOKEN"))
+		assert.Equal(t, http.MethodPut, got[1].method)
+		assert.Equal(t, "/projects/group%2Finfra/merge_requests/3/notes/9", got[1].path)
+	})
+
+	t.Run("returns api errors", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, `{"message": "Bad credentials"}`, http.StatusUnauthorized)
+		}))
+		t.Cleanup(srv.Close)
+
+		client := NewClient(Target{Provider: ProviderGitHub, BaseURL: srv.URL, Repo: "acme/infra", Number: 1, Token: "bad"})
+		err := client.Upsert(context.Background(), "report")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "could not list comments")
+		assert.Contains(t, err.Error(), "unexpected status 401")
+	})
+}
@@ -0,0 +1,32 @@
+package prcomment
+
+import (
+	"net/http"
+	"time"
+)
+
+type Option func(*options)
+
+type options struct {
+	userAgent  string
+	timeout    time.Duration
+	httpClient *http.Client
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(opts *options) {
+		opts.userAgent = userAgent
+	}
+}
+
+func WithTimeout(timeout time.Duration) Option {
+	return func(opts *options) {
+		opts.timeout = timeout
+	}
+}
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(opts *options) {
+		opts.httpClient = httpClient
+	}
+}
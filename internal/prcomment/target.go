@@ -0,0 +1,187 @@
+package prcomment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/alesr/impact/internal/pkg/netx"
+)
+
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+const (
+	defaultGitHubAPIURL = "https://api.github.com"
+	defaultGitLabAPIURL = "https://gitlab.com/api/v4"
+)
+
+// Target identifies the pull request (GitHub) or merge request (GitLab) to
+// comment on. Repo is owner/name on GitHub and the project id or path on
+// GitLab; Number is the PR number or the MR iid.
+type Target struct {
+	Provider string
+	BaseURL  string
+	Repo     string
+	Number   int
+	Token    string
+}
+
+// TargetFromEnv reads the target from the variables set by GitHub Actions or
+// GitLab CI. A non-empty baseURL takes precedence over the CI provided API
+// URL.
+func TargetFromEnv(provider, baseURL string, getenv func(string) string) (Target, error) {
+	var (
+		target Target
+		err    error
+	)
+
+	switch strings.ToLower(strings.TrimSpace(provider)) {
+	case ProviderGitHub:
+		target, err = githubTargetFromEnv(getenv)
+	case ProviderGitLab:
+		target, err = gitlabTargetFromEnv(getenv)
+	default:
+		return Target{}, fmt.Errorf("could not resolve comment target: unknown provider %q (use github or gitlab)", provider)
+	}
+	if err != nil {
+		return Target{}, fmt.Errorf("could not resolve %s comment target: %w", provider, err)
+	}
+
+	if baseURL != "" {
+		target.BaseURL = baseURL
+	}
+	target.BaseURL = strings.TrimRight(target.BaseURL, "/")
+
+	if err := validateBaseURL(target.BaseURL); err != nil {
+		return Target{}, err
+	}
+	return target, nil
+}
+
+func githubTargetFromEnv(getenv func(string) string) (Target, error) {
+	target := Target{
+		Provider: ProviderGitHub,
+		BaseURL:  valueOr(getenv("GITHUB_API_URL"), defaultGitHubAPIURL),
+		Repo:     getenv("GITHUB_REPOSITORY"),
+		Token:    getenv("GITHUB_TOKEN"),
+	}
+	if target.Repo == "" {
+		return Target{}, errors.New("GITHUB_REPOSITORY is not set")
+	}
+	if target.Token == "" {
+		return Target{}, errors.New("GITHUB_TOKEN is not set")
+	}
+
+	number, err := githubPRNumber(getenv)
+	if err != nil {
+		return Target{}, err
+	}
+	target.Number = number
+	return target, nil
+}
+
+// githubPRNumber prefers IMPACT_PR_NUMBER, then refs/pull/<n>/merge from
+// GITHUB_REF, then the pull_request number in the event payload.
+func githubPRNumber(getenv func(string) string) (int, error) {
+	if raw := getenv("IMPACT_PR_NUMBER"); raw != "" {
+		return parseNumber("IMPACT_PR_NUMBER", raw)
+	}
+
+	if ref := getenv("GITHUB_REF"); strings.HasPrefix(ref, "refs/pull/") {
+		raw, _, _ := strings.Cut(strings.TrimPrefix(ref, "refs/pull/"), "/")
+		return parseNumber("GITHUB_REF", raw)
+	}
+
+	if path := getenv("GITHUB_EVENT_PATH"); path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return 0, fmt.Errorf("could not read GITHUB_EVENT_PATH: %w", err)
+		}
+
+		var event struct {
+			Number      int `json:"number"`
+			PullRequest struct {
+				Number int `json:"number"`
+			} `json:"pull_request"`
+		}
+		if err := json.Unmarshal(b, &event); err != nil {
+			return 0, fmt.Errorf("could not decode GITHUB_EVENT_PATH: %w", err)
+		}
+		if event.PullRequest.Number > 0 {
+			return event.PullRequest.Number, nil
+		}
+		if event.Number > 0 {
+			return event.Number, nil
+		}
+	}
+
+	return 0, errors.New("no pull request number found (set IMPACT_PR_NUMBER or run on a pull_request event)")
+}
+
+func gitlabTargetFromEnv(getenv func(string) string) (Target, error) {
+	target := Target{
+		Provider: ProviderGitLab,
+		BaseURL:  valueOr(getenv("CI_API_V4_URL"), defaultGitLabAPIURL),
+		Repo:     valueOr(getenv("CI_PROJECT_ID"), getenv("CI_PROJECT_PATH")),
+		Token:    getenv("GITLAB_TOKEN"),
+	}
+	if target.Repo == "" {
+		return Target{}, errors.New("CI_PROJECT_ID is not set")
+	}
+	if target.Token == "" {
+		return Target{}, errors.New("GITLAB_TOKEN is not set")
+	}
+
+	raw := valueOr(getenv("IMPACT_PR_NUMBER"), getenv("CI_MERGE_REQUEST_IID"))
+	if raw == "" {
+		return Target{}, errors.New("CI_MERGE_REQUEST_IID is not set (run in a merge request pipeline or set IMPACT_PR_NUMBER)")
+	}
+
+	number, err := parseNumber("CI_MERGE_REQUEST_IID", raw)
+	if err != nil {
+		return Target{}, err
+	}
+	target.Number = number
+	return target, nil
+}
+
+// validateBaseURL requires https, except for loopback hosts so a local stub
+// can be used without sending the token over plain http elsewhere.
+func validateBaseURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("could not validate comment API URL %q: must be a valid absolute URL", raw)
+	}
+
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		if netx.IsLoopback(u.Hostname()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("could not validate comment API URL %q: https scheme is required", raw)
+}
+
+func parseNumber(name, raw string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%s: %q is not a valid pull request number", name, raw)
+	}
+	return n, nil
+}
+
+func valueOr(v, fallback string) string {
+	if v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,95 @@
+package prcomment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func envFunc(env map[string]string) func(string) string {
+	return func(key string) string { return env[key] }
+}
+
+func TestTargetFromEnv(t *testing.T) {
+	t.Parallel()
+
+	eventPath := filepath.Join(t.TempDir(), "event.json")
+	require.NoError(t, os.WriteFile(eventPath, []byte(`{"pull_request": {"number": 17}}`), 0o600))
+
+	testCases := []struct {
+		name     string
+		provider string
+		baseURL  string
+		env      map[string]string
+		want     Target
+		wantErr  string
+	}{
+		{
+			name:     "github from ref",
+			provider: "github",
+			env:      map[string]string{"GITHUB_REPOSITORY": "acme/infra", "GITHUB_TOKEN": "tok", "GITHUB_REF": "refs/pull/42/merge"},
+			want:     Target{Provider: ProviderGitHub, BaseURL: defaultGitHubAPIURL, Repo: "acme/infra", Number: 42, Token: "tok"},
+		},
+		{
+			name:     "github from event payload with enterprise api url",
+			provider: "GitHub",
+			env:      map[string]string{"GITHUB_REPOSITORY": "acme/infra", "GITHUB_TOKEN": "tok", "GITHUB_EVENT_PATH": eventPath, "GITHUB_API_URL": "https://ghe.example.com/api/v3/"},
+			want:     Target{Provider: ProviderGitHub, BaseURL: "https://ghe.example.com/api/v3", Repo: "acme/infra", Number: 17, Token: "tok"},
+		},
+		{
+			name:     "gitlab with base url override",
+			provider: "gitlab",
+			baseURL:  "http://127.0.0.1:8080",
+			env:      map[string]string{"CI_PROJECT_ID": "12", "GITLAB_TOKEN": "tok", "CI_MERGE_REQUEST_IID": "3"},
+			want:     Target{Provider: ProviderGitLab, BaseURL: "http://127.0.0.1:8080", Repo: "12", Number: 3, Token: "tok"},
+		},
+		{
+			name:     "missing token",
+			provider: "github",
+			env:      map[string]string{"GITHUB_REPOSITORY": "acme/infra", "GITHUB_REF": "refs/pull/1/merge"},
+			wantErr:  "GITHUB_TOKEN is not set",
+		},
+		{
+			name:     "not a pull request",
+			provider: "github",
+			env:      map[string]string{"GITHUB_REPOSITORY": "acme/infra", "GITHUB_TOKEN": "tok", "GITHUB_REF": "refs/heads/main"},
+			wantErr:  "no pull request number found",
+		},
+		{
+			name:     "missing merge request iid",
+			provider: "gitlab",
+			env:      map[string]string{"CI_PROJECT_ID": "12", "GITLAB_TOKEN": "tok"},
+			wantErr:  "CI_MERGE_REQUEST_IID is not set",
+		},
+		{
+			name:     "plain http to a remote host",
+			provider: "gitlab",
+			baseURL:  "http://gitlab.example.com/api/v4",
+			env:      map[string]string{"CI_PROJECT_ID": "12", "GITLAB_TOKEN": "tok", "CI_MERGE_REQUEST_IID": "3"},
+			wantErr:  "https scheme is required",
+		},
+		{
+			name:     "unknown provider",
+			provider: "bitbucket",
+			wantErr:  "unknown provider",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := TargetFromEnv(tc.provider, tc.baseURL, envFunc(tc.env))
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package projectnames
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/alesr/impact/internal/scw/account"
+	"github.com/alesr/impact/internal/scw/footprint"
+)
+
+const defaultTTL = 24 * time.Hour
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+type ProjectLister interface {
+	ListProjects(ctx context.Context, organizationID string) ([]account.Project, error)
+}
+
+// Resolver maps project IDs to names for one organization. Names are cached
+// on disk so most runs do not call the Account API.
+type Resolver struct {
+	lister ProjectLister
+	orgID  string
+	opts   options
+}
+
+type cacheFile struct {
+	FetchedAt time.Time         `json:"fetched_at"`
+	Names     map[string]string `json:"names"`
+}
+
+func NewResolver(lister ProjectLister, orgID string, opts ...Option) *Resolver {
+	cfg := options{ttl: defaultTTL, now: time.Now}
+	if dir, err := os.UserCacheDir(); err == nil {
+		cfg.cacheDir = filepath.Join(dir, "impact")
+	}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(&cfg)
+	}
+	return &Resolver{lister: lister, orgID: orgID, opts: cfg}
+}
+
+// IsID reports whether v looks like a project ID rather than a name.
+func IsID(v string) bool {
+	return uuidPattern.MatchString(v)
+}
+
+// Names returns project names keyed by ID, from the cache when it is fresh.
+func (r *Resolver) Names(ctx context.Context) (map[string]string, error) {
+	if names, ok := r.readCache(); ok {
+		return names, nil
+	}
+	return r.refresh(ctx)
+}
+
+// ResolveIDs turns a --project style list of IDs and names into IDs. IDs are
+// kept as given. A name missing from the cache triggers one refresh before it
+// is reported as unknown; a name shared by several projects selects them all.
+func (r *Resolver) ResolveIDs(ctx context.Context, values []string) ([]string, error) {
+	var names map[string]string
+	refreshed := false
+
+	ids := make([]string, 0, len(values))
+	for _, value := range values {
+		if IsID(value) {
+			ids = append(ids, value)
+			continue
+		}
+
+		if names == nil {
+			var err error
+			if names, err = r.Names(ctx); err != nil {
+				return nil, fmt.Errorf("could not resolve project name %q: %w", value, err)
+			}
+		}
+
+		matches := idsByName(names, value)
+		if len(matches) == 0 && !refreshed {
+			var err error
+			if names, err = r.refresh(ctx); err != nil {
+				return nil, fmt.Errorf("could not resolve project name %q: %w", value, err)
+			}
+			refreshed = true
+			matches = idsByName(names, value)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("could not resolve project name %q: no such project in organization %s", value, r.orgID)
+		}
+		ids = append(ids, matches...)
+	}
+	return ids, nil
+}
+
+// Attach sets the name of every project of resp found in names.
+func Attach(resp *footprint.QueryImpactDataResponse, names map[string]string) {
+	if resp == nil {
+		return
+	}
+	for i := range resp.Projects {
+		resp.Projects[i].ProjectName = names[resp.Projects[i].ProjectID]
+	}
+}
+
+func (r *Resolver) refresh(ctx context.Context) (map[string]string, error) {
+	projects, err := r.lister.ListProjects(ctx, r.orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string, len(projects))
+	for _, project := range projects {
+		names[project.ID] = project.Name
+	}
+	r.writeCache(names)
+	return names, nil
+}
+
+func (r *Resolver) cachePath() string {
+	if r.opts.cacheDir == "" || r.orgID == "" {
+		return ""
+	}
+	return filepath.Join(r.opts.cacheDir, "projects-"+r.orgID+".json")
+}
+
+func (r *Resolver) readCache() (map[string]string, bool) {
+	path := r.cachePath()
+	if path == "" {
+		return nil, false
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var cached cacheFile
+	if err := json.Unmarshal(b, &cached); err != nil || cached.Names == nil {
+		return nil, false
+	}
+	if r.opts.now().Sub(cached.FetchedAt) > r.opts.ttl {
+		return nil, false
+	}
+	return cached.Names, true
+}
+
+// writeCache is best effort: a read-only cache directory only costs an
+// extra API call on the next run.
+func (r *Resolver) writeCache(names map[string]string) {
+	path := r.cachePath()
+	if path == "" {
+		return
+	}
+
+	b, err := json.Marshal(cacheFile{FetchedAt: r.opts.now().UTC(), Names: names})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return
+	}
+	_ = os.Rename(tmp, path)
+}
+
+func idsByName(names map[string]string, name string) []string {
+	var ids []string
+	for id, n := range names {
+		if n == name {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
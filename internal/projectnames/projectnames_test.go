@@ -0,0 +1,116 @@
+package projectnames
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alesr/impact/internal/scw/account"
+	"github.com/alesr/impact/internal/scw/footprint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	idWeb  = "11111111-1111-1111-1111-111111111111"
+	idData = "22222222-2222-2222-2222-222222222222"
+)
+
+type fakeLister struct {
+	calls    int
+	projects []account.Project
+	err      error
+}
+
+func (f *fakeLister) ListProjects(context.Context, string) ([]account.Project, error) {
+	f.calls++
+	return f.projects, f.err
+}
+
+func TestResolverNames(t *testing.T) {
+	t.Parallel()
+
+	t.Run("caches names on disk until the ttl expires", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+		clock := func() time.Time { return now }
+		lister := &fakeLister{projects: []account.Project{{ID: idWeb, Name: "web"}}}
+
+		names, err := NewResolver(lister, "org", WithCacheDir(dir), WithClock(clock)).Names(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{idWeb: "web"}, names)
+
+		names, err = NewResolver(lister, "org", WithCacheDir(dir), WithClock(clock)).Names(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "web", names[idWeb])
+		assert.Equal(t, 1, lister.calls)
+
+		now = now.Add(25 * time.Hour)
+		_, err = NewResolver(lister, "org", WithCacheDir(dir), WithClock(clock)).Names(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 2, lister.calls)
+	})
+
+	t.Run("returns lister errors", func(t *testing.T) {
+		t.Parallel()
+
+		lister := &fakeLister{err: account.ErrPermissionDenied}
+		_, err := NewResolver(lister, "org", WithCacheDir(t.TempDir())).Names(context.Background())
+		assert.ErrorIs(t, err, account.ErrPermissionDenied)
+	})
+}
+
+func TestResolveIDs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("keeps ids and resolves names", func(t *testing.T) {
+		t.Parallel()
+
+		lister := &fakeLister{projects: []account.Project{{ID: idWeb, Name: "web"}, {ID: idData, Name: "data"}}}
+		ids, err := NewResolver(lister, "org", WithCacheDir(t.TempDir())).ResolveIDs(context.Background(), []string{idWeb, "data"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{idWeb, idData}, ids)
+	})
+
+	t.Run("does not call the api for ids only", func(t *testing.T) {
+		t.Parallel()
+
+		lister := &fakeLister{err: errors.New("unexpected call")}
+		ids, err := NewResolver(lister, "org", WithCacheDir("")).ResolveIDs(context.Background(), []string{idWeb})
+		require.NoError(t, err)
+		assert.Equal(t, []string{idWeb}, ids)
+		assert.Zero(t, lister.calls)
+	})
+
+	t.Run("refreshes a stale cache once for unknown names", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		lister := &fakeLister{projects: []account.Project{{ID: idWeb, Name: "web"}}}
+		_, err := NewResolver(lister, "org", WithCacheDir(dir)).Names(context.Background())
+		require.NoError(t, err)
+
+		lister.projects = append(lister.projects, account.Project{ID: idData, Name: "data"})
+		ids, err := NewResolver(lister, "org", WithCacheDir(dir)).ResolveIDs(context.Background(), []string{"data"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{idData}, ids)
+		assert.Equal(t, 2, lister.calls)
+
+		_, err = NewResolver(lister, "org", WithCacheDir(dir)).ResolveIDs(context.Background(), []string{"missing"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `could not resolve project name "missing"`)
+	})
+}
+
+func TestAttach(t *testing.T) {
+	t.Parallel()
+
+	resp := &footprint.QueryImpactDataResponse{Projects: []footprint.ProjectImpact{{ProjectID: idWeb}, {ProjectID: idData}}}
+	Attach(resp, map[string]string{idWeb: "web"})
+
+	assert.Equal(t, "web", resp.Projects[0].ProjectName)
+	assert.Empty(t, resp.Projects[1].ProjectName)
+}
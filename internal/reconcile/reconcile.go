@@ -0,0 +1,256 @@
+package reconcile
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/alesr/impact/internal/estimate"
+	"github.com/alesr/impact/internal/pkg/actualview"
+	"github.com/alesr/impact/internal/plan"
+	"github.com/alesr/impact/internal/scw/footprint"
+)
+
+const DefaultFactor = 2.0
+
+const (
+	StatusOK       = "ok"
+	StatusOff      = "off"
+	StatusNoActual = "no_actual"
+	StatusUnknown  = "unknown"
+)
+
+// Line compares the monthly estimate of one SKU with its measured footprint
+// normalized to a month. Ratios are estimated over actual and nil when either
+// side is missing.
+type Line struct {
+	SKU                   string   `json:"sku"`
+	Resources             int      `json:"resources"`
+	EstimatedKgCO2eMonth  float64  `json:"estimated_kgco2e_month"`
+	ActualKgCO2eMonth     float64  `json:"actual_kgco2e_month"`
+	KgCO2eRatio           *float64 `json:"kgco2e_ratio"`
+	EstimatedM3WaterMonth float64  `json:"estimated_m3_water_month"`
+	ActualM3WaterMonth    float64  `json:"actual_m3_water_month"`
+	M3WaterRatio          *float64 `json:"m3_water_ratio"`
+	Status                string   `json:"status"`
+}
+
+type Report struct {
+	Window  actualview.Window `json:"window"`
+	Factor  float64           `json:"factor"`
+	Lines   []Line            `json:"lines"`
+	Total   Line              `json:"total"`
+	Flagged int               `json:"flagged"`
+}
+
+// Deployed turns plan changes into the set of resources that exist once the
+// plan is applied: every change with an after state becomes a create, so
+// unchanged resources are estimated too and deletions are left out.
+func Deployed(changes []plan.ResourceChange) []plan.ResourceChange {
+	deployed := make([]plan.ResourceChange, 0, len(changes))
+	for _, change := range changes {
+		if change.After == nil {
+			continue
+		}
+		change.Actions = []string{"create"}
+		change.Before = nil
+		deployed = append(deployed, change)
+	}
+	return deployed
+}
+
+// Regions returns the sorted regions the resources live in, derived from
+// their zone when no region is set.
+func Regions(changes []plan.ResourceChange) []string {
+	seen := map[string]struct{}{}
+	for _, change := range changes {
+		region := stringAttribute(change.After, "region", change.Region)
+		if zone := stringAttribute(change.After, "zone", change.Zone); region == "" && zone != "" {
+			region = zoneRegion(zone)
+		}
+		if region != "" {
+			seen[region] = struct{}{}
+		}
+	}
+	return sortedSet(seen)
+}
+
+// Zones returns the sorted zones the resources live in, or nil when any of
+// them is regional or sets no zone, since a zone filter would hide its
+// measured footprint.
+func Zones(changes []plan.ResourceChange) []string {
+	seen := map[string]struct{}{}
+	for _, change := range changes {
+		zone := stringAttribute(change.After, "zone", change.Zone)
+		if zone == "" {
+			return nil
+		}
+		seen[zone] = struct{}{}
+	}
+	return sortedSet(seen)
+}
+
+// ProjectIDs returns the sorted project ids of the resources, or nil when
+// any of them relies on the provider default project, since a partial list
+// would hide part of the measured footprint.
+func ProjectIDs(changes []plan.ResourceChange) []string {
+	seen := map[string]struct{}{}
+	for _, change := range changes {
+		id := stringAttribute(change.After, "project_id", "")
+		if id == "" {
+			return nil
+		}
+		seen[id] = struct{}{}
+	}
+	return sortedSet(seen)
+}
+
+// Build aggregates estimate rows and the measured footprint per SKU. Only
+// SKUs present in the estimate are compared. Measured values cover the
+// window and are scaled to a month of estimate.MonthlyHours. A SKU is off
+// when a known ratio is above factor or below 1/factor.
+func Build(rows []estimate.Row, resp *footprint.QueryImpactDataResponse, window actualview.Window, factor float64) Report {
+	bySKU := map[string]*Line{}
+	var order []string
+
+	unknownKg := map[string]bool{}
+	unknownWater := map[string]bool{}
+
+	for _, row := range rows {
+		if row.SKU == "" {
+			continue
+		}
+		line, ok := bySKU[row.SKU]
+		if !ok {
+			line = &Line{SKU: row.SKU}
+			bySKU[row.SKU] = line
+			order = append(order, row.SKU)
+		}
+		line.Resources++
+		if row.KgCO2eKnown {
+			line.EstimatedKgCO2eMonth += row.KgCO2eMonth
+		} else {
+			unknownKg[row.SKU] = true
+		}
+		if row.M3WaterKnown {
+			line.EstimatedM3WaterMonth += row.M3WaterMonth
+		} else {
+			unknownWater[row.SKU] = true
+		}
+	}
+
+	scale := actualview.MonthlyScale(window)
+
+	if resp != nil {
+		for _, actual := range actualview.Flatten(resp, actualview.DepthSKU) {
+			line, ok := bySKU[actual.SKU]
+			if !ok {
+				continue
+			}
+			monthly := actualview.ScaleImpact(actual.Impact, scale)
+			line.ActualKgCO2eMonth += monthly.KgCO2Equivalent
+			line.ActualM3WaterMonth += monthly.M3WaterUsage
+		}
+	}
+
+	rep := Report{Window: window, Factor: factor, Lines: make([]Line, 0, len(order))}
+	totalUnknownKg, totalUnknownWater := false, false
+
+	for _, sku := range order {
+		line := bySKU[sku]
+		line.KgCO2eRatio = ratio(line.EstimatedKgCO2eMonth, line.ActualKgCO2eMonth, unknownKg[sku])
+		line.M3WaterRatio = ratio(line.EstimatedM3WaterMonth, line.ActualM3WaterMonth, unknownWater[sku])
+		line.Status = status(*line, unknownKg[sku] && unknownWater[sku], factor)
+		if line.Status == StatusOff {
+			rep.Flagged++
+		}
+
+		rep.Total.Resources += line.Resources
+		rep.Total.EstimatedKgCO2eMonth += line.EstimatedKgCO2eMonth
+		rep.Total.ActualKgCO2eMonth += line.ActualKgCO2eMonth
+		rep.Total.EstimatedM3WaterMonth += line.EstimatedM3WaterMonth
+		rep.Total.ActualM3WaterMonth += line.ActualM3WaterMonth
+		totalUnknownKg = totalUnknownKg || unknownKg[sku]
+		totalUnknownWater = totalUnknownWater || unknownWater[sku]
+
+		rep.Lines = append(rep.Lines, *line)
+	}
+
+	rep.Total.KgCO2eRatio = ratio(rep.Total.EstimatedKgCO2eMonth, rep.Total.ActualKgCO2eMonth, totalUnknownKg)
+	rep.Total.M3WaterRatio = ratio(rep.Total.EstimatedM3WaterMonth, rep.Total.ActualM3WaterMonth, totalUnknownWater)
+	rep.Total.Status = status(rep.Total, totalUnknownKg && totalUnknownWater, factor)
+
+	sortLines(rep.Lines)
+	return rep
+}
+
+func ratio(estimated, actual float64, unknown bool) *float64 {
+	if unknown || actual == 0 {
+		return nil
+	}
+	r := estimated / actual
+	return &r
+}
+
+func status(line Line, unknown bool, factor float64) string {
+	switch {
+	case line.ActualKgCO2eMonth == 0 && line.ActualM3WaterMonth == 0:
+		return StatusNoActual
+	case unknown:
+		return StatusUnknown
+	case isOff(line.KgCO2eRatio, factor) || isOff(line.M3WaterRatio, factor):
+		return StatusOff
+	default:
+		return StatusOK
+	}
+}
+
+func isOff(r *float64, factor float64) bool {
+	return r != nil && (*r > factor || *r < 1/factor)
+}
+
+// sortLines puts the SKUs the model is furthest from first, measured as the
+// log distance of the CO2 ratio from 1, and lines without a ratio last.
+func sortLines(lines []Line) {
+	slices.SortStableFunc(lines, func(a, b Line) int {
+		if c := cmp.Compare(deviation(b.KgCO2eRatio), deviation(a.KgCO2eRatio)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.SKU, b.SKU)
+	})
+}
+
+func deviation(r *float64) float64 {
+	if r == nil {
+		return -1
+	}
+	if *r == 0 {
+		return math.Inf(1)
+	}
+	return math.Abs(math.Log(*r))
+}
+
+func stringAttribute(attrs map[string]any, key, fallback string) string {
+	if v, ok := attrs[key].(string); ok && v != "" {
+		return v
+	}
+	return fallback
+}
+
+// zoneRegion strips the zone index, e.g. fr-par-1 becomes fr-par.
+func zoneRegion(zone string) string {
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		return zone[:i]
+	}
+	return zone
+}
+
+func sortedSet(set map[string]struct{}) []string {
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	slices.Sort(out)
+	return out
+}
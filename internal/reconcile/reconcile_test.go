@@ -0,0 +1,131 @@
+package reconcile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alesr/impact/internal/estimate"
+	"github.com/alesr/impact/internal/pkg/actualview"
+	"github.com/alesr/impact/internal/plan"
+	"github.com/alesr/impact/internal/scw/footprint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeployed(t *testing.T) {
+	t.Parallel()
+
+	changes := []plan.ResourceChange{
+		{Address: "a", Actions: []string{"no-op"}, Before: map[string]any{}, After: map[string]any{"zone": "fr-par-1"}},
+		{Address: "b", Actions: []string{"update"}, Before: map[string]any{"type": "old"}, After: map[string]any{"type": "new"}},
+		{Address: "c", Actions: []string{"delete"}, Before: map[string]any{}},
+	}
+
+	deployed := Deployed(changes)
+	require.Len(t, deployed, 2)
+	for _, change := range deployed {
+		assert.Equal(t, []string{"create"}, change.Actions)
+		assert.Nil(t, change.Before)
+	}
+	assert.Equal(t, "new", deployed[1].After["type"])
+	assert.Equal(t, []string{"no-op"}, changes[0].Actions)
+}
+
+func TestRegionsAndProjectIDs(t *testing.T) {
+	t.Parallel()
+
+	changes := []plan.ResourceChange{
+		{After: map[string]any{"zone": "fr-par-2", "project_id": "p2"}},
+		{Zone: "nl-ams-1", After: map[string]any{"project_id": "p1"}},
+		{After: map[string]any{"region": "pl-waw", "project_id": "p1"}},
+	}
+	assert.Equal(t, []string{"fr-par", "nl-ams", "pl-waw"}, Regions(changes))
+	assert.Equal(t, []string{"p1", "p2"}, ProjectIDs(changes))
+
+	changes = append(changes, plan.ResourceChange{After: map[string]any{"zone": "fr-par-1"}})
+	assert.Nil(t, ProjectIDs(changes))
+}
+
+func TestZones(t *testing.T) {
+	t.Parallel()
+
+	changes := []plan.ResourceChange{
+		{After: map[string]any{"zone": "fr-par-2"}},
+		{Zone: "fr-par-1", After: map[string]any{}},
+		{After: map[string]any{"zone": "fr-par-2"}},
+	}
+	assert.Equal(t, []string{"fr-par-1", "fr-par-2"}, Zones(changes))
+
+	changes = append(changes, plan.ResourceChange{After: map[string]any{"region": "fr-par"}})
+	assert.Nil(t, Zones(changes))
+}
+
+func TestBuild(t *testing.T) {
+	t.Parallel()
+
+	// 10 days of measurements, scaled by 730 / 240.
+	window := actualview.Window{
+		Start: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC),
+	}
+	scale := 730.0 / 240.0
+
+	rows := []estimate.Row{
+		{SKU: "ok", KgCO2eMonth: 1, KgCO2eKnown: true, M3WaterMonth: 1, M3WaterKnown: true},
+		{SKU: "ok", KgCO2eMonth: 1, KgCO2eKnown: true, M3WaterMonth: 1, M3WaterKnown: true},
+		{SKU: "high", KgCO2eMonth: 10, KgCO2eKnown: true, M3WaterMonth: 1, M3WaterKnown: true},
+		{SKU: "missing", KgCO2eMonth: 1, KgCO2eKnown: true, M3WaterMonth: 1, M3WaterKnown: true},
+		{SKU: "unknown"},
+	}
+
+	skus := func(values map[string]float64) []footprint.SKUImpact {
+		var out []footprint.SKUImpact
+		for sku, v := range values {
+			out = append(out, footprint.SKUImpact{SKU: sku, TotalSKUImpact: footprint.TotalImpact{KgCO2Equivalent: v / scale, M3WaterUsage: v / scale}})
+		}
+		return out
+	}
+	resp := &footprint.QueryImpactDataResponse{
+		Projects: []footprint.ProjectImpact{{
+			ProjectID: "p",
+			Regions: []footprint.RegionImpact{{
+				Region: "fr-par",
+				Zones: []footprint.ZoneImpact{{
+					Zone: "fr-par-1",
+					SKUs: skus(map[string]float64{"ok": 1.5, "high": 1, "unknown": 3, "not-planned": 100}),
+				}},
+			}},
+		}},
+	}
+
+	rep := Build(rows, resp, window, DefaultFactor)
+	require.Len(t, rep.Lines, 4)
+	assert.Equal(t, 1, rep.Flagged)
+
+	byStatus := map[string]Line{}
+	for _, line := range rep.Lines {
+		byStatus[line.Status] = line
+	}
+
+	high := byStatus[StatusOff]
+	assert.Equal(t, "high", high.SKU)
+	require.NotNil(t, high.KgCO2eRatio)
+	assert.InDelta(t, 10, *high.KgCO2eRatio, 1e-9)
+	assert.Equal(t, "high", rep.Lines[0].SKU, "largest deviation first")
+
+	ok := byStatus[StatusOK]
+	assert.Equal(t, 2, ok.Resources)
+	assert.InDelta(t, 1.5, ok.ActualKgCO2eMonth, 1e-9)
+	assert.InDelta(t, 2/1.5, *ok.KgCO2eRatio, 1e-9)
+
+	assert.Equal(t, "missing", byStatus[StatusNoActual].SKU)
+	assert.Nil(t, byStatus[StatusNoActual].KgCO2eRatio)
+
+	assert.Equal(t, "unknown", byStatus[StatusUnknown].SKU)
+	assert.Nil(t, byStatus[StatusUnknown].KgCO2eRatio)
+
+	assert.Equal(t, 5, rep.Total.Resources)
+	assert.InDelta(t, 13, rep.Total.EstimatedKgCO2eMonth, 1e-9)
+	assert.InDelta(t, 5.5, rep.Total.ActualKgCO2eMonth, 1e-9)
+	assert.Nil(t, rep.Total.KgCO2eRatio, "unknown estimates make the total ratio unknown")
+}
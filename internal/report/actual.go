@@ -0,0 +1,199 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/alesr/impact/internal/pkg/actualview"
+	"github.com/alesr/impact/internal/scw/footprint"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+type ActualTableOptions struct {
+	Depth  actualview.Depth
+	SortBy actualview.SortKey
+	// Top limits the table to the first Top lines after sorting; 0 shows all.
+	Top int
+}
+
+// actualJSON adds the window length and the monthly rate to the response
+// fields, so the json stays compatible with the raw API shape. Projects and
+// the lines below them get their own monthly_impact.
+type actualJSON struct {
+	*footprint.QueryImpactDataResponse
+	WindowHours   float64               `json:"window_hours"`
+	MonthlyImpact footprint.TotalImpact `json:"monthly_impact"`
+	Projects      []projectJSON         `json:"projects"`
+}
+
+type projectJSON struct {
+	footprint.ProjectImpact
+	MonthlyImpact footprint.TotalImpact `json:"monthly_impact"`
+	Regions       []regionJSON          `json:"regions"`
+}
+
+type regionJSON struct {
+	footprint.RegionImpact
+	MonthlyImpact footprint.TotalImpact `json:"monthly_impact"`
+	Zones         []zoneJSON            `json:"zones"`
+}
+
+type zoneJSON struct {
+	footprint.ZoneImpact
+	MonthlyImpact footprint.TotalImpact `json:"monthly_impact"`
+	SKUs          []skuJSON             `json:"skus"`
+}
+
+type skuJSON struct {
+	footprint.SKUImpact
+	MonthlyImpact footprint.TotalImpact `json:"monthly_impact"`
+}
+
+// monthlyProjects copies projects with the monthly rate of every level.
+func monthlyProjects(rep *footprint.QueryImpactDataResponse, scale float64) []projectJSON {
+	if rep == nil {
+		return nil
+	}
+
+	projects := make([]projectJSON, 0, len(rep.Projects))
+	for _, project := range rep.Projects {
+		p := projectJSON{ProjectImpact: project, MonthlyImpact: actualview.ScaleImpact(project.TotalProjectImpact, scale), Regions: make([]regionJSON, 0, len(project.Regions))}
+		for _, region := range project.Regions {
+			r := regionJSON{RegionImpact: region, MonthlyImpact: actualview.ScaleImpact(region.TotalRegionImpact, scale), Zones: make([]zoneJSON, 0, len(region.Zones))}
+			for _, zone := range region.Zones {
+				z := zoneJSON{ZoneImpact: zone, MonthlyImpact: actualview.ScaleImpact(zone.TotalZoneImpact, scale), SKUs: make([]skuJSON, 0, len(zone.SKUs))}
+				for _, sku := range zone.SKUs {
+					z.SKUs = append(z.SKUs, skuJSON{SKUImpact: sku, MonthlyImpact: actualview.ScaleImpact(sku.TotalSKUImpact, scale)})
+				}
+				r.Zones = append(r.Zones, z)
+			}
+			p.Regions = append(p.Regions, r)
+		}
+		projects = append(projects, p)
+	}
+	return projects
+}
+
+// PrintActualTable prints window totals and their monthly rate, then one line
+// per entry at opts.Depth. Monthly rates use the same 730-hour month as plan
+// estimates so both can be compared directly.
+func PrintActualTable(rep *footprint.QueryImpactDataResponse, opts ActualTableOptions) error {
+	window := actualview.ResponseWindow(rep)
+	scale := actualview.MonthlyScale(window)
+
+	printActualTotals(window, rep.TotalImpact, scale)
+	printActualLines(actualview.Flatten(rep, opts.Depth), rep.TotalImpact, scale, opts, false)
+	return nil
+}
+
+func printActualTotals(window actualview.Window, total footprint.TotalImpact, scale float64) {
+	monthly := actualview.ScaleImpact(total, scale)
+
+	fmt.Fprintf(os.Stdout, "Period: %s -> %s (%s)\n", window.Start.Format(time.RFC3339), window.End.Format(time.RFC3339), formatWindow(window))
+	fmt.Fprintf(os.Stdout, "Totals\n")
+	fmt.Fprintf(os.Stdout, "  kgCO2e: %.6f (%s/month)\n", total.KgCO2Equivalent, formatRate(monthly.KgCO2Equivalent, scale))
+	fmt.Fprintf(os.Stdout, "  m3 water: %.6f (%s/month)\n", total.M3WaterUsage, formatRate(monthly.M3WaterUsage, scale))
+	fmt.Fprintf(os.Stdout, "\n")
+}
+
+// printActualLines sorts and prints lines, with an organization column when
+// withOrg is set. Shares are relative to total.
+func printActualLines(lines []actualview.Line, total footprint.TotalImpact, scale float64, opts ActualTableOptions, withOrg bool) {
+	actualview.SortLines(lines, opts.SortBy)
+
+	count := len(lines)
+	if opts.Top > 0 && len(lines) > opts.Top {
+		lines = lines[:opts.Top]
+	}
+
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+
+	header := table.Row{"PROJECT"}
+	if withOrg {
+		header = append(table.Row{"ORGANIZATION"}, header...)
+	}
+	if opts.Depth >= actualview.DepthRegion {
+		header = append(header, "REGION")
+	}
+	if opts.Depth >= actualview.DepthZone {
+		header = append(header, "ZONE")
+	}
+	if opts.Depth >= actualview.DepthSKU {
+		header = append(header, "SKU", "CATEGORY")
+	}
+	header = append(header, impactHeader...)
+	tw.AppendHeader(header)
+
+	for _, line := range lines {
+		cells := table.Row{actualview.ProjectLabel(line.ProjectID, line.ProjectName)}
+		if withOrg {
+			cells = append(table.Row{line.OrganizationID}, cells...)
+		}
+		if opts.Depth >= actualview.DepthRegion {
+			cells = append(cells, line.Region)
+		}
+		if opts.Depth >= actualview.DepthZone {
+			cells = append(cells, line.Zone)
+		}
+		if opts.Depth >= actualview.DepthSKU {
+			cells = append(cells, line.SKU, line.ProductCategory)
+		}
+		tw.AppendRow(append(cells, impactCells(line.Impact, total, scale)...))
+	}
+
+	tw.Render()
+
+	if len(lines) < count {
+		fmt.Fprintf(os.Stdout, "\nshowing top %d of %d lines\n", len(lines), count)
+	}
+}
+
+var impactHeader = table.Row{"KGCO2E", "KGCO2E/MONTH", "% CO2", "M3", "M3/MONTH", "% WATER"}
+
+func impactCells(impact, total footprint.TotalImpact, scale float64) table.Row {
+	monthly := actualview.ScaleImpact(impact, scale)
+	return table.Row{
+		fmt.Sprintf("%.6f", impact.KgCO2Equivalent),
+		formatRate(monthly.KgCO2Equivalent, scale),
+		fmt.Sprintf("%.1f%%", actualview.Share(impact.KgCO2Equivalent, total.KgCO2Equivalent)),
+		fmt.Sprintf("%.6f", impact.M3WaterUsage),
+		formatRate(monthly.M3WaterUsage, scale),
+		fmt.Sprintf("%.1f%%", actualview.Share(impact.M3WaterUsage, total.M3WaterUsage)),
+	}
+}
+
+func PrintActualJSON(rep *footprint.QueryImpactDataResponse) error {
+	scale := actualview.MonthlyScale(actualview.ResponseWindow(rep))
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+
+	out := actualJSON{
+		QueryImpactDataResponse: rep,
+		WindowHours:             rep.EndDate.Sub(rep.StartDate).Hours(),
+		MonthlyImpact:           actualview.ScaleImpact(rep.TotalImpact, scale),
+		Projects:                monthlyProjects(rep, scale),
+	}
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("could not encode json report: %w", err)
+	}
+	return nil
+}
+
+// formatRate prints a monthly rate, or "n/a" when the window is empty.
+func formatRate(v, scale float64) string {
+	if scale == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.6f", v)
+}
+
+func formatWindow(w actualview.Window) string {
+	days := math.Round(w.End.Sub(w.Start).Hours()/24*10) / 10
+	return strconv.FormatFloat(days, 'f', -1, 64) + " days"
+}
@@ -0,0 +1,139 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alesr/impact/internal/pkg/actualview"
+	"github.com/alesr/impact/internal/scw/footprint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintActualTable(t *testing.T) {
+	rep := &footprint.QueryImpactDataResponse{
+		StartDate:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:     time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).Add(2 * 730 * time.Hour),
+		TotalImpact: footprint.TotalImpact{KgCO2Equivalent: 10, M3WaterUsage: 1},
+		Projects: []footprint.ProjectImpact{
+			{
+				ProjectID:          "p1",
+				TotalProjectImpact: footprint.TotalImpact{KgCO2Equivalent: 2, M3WaterUsage: 0.5},
+			},
+			{
+				ProjectID:          "p2",
+				TotalProjectImpact: footprint.TotalImpact{KgCO2Equivalent: 8, M3WaterUsage: 0.5},
+				Regions: []footprint.RegionImpact{{
+					Region: "nl-ams",
+					Zones: []footprint.ZoneImpact{{
+						Zone: "nl-ams-1",
+						SKUs: []footprint.SKUImpact{
+							{SKU: "sku-big", ProductCategory: "instances", TotalSKUImpact: footprint.TotalImpact{KgCO2Equivalent: 6}},
+							{SKU: "sku-small", ProductCategory: "block_storage", TotalSKUImpact: footprint.TotalImpact{KgCO2Equivalent: 2, M3WaterUsage: 0.5}},
+						},
+					}},
+				}},
+			},
+		},
+	}
+
+	t.Run("project depth sorted by co2", func(t *testing.T) {
+		output := captureStdout(t, func() {
+			require.NoError(t, PrintActualTable(rep, ActualTableOptions{}))
+		})
+
+		assert.Contains(t, output, "(60.8 days)")
+		assert.Contains(t, output, "kgCO2e: 10.000000 (5.000000/month)")
+		assert.Contains(t, output, "KGCO2E/MONTH")
+		assert.Contains(t, output, "4.000000")
+		assert.Contains(t, output, "PROJECT")
+		assert.NotContains(t, output, "REGION")
+		assert.Contains(t, output, "80.0%")
+		assert.Less(t, strings.Index(output, "p2"), strings.Index(output, "p1"))
+	})
+
+	t.Run("sku depth with top limit", func(t *testing.T) {
+		output := captureStdout(t, func() {
+			require.NoError(t, PrintActualTable(rep, ActualTableOptions{Depth: actualview.DepthSKU, Top: 2}))
+		})
+
+		assert.Contains(t, output, "SKU")
+		assert.Contains(t, output, "sku-big")
+		assert.Contains(t, output, "60.0%")
+		assert.NotContains(t, output, "sku-small")
+		assert.Contains(t, output, "showing top 2 of 3 lines")
+	})
+}
+
+func TestPrintActualJSON(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	rep := &footprint.QueryImpactDataResponse{
+		StartDate:   start,
+		EndDate:     start.AddDate(0, 0, 90),
+		TotalImpact: footprint.TotalImpact{KgCO2Equivalent: 90 * 24, M3WaterUsage: 1},
+		Projects: []footprint.ProjectImpact{{
+			ProjectID:          "p1",
+			TotalProjectImpact: footprint.TotalImpact{KgCO2Equivalent: 90 * 24, M3WaterUsage: 1},
+			Regions: []footprint.RegionImpact{{
+				Region:            "fr-par",
+				TotalRegionImpact: footprint.TotalImpact{KgCO2Equivalent: 90 * 24},
+				Zones: []footprint.ZoneImpact{{
+					Zone:            "fr-par-1",
+					TotalZoneImpact: footprint.TotalImpact{KgCO2Equivalent: 90 * 24},
+					SKUs: []footprint.SKUImpact{{
+						SKU:            "sku-1",
+						TotalSKUImpact: footprint.TotalImpact{KgCO2Equivalent: 90 * 12},
+					}},
+				}},
+			}},
+		}},
+	}
+
+	output := captureStdout(t, func() {
+		require.NoError(t, PrintActualJSON(rep))
+	})
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal([]byte(output), &decoded))
+	assert.Contains(t, decoded, "total_impact")
+	assert.Contains(t, decoded, "projects")
+	assert.InDelta(t, 2160.0, decoded["window_hours"], 1e-9)
+	monthly := decoded["monthly_impact"].(map[string]any)
+	assert.InDelta(t, 730.0, monthly["kg_co2_equivalent"], 1e-9)
+
+	var typed struct {
+		Projects []struct {
+			ProjectID          string                `json:"project_id"`
+			TotalProjectImpact footprint.TotalImpact `json:"total_project_impact"`
+			MonthlyImpact      footprint.TotalImpact `json:"monthly_impact"`
+			Regions            []struct {
+				MonthlyImpact footprint.TotalImpact `json:"monthly_impact"`
+				Zones         []struct {
+					MonthlyImpact footprint.TotalImpact `json:"monthly_impact"`
+					SKUs          []struct {
+						SKU            string                `json:"sku"`
+						TotalSKUImpact footprint.TotalImpact `json:"total_sku_impact"`
+						MonthlyImpact  footprint.TotalImpact `json:"monthly_impact"`
+					} `json:"skus"`
+				} `json:"zones"`
+			} `json:"regions"`
+		} `json:"projects"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(output), &typed))
+	require.Len(t, typed.Projects, 1)
+
+	project := typed.Projects[0]
+	assert.Equal(t, "p1", project.ProjectID)
+	assert.InDelta(t, 2160.0, project.TotalProjectImpact.KgCO2Equivalent, 1e-9)
+	assert.InDelta(t, 730.0, project.MonthlyImpact.KgCO2Equivalent, 1e-9)
+	assert.InDelta(t, 730.0/2160, project.MonthlyImpact.M3WaterUsage, 1e-9)
+	assert.InDelta(t, 730.0, project.Regions[0].MonthlyImpact.KgCO2Equivalent, 1e-9)
+	assert.InDelta(t, 730.0, project.Regions[0].Zones[0].MonthlyImpact.KgCO2Equivalent, 1e-9)
+
+	sku := project.Regions[0].Zones[0].SKUs[0]
+	assert.Equal(t, "sku-1", sku.SKU)
+	assert.InDelta(t, 1080.0, sku.TotalSKUImpact.KgCO2Equivalent, 1e-9)
+	assert.InDelta(t, 365.0, sku.MonthlyImpact.KgCO2Equivalent, 1e-9)
+}
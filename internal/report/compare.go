@@ -0,0 +1,90 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/alesr/impact/internal/pkg/actualview"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// PrintComparisonTable prints the total deltas followed by the projects,
+// regions and SKUs that moved the most, all as monthly rates. Top limits each
+// level; 0 shows all.
+func PrintComparisonTable(cmp actualview.Comparison, opts ActualTableOptions) error {
+	fmt.Fprintf(os.Stdout, "Current:  %s -> %s\n", cmp.Current.Start.Format(time.RFC3339), cmp.Current.End.Format(time.RFC3339))
+	fmt.Fprintf(os.Stdout, "Previous: %s -> %s\n", cmp.Previous.Start.Format(time.RFC3339), cmp.Previous.End.Format(time.RFC3339))
+	fmt.Fprintf(os.Stdout, "\nTotals per month\n")
+	fmt.Fprintf(os.Stdout, "  kgCO2e: %.6f -> %.6f  %s\n", cmp.Total.Previous.KgCO2Equivalent, cmp.Total.Current.KgCO2Equivalent, formatChange(cmp.Total.KgCO2eDelta, cmp.Total.KgCO2ePercent))
+	fmt.Fprintf(os.Stdout, "  m3 water: %.6f -> %.6f  %s\n", cmp.Total.Previous.M3WaterUsage, cmp.Total.Current.M3WaterUsage, formatChange(cmp.Total.M3WaterDelta, cmp.Total.M3WaterPercent))
+
+	levels := []struct {
+		title  string
+		header table.Row
+		key    func(actualview.Delta) table.Row
+		deltas []actualview.Delta
+	}{
+		{"projects", table.Row{"PROJECT"}, func(d actualview.Delta) table.Row {
+			return table.Row{actualview.ProjectLabel(d.ProjectID, d.ProjectName)}
+		}, cmp.Projects},
+		{"regions", table.Row{"PROJECT", "REGION"}, func(d actualview.Delta) table.Row {
+			return table.Row{actualview.ProjectLabel(d.ProjectID, d.ProjectName), d.Region}
+		}, cmp.Regions},
+		{"SKUs", table.Row{"PROJECT", "ZONE", "SKU"}, func(d actualview.Delta) table.Row {
+			return table.Row{actualview.ProjectLabel(d.ProjectID, d.ProjectName), d.Zone, d.SKU}
+		}, cmp.SKUs},
+	}
+
+	for _, level := range levels {
+		deltas := level.deltas
+		if opts.Top > 0 && len(deltas) > opts.Top {
+			deltas = deltas[:opts.Top]
+		}
+
+		fmt.Fprintf(os.Stdout, "\nTop movers: %s (%d of %d)\n", level.title, len(deltas), len(level.deltas))
+
+		tw := table.NewWriter()
+		tw.SetOutputMirror(os.Stdout)
+		tw.AppendHeader(append(level.header, "KGCO2E/MONTH PREV", "KGCO2E/MONTH CUR", "KGCO2E CHANGE", "M3 CHANGE"))
+		for _, d := range deltas {
+			tw.AppendRow(append(level.key(d),
+				fmt.Sprintf("%.6f", d.Previous.KgCO2Equivalent),
+				fmt.Sprintf("%.6f", d.Current.KgCO2Equivalent),
+				formatChange(d.KgCO2eDelta, d.KgCO2ePercent),
+				formatChange(d.M3WaterDelta, d.M3WaterPercent),
+			))
+		}
+		tw.Render()
+	}
+	return nil
+}
+
+func PrintComparisonJSON(cmp actualview.Comparison) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(cmp); err != nil {
+		return fmt.Errorf("could not encode json report: %w", err)
+	}
+	return nil
+}
+
+func formatChange(delta float64, percent *float64) string {
+	arrow := "="
+	switch {
+	case delta > 0:
+		arrow = "▲"
+	case delta < 0:
+		arrow = "▼"
+	}
+
+	if percent == nil {
+		if delta == 0 {
+			return fmt.Sprintf("%s %+.6f", arrow, delta)
+		}
+		return fmt.Sprintf("%s %+.6f (new)", arrow, delta)
+	}
+	return fmt.Sprintf("%s %+.6f (%+.1f%%)", arrow, delta, *percent)
+}
@@ -0,0 +1,41 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/alesr/impact/internal/pkg/actualview"
+	"github.com/alesr/impact/internal/scw/footprint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintComparisonTable(t *testing.T) {
+	pct := 50.0
+	cmp := actualview.Comparison{
+		Total: actualview.Delta{
+			Current: footprint.TotalImpact{KgCO2Equivalent: 3}, Previous: footprint.TotalImpact{KgCO2Equivalent: 2},
+			KgCO2eDelta: 1, KgCO2ePercent: &pct,
+		},
+		Projects: []actualview.Delta{
+			{ProjectID: "p-new", KgCO2eDelta: 2},
+			{ProjectID: "p-old", KgCO2eDelta: -1},
+		},
+	}
+
+	output := captureStdout(t, func() {
+		require.NoError(t, PrintComparisonTable(cmp, ActualTableOptions{Top: 1}))
+	})
+
+	assert.Contains(t, output, "Totals per month")
+	assert.Contains(t, output, "kgCO2e: 2.000000 -> 3.000000  ▲ +1.000000 (+50.0%)")
+	assert.Contains(t, output, "Top movers: projects (1 of 2)")
+	assert.Contains(t, output, "▲ +2.000000 (new)")
+	assert.NotContains(t, output, "p-old")
+	assert.Contains(t, output, "Top movers: SKUs (0 of 0)")
+}
+
+func TestFormatChange(t *testing.T) {
+	pct := -12.5
+	assert.Equal(t, "▼ -0.500000 (-12.5%)", formatChange(-0.5, &pct))
+	assert.Equal(t, "= +0.000000", formatChange(0, nil))
+}
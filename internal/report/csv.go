@@ -0,0 +1,94 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/alesr/impact/internal/estimate"
+	"github.com/alesr/impact/internal/pkg/actualview"
+	"github.com/alesr/impact/internal/scw/footprint"
+)
+
+var planCSVHeader = []string{
+	"source", "address", "type", "action", "sku",
+	"kgco2e_month", "m3_water_month", "status", "unsupported_code", "unsupported_reason",
+}
+
+var actualCSVHeader = []string{
+	"start_date", "end_date", "project_id", "project_name", "region", "zone", "sku",
+	"service_category", "product_category", "kgco2e", "m3_water",
+}
+
+// PrintPlanCSV writes one line per row. Unknown footprint values are left
+// empty. With includeUnsupported, unsupported resources are appended with
+// status "unsupported" and their error code and reason.
+func PrintPlanCSV(rep estimate.Report, includeUnsupported bool) error {
+	return writePlanCSV(os.Stdout, rep, includeUnsupported)
+}
+
+func writePlanCSV(w io.Writer, rep estimate.Report, includeUnsupported bool) error {
+	records := make([][]string, 0, len(rep.Rows)+len(rep.Unsupported)+1)
+	records = append(records, planCSVHeader)
+
+	for _, row := range rep.Rows {
+		records = append(records, []string{
+			row.Source, row.Address, row.Type, row.Action, row.SKU,
+			csvFloat(row.KgCO2eMonth, row.KgCO2eKnown),
+			csvFloat(row.M3WaterMonth, row.M3WaterKnown),
+			"estimated", "", "",
+		})
+	}
+
+	if includeUnsupported {
+		for _, u := range rep.Unsupported {
+			records = append(records, []string{
+				u.Source, u.Address, "", "", "", "", "", "unsupported", u.Code, u.Reason,
+			})
+		}
+	}
+	return writeCSV(w, records)
+}
+
+// PrintActualCSV flattens the impact tree to one line per SKU. Projects,
+// regions or zones without children are written as a single line with the
+// deeper columns left empty, so the impact columns always sum to the total.
+func PrintActualCSV(rep *footprint.QueryImpactDataResponse) error {
+	return writeActualCSV(os.Stdout, rep)
+}
+
+func writeActualCSV(w io.Writer, rep *footprint.QueryImpactDataResponse) error {
+	start, end := rep.StartDate.Format(time.RFC3339), rep.EndDate.Format(time.RFC3339)
+
+	lines := actualview.Flatten(rep, actualview.DepthSKU)
+
+	records := make([][]string, 0, len(lines)+1)
+	records = append(records, actualCSVHeader)
+	for _, line := range lines {
+		records = append(records, []string{
+			start, end, line.ProjectID, line.ProjectName, line.Region, line.Zone, line.SKU, line.ServiceCategory, line.ProductCategory,
+			strconv.FormatFloat(line.Impact.KgCO2Equivalent, 'f', -1, 64),
+			strconv.FormatFloat(line.Impact.M3WaterUsage, 'f', -1, 64),
+		})
+	}
+	return writeCSV(w, records)
+}
+
+func writeCSV(w io.Writer, records [][]string) error {
+	cw := csv.NewWriter(w)
+	cw.UseCRLF = true
+	if err := cw.WriteAll(records); err != nil {
+		return fmt.Errorf("could not write csv report: %w", err)
+	}
+	return nil
+}
+
+func csvFloat(v float64, known bool) string {
+	if !known {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,85 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/alesr/impact/internal/estimate"
+	"github.com/alesr/impact/internal/scw/footprint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePlanCSV(t *testing.T) {
+	t.Parallel()
+
+	rep := estimate.Report{
+		Rows: []estimate.Row{
+			{Address: `scaleway_instance_server.web["a,b"]`, Type: "scaleway_instance_server", Action: "create", SKU: "sku-1", KgCO2eMonth: 1.25, KgCO2eKnown: true},
+		},
+		Unsupported: []estimate.UnsupportedResource{{Source: "app", Address: "scaleway_x.y", Code: "unsupported_type", Reason: `type "x" not implemented`}},
+	}
+
+	t.Run("writes rows with stable headers and rfc 4180 quoting", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		require.NoError(t, writePlanCSV(&buf, rep, false))
+
+		assert.Equal(t,
+			"source,address,type,action,sku,kgco2e_month,m3_water_month,status,unsupported_code,unsupported_reason\r\n"+
+				",\"scaleway_instance_server.web[\"\"a,b\"\"]\",scaleway_instance_server,create,sku-1,1.25,,estimated,,\r\n",
+			buf.String(),
+		)
+	})
+
+	t.Run("appends unsupported resources", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		require.NoError(t, writePlanCSV(&buf, rep, true))
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, []string{"app", "scaleway_x.y", "", "", "", "", "", "unsupported", "unsupported_type", `type "x" not implemented`}, records[2])
+	})
+}
+
+func TestWriteActualCSV(t *testing.T) {
+	t.Parallel()
+
+	rep := &footprint.QueryImpactDataResponse{
+		StartDate: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		Projects: []footprint.ProjectImpact{
+			{
+				ProjectID:   "p1",
+				ProjectName: "web",
+				Regions: []footprint.RegionImpact{{
+					Region: "fr-par",
+					Zones: []footprint.ZoneImpact{{
+						Zone: "fr-par-1",
+						SKUs: []footprint.SKUImpact{
+							{SKU: "sku-a", ServiceCategory: "compute", ProductCategory: "instances", TotalSKUImpact: footprint.TotalImpact{KgCO2Equivalent: 1.5, M3WaterUsage: 0.1}},
+							{SKU: "sku-b", ServiceCategory: "storage", ProductCategory: "block_storage", TotalSKUImpact: footprint.TotalImpact{KgCO2Equivalent: 0.5}},
+						},
+					}},
+				}},
+			},
+			{ProjectID: "p2", TotalProjectImpact: footprint.TotalImpact{KgCO2Equivalent: 2}},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeActualCSV(&buf, rep))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	assert.Equal(t, actualCSVHeader, records[0])
+	assert.Equal(t, []string{"2026-01-01T00:00:00Z", "2026-02-01T00:00:00Z", "p1", "web", "fr-par", "fr-par-1", "sku-a", "compute", "instances", "1.5", "0.1"}, records[1])
+	assert.Equal(t, []string{"2026-01-01T00:00:00Z", "2026-02-01T00:00:00Z", "p2", "", "", "", "", "", "", "2", "0"}, records[3])
+}
@@ -0,0 +1,214 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/alesr/impact/internal/estimate"
+	"github.com/alesr/impact/internal/pkg/planview"
+)
+
+// maxMarkdownBytes keeps the rendered comment below GitHub's 65536 character
+// limit, with room left for a marker or a footer added by the caller.
+const maxMarkdownBytes = 60000
+
+// markdownNoteReserve is kept free for the note that replaces a truncated or
+// dropped section.
+const markdownNoteReserve = 96
+
+func PrintMarkdown(rep estimate.Report) error {
+	if _, err := io.WriteString(os.Stdout, RenderMarkdown(rep)); err != nil {
+		return fmt.Errorf("could not write markdown report: %w", err)
+	}
+	return nil
+}
+
+// RenderMarkdown renders a compact summary meant for pull request comments.
+// Long lists are truncated with a count of the omitted entries so the result
+// stays under maxMarkdownBytes.
+func RenderMarkdown(rep estimate.Report) string {
+	return renderMarkdown(rep, maxMarkdownBytes)
+}
+
+func renderMarkdown(rep estimate.Report, maxBytes int) string {
+	var b strings.Builder
+
+	b.WriteString("### Impact estimate\n\n")
+	b.WriteString("| | Monthly delta |\n|---|---|\n")
+	fmt.Fprintf(&b, "| kgCO2e | %s |\n", markdownDelta(rep.Totals.KgCO2eMonth, rep.Totals.KgCO2eKnown, planview.FormatKg))
+	fmt.Fprintf(&b, "| m3 water | %s |\n", markdownDelta(rep.Totals.M3WaterMonth, rep.Totals.M3WaterKnown, planview.FormatWater))
+	b.WriteString("\n")
+
+	if note := planview.UnknownImpactNote(rep.Totals.UnknownRows); note != "" {
+		fmt.Fprintf(&b, "> [!WARNING]\n> Estimate is partial: %s.\n\n", note)
+	}
+
+	if len(rep.Groups) > 0 {
+		b.WriteString("| Plan | kgCO2e/month | m3/month | Unsupported |\n|---|---:|---:|---:|\n")
+		for _, group := range rep.Groups {
+			fmt.Fprintf(&b, "| %s | %s | %s | %d |\n",
+				markdownCell(group.Source),
+				markdownDelta(group.Totals.KgCO2eMonth, group.Totals.KgCO2eKnown, planview.FormatKg),
+				markdownDelta(group.Totals.M3WaterMonth, group.Totals.M3WaterKnown, planview.FormatWater),
+				group.Unsupported,
+			)
+		}
+		b.WriteString("\n")
+	}
+
+	// Sections below are written in order of importance, each truncated
+	// against what is left of the budget once the notes of the later
+	// sections are reserved, so a dropped section is always reported.
+	sections := []struct {
+		present bool
+		write   func(*strings.Builder, int)
+	}{
+		{len(rep.Findings) > 0, func(b *strings.Builder, budget int) { writeMarkdownFindings(b, rep.Findings, budget) }},
+		{len(rep.Unsupported) > 0, func(b *strings.Builder, budget int) { writeMarkdownUnsupported(b, rep.Unsupported, budget) }},
+		{len(rep.Rows) > 0, func(b *strings.Builder, budget int) { writeMarkdownRows(b, rep.Rows, len(rep.Groups) > 0, budget) }},
+	}
+	for i, section := range sections {
+		reserve := 0
+		for _, later := range sections[i+1:] {
+			if later.present {
+				reserve += markdownNoteReserve
+			}
+		}
+		section.write(&b, maxBytes-b.Len()-reserve)
+	}
+
+	return b.String()
+}
+
+func writeMarkdownFindings(b *strings.Builder, findings []estimate.Finding, budget int) {
+	if len(findings) == 0 {
+		return
+	}
+
+	lines := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		line := fmt.Sprintf("- **%s** `%s`: %s", finding.Severity, finding.Rule, finding.Message)
+		if finding.Address != "" {
+			line += fmt.Sprintf(" (`%s`)", finding.Address)
+		}
+		lines = append(lines, line+"\n")
+	}
+
+	header := fmt.Sprintf("#### Policy findings (%d)\n\n", len(findings))
+	writeMarkdownLines(b, header, lines, "\n", fmt.Sprintf("%d policy finding(s)", len(findings)), budget)
+}
+
+func writeMarkdownUnsupported(b *strings.Builder, unsupported []estimate.UnsupportedResource, budget int) {
+	if len(unsupported) == 0 {
+		return
+	}
+
+	byCode := map[string][]estimate.UnsupportedResource{}
+	for _, u := range unsupported {
+		byCode[u.Code] = append(byCode[u.Code], u)
+	}
+
+	codes := make([]string, 0, len(byCode))
+	for code := range byCode {
+		codes = append(codes, code)
+	}
+	slices.Sort(codes)
+
+	var lines []string
+	for _, code := range codes {
+		lines = append(lines, fmt.Sprintf("- `%s` (%d)\n", code, len(byCode[code])))
+		for _, u := range byCode[code] {
+			address := u.Address
+			if u.Source != "" {
+				address = u.Source + ": " + address
+			}
+			lines = append(lines, fmt.Sprintf("  - `%s`: %s\n", address, u.Reason))
+		}
+	}
+
+	header := fmt.Sprintf("#### Unsupported resources (%d)\n\n", len(unsupported))
+	writeMarkdownLines(b, header, lines, "\n", fmt.Sprintf("%d unsupported resource(s)", len(unsupported)), budget)
+}
+
+func writeMarkdownRows(b *strings.Builder, rows []estimate.Row, grouped bool, budget int) {
+	if len(rows) == 0 {
+		return
+	}
+
+	sorted := slices.Clone(rows)
+	planview.SortRows(sorted, planview.SortByCO2)
+
+	header := fmt.Sprintf("<details>\n<summary>Resources (%d)</summary>\n\n", len(rows))
+	if grouped {
+		header += "| Plan | Address | Action | kgCO2e/month | m3/month |\n|---|---|---|---:|---:|\n"
+	} else {
+		header += "| Address | Action | kgCO2e/month | m3/month |\n|---|---|---:|---:|\n"
+	}
+
+	lines := make([]string, 0, len(sorted))
+	for _, row := range sorted {
+		cells := []string{
+			"`" + markdownCell(row.Address) + "`",
+			row.Action,
+			planview.FormatKg(row.KgCO2eMonth, row.KgCO2eKnown),
+			planview.FormatWater(row.M3WaterMonth, row.M3WaterKnown),
+		}
+		if grouped {
+			cells = append([]string{markdownCell(row.Source)}, cells...)
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |\n")
+	}
+
+	writeMarkdownLines(b, header, lines, "\n</details>\n", fmt.Sprintf("%d resource row(s)", len(rows)), budget)
+}
+
+// writeMarkdownLines writes header, as many lines as fit in budget and footer.
+// When lines are dropped a note with the omitted count is added before the
+// footer. When not even the header and the first line fit, only a note
+// naming the dropped items is written.
+func writeMarkdownLines(b *strings.Builder, header string, lines []string, footer, items string, budget int) {
+	used := len(header) + len(footer) + markdownNoteReserve
+	fit := 0
+	for _, line := range lines {
+		if used+len(line) > budget {
+			break
+		}
+		used += len(line)
+		fit++
+	}
+	if fit == 0 {
+		fmt.Fprintf(b, "_… %s omitted to fit the comment size limit._\n\n", items)
+		return
+	}
+
+	b.WriteString(header)
+	for _, line := range lines[:fit] {
+		b.WriteString(line)
+	}
+
+	if omitted := len(lines) - fit; omitted > 0 {
+		fmt.Fprintf(b, "\n_… %d more line(s) omitted to fit the comment size limit._\n", omitted)
+	}
+	b.WriteString(footer)
+}
+
+func markdownDelta(v float64, known bool, format func(float64, bool) string) string {
+	formatted := format(v, known)
+	switch {
+	case !known:
+		return formatted
+	case v > 0:
+		return "▲ +" + formatted
+	case v < 0:
+		return "▼ " + formatted
+	default:
+		return "= " + formatted
+	}
+}
+
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
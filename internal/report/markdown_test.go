@@ -0,0 +1,91 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/alesr/impact/internal/estimate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	t.Parallel()
+
+	t.Run("renders totals, unsupported by code and rows", func(t *testing.T) {
+		t.Parallel()
+
+		rep := estimate.Report{
+			Rows: []estimate.Row{
+				{Address: "scaleway_instance_server.web", Action: "create", KgCO2eMonth: 1.5, KgCO2eKnown: true, M3WaterMonth: 0.01, M3WaterKnown: true},
+				{Address: `scaleway_lb.edge["a|b"]`, Action: "delete", KgCO2eMonth: -0.5, KgCO2eKnown: true},
+			},
+			Unsupported: []estimate.UnsupportedResource{
+				{Address: "scaleway_x.a", Code: "unsupported_type", Reason: "not implemented"},
+				{Address: "scaleway_rdb_instance.db", Code: "no_catalog_match", Reason: "no matching catalog product"},
+				{Address: "scaleway_x.b", Code: "unsupported_type", Reason: "not implemented"},
+			},
+			Totals: estimate.Totals{KgCO2eMonth: 1, KgCO2eKnown: true, M3WaterMonth: -0.2, M3WaterKnown: true, UnknownRows: 1},
+		}
+
+		out := RenderMarkdown(rep)
+
+		assert.Contains(t, out, "| kgCO2e | ▲ +1.000000 |")
+		assert.Contains(t, out, "| m3 water | ▼ -0.200000 |")
+		assert.Contains(t, out, "> [!WARNING]\n> Estimate is partial: partial totals: 1 row(s) have unknown footprint data.")
+		assert.Contains(t, out, "#### Unsupported resources (3)")
+		assert.Contains(t, out, "- `unsupported_type` (2)\n  - `scaleway_x.a`: not implemented\n  - `scaleway_x.b`: not implemented\n")
+		assert.Less(t, strings.Index(out, "no_catalog_match"), strings.Index(out, "unsupported_type"))
+		assert.Contains(t, out, "<details>\n<summary>Resources (2)</summary>")
+		assert.Contains(t, out, "| `scaleway_instance_server.web` | create | 1.500000 | 0.010000 |")
+		assert.Contains(t, out, "`scaleway_lb.edge[\"a\\|b\"]`")
+		assert.Contains(t, out, "</details>")
+		assert.NotContains(t, out, "omitted")
+	})
+
+	t.Run("truncates rows to the size limit", func(t *testing.T) {
+		t.Parallel()
+
+		var rep estimate.Report
+		for i := range 2000 {
+			rep.Rows = append(rep.Rows, estimate.Row{
+				Address:     fmt.Sprintf("scaleway_instance_server.web[%d]", i),
+				Action:      "create",
+				KgCO2eMonth: 1, KgCO2eKnown: true,
+			})
+		}
+
+		out := renderMarkdown(rep, 4000)
+
+		require.LessOrEqual(t, len(out), 4000)
+		assert.Contains(t, out, "more line(s) omitted to fit the comment size limit")
+		assert.True(t, strings.HasSuffix(out, "</details>\n"))
+	})
+
+	t.Run("notes every section dropped by the size limit", func(t *testing.T) {
+		t.Parallel()
+
+		rep := estimate.Report{
+			Unsupported: []estimate.UnsupportedResource{{Address: "scaleway_x.a", Code: "unsupported_type", Reason: "not implemented"}},
+			Rows:        []estimate.Row{{Address: "scaleway_instance_server.web", Action: "create"}},
+		}
+		for i := range 500 {
+			rep.Findings = append(rep.Findings, estimate.Finding{
+				Rule:     "max-co2",
+				Severity: "error",
+				Message:  fmt.Sprintf("finding %d is over the limit", i),
+			})
+		}
+
+		out := renderMarkdown(rep, 4000)
+
+		require.LessOrEqual(t, len(out), 4000)
+		assert.Contains(t, out, "#### Policy findings (500)")
+		assert.Contains(t, out, "more line(s) omitted to fit the comment size limit")
+		assert.Contains(t, out, "_… 1 unsupported resource(s) omitted to fit the comment size limit._")
+		assert.Contains(t, out, "_… 1 resource row(s) omitted to fit the comment size limit._")
+		assert.NotContains(t, out, "#### Unsupported resources")
+		assert.NotContains(t, out, "<details>")
+	})
+}
@@ -0,0 +1,123 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/alesr/impact/internal/pkg/actualview"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+var monthlyCSVHeader = []string{"month", "start_date", "end_date", "scope", "project_id", "project_name", "kgco2e", "m3_water"}
+
+// PrintMonthlyTable prints one line per month with the totals, followed by
+// one line per project with its monthly values. Sparklines follow sortBy.
+func PrintMonthlyTable(months []actualview.MonthImpact, sortBy actualview.SortKey) error {
+	unit := "kgCO2e"
+	if sortBy == actualview.SortByWater {
+		unit = "m3 water"
+	}
+
+	totals := make([]float64, 0, len(months))
+	for _, month := range months {
+		totals = append(totals, actualview.Value(month.TotalImpact, sortBy))
+	}
+	fmt.Fprintf(os.Stdout, "Monthly %s: %s\n\n", unit, actualview.Sparkline(totals))
+
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+	tw.AppendHeader(table.Row{"MONTH", "KGCO2E", "M3", "PROJECTS"})
+	for _, month := range months {
+		tw.AppendRow(table.Row{
+			month.Month,
+			fmt.Sprintf("%.6f", month.TotalImpact.KgCO2Equivalent),
+			fmt.Sprintf("%.6f", month.TotalImpact.M3WaterUsage),
+			len(month.Projects),
+		})
+	}
+	tw.Render()
+
+	projects := actualview.ProjectIDs(months)
+	if len(projects) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stdout, "\nPer project (%s)\n", unit)
+
+	pw := table.NewWriter()
+	pw.SetOutputMirror(os.Stdout)
+
+	header := table.Row{"PROJECT", "TREND"}
+	for _, month := range months {
+		header = append(header, month.Month)
+	}
+	pw.AppendHeader(header)
+
+	names := map[string]string{}
+	for _, month := range months {
+		for _, project := range month.Projects {
+			if project.ProjectName != "" {
+				names[project.ProjectID] = project.ProjectName
+			}
+		}
+	}
+
+	for _, projectID := range projects {
+		values := make([]float64, 0, len(months))
+		for _, month := range months {
+			values = append(values, actualview.Value(month.ProjectImpact(projectID), sortBy))
+		}
+
+		cells := table.Row{actualview.ProjectLabel(projectID, names[projectID]), actualview.Sparkline(values)}
+		for _, v := range values {
+			cells = append(cells, fmt.Sprintf("%.6f", v))
+		}
+		pw.AppendRow(cells)
+	}
+	pw.Render()
+	return nil
+}
+
+func PrintMonthlyJSON(months []actualview.MonthImpact) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+
+	if months == nil {
+		months = []actualview.MonthImpact{}
+	}
+	if err := enc.Encode(months); err != nil {
+		return fmt.Errorf("could not encode json report: %w", err)
+	}
+	return nil
+}
+
+// PrintMonthlyCSV writes a "total" line per month followed by one "project"
+// line per project with data that month.
+func PrintMonthlyCSV(months []actualview.MonthImpact) error {
+	return writeMonthlyCSV(os.Stdout, months)
+}
+
+func writeMonthlyCSV(w io.Writer, months []actualview.MonthImpact) error {
+	records := [][]string{monthlyCSVHeader}
+	for _, month := range months {
+		start, end := month.StartDate.Format(time.RFC3339), month.EndDate.Format(time.RFC3339)
+
+		records = append(records, []string{
+			month.Month, start, end, "total", "", "",
+			strconv.FormatFloat(month.TotalImpact.KgCO2Equivalent, 'f', -1, 64),
+			strconv.FormatFloat(month.TotalImpact.M3WaterUsage, 'f', -1, 64),
+		})
+		for _, project := range month.Projects {
+			records = append(records, []string{
+				month.Month, start, end, "project", project.ProjectID, project.ProjectName,
+				strconv.FormatFloat(project.Impact.KgCO2Equivalent, 'f', -1, 64),
+				strconv.FormatFloat(project.Impact.M3WaterUsage, 'f', -1, 64),
+			})
+		}
+	}
+	return writeCSV(w, records)
+}
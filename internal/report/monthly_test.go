@@ -0,0 +1,65 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/alesr/impact/internal/pkg/actualview"
+	"github.com/alesr/impact/internal/scw/footprint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMonths() []actualview.MonthImpact {
+	return []actualview.MonthImpact{
+		{
+			Month:       "2026-01",
+			StartDate:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:     time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+			TotalImpact: footprint.TotalImpact{KgCO2Equivalent: 1},
+			Projects:    []actualview.ProjectTotal{{ProjectID: "p1", Impact: footprint.TotalImpact{KgCO2Equivalent: 1}}},
+		},
+		{
+			Month:       "2026-02",
+			StartDate:   time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:     time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			TotalImpact: footprint.TotalImpact{KgCO2Equivalent: 3, M3WaterUsage: 0.5},
+			Projects: []actualview.ProjectTotal{
+				{ProjectID: "p1", Impact: footprint.TotalImpact{KgCO2Equivalent: 2}},
+				{ProjectID: "p2", ProjectName: "data", Impact: footprint.TotalImpact{KgCO2Equivalent: 1, M3WaterUsage: 0.5}},
+			},
+		},
+	}
+}
+
+func TestPrintMonthlyTable(t *testing.T) {
+	output := captureStdout(t, func() {
+		require.NoError(t, PrintMonthlyTable(testMonths(), actualview.SortByCO2))
+	})
+
+	assert.Contains(t, output, "Monthly kgCO2e: ▁█")
+	assert.Contains(t, output, "2026-02")
+	assert.Contains(t, output, "Per project (kgCO2e)")
+	assert.Contains(t, output, "data")
+}
+
+func TestWriteMonthlyCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeMonthlyCSV(&buf, testMonths()))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 6)
+	assert.Equal(t, monthlyCSVHeader, records[0])
+	assert.Equal(t, []string{"2026-02", "2026-02-01T00:00:00Z", "2026-03-01T00:00:00Z", "total", "", "", "3", "0.5"}, records[3])
+	assert.Equal(t, []string{"2026-02", "2026-02-01T00:00:00Z", "2026-03-01T00:00:00Z", "project", "p2", "data", "1", "0.5"}, records[5])
+}
+
+func TestPrintMonthlyJSON(t *testing.T) {
+	output := captureStdout(t, func() {
+		require.NoError(t, PrintMonthlyJSON(nil))
+	})
+	assert.Equal(t, "[]\n", output)
+}
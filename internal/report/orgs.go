@@ -0,0 +1,121 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/alesr/impact/internal/pkg/actualview"
+	"github.com/alesr/impact/internal/scw/footprint"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+type orgReportJSON struct {
+	StartDate     time.Time             `json:"start_date"`
+	EndDate       time.Time             `json:"end_date"`
+	WindowHours   float64               `json:"window_hours"`
+	TotalImpact   footprint.TotalImpact `json:"total_impact"`
+	MonthlyImpact footprint.TotalImpact `json:"monthly_impact"`
+	Organizations []orgJSON             `json:"organizations"`
+}
+
+type orgJSON struct {
+	OrganizationID string `json:"organization_id"`
+	Profile        string `json:"profile,omitempty"`
+	*footprint.QueryImpactDataResponse
+	MonthlyImpact footprint.TotalImpact `json:"monthly_impact"`
+	Projects      []projectJSON         `json:"projects,omitempty"`
+}
+
+// PrintOrgTable prints the combined totals, one subtotal line per
+// organization, then the breakdown of all organizations at opts.Depth.
+func PrintOrgTable(rep actualview.OrgReport, opts ActualTableOptions) error {
+	window := actualview.Window{Start: rep.StartDate, End: rep.EndDate}
+	scale := actualview.MonthlyScale(window)
+
+	printActualTotals(window, rep.TotalImpact, scale)
+
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+	tw.AppendHeader(append(table.Row{"ORGANIZATION", "PROFILE"}, impactHeader...))
+	for _, org := range rep.Organizations {
+		tw.AppendRow(append(table.Row{org.OrganizationID, org.Profile}, impactCells(orgTotal(org), rep.TotalImpact, scale)...))
+	}
+	tw.Render()
+	fmt.Fprintf(os.Stdout, "\n")
+
+	printActualLines(actualview.FlattenOrgs(rep, opts.Depth), rep.TotalImpact, scale, opts, true)
+	return nil
+}
+
+func PrintOrgJSON(rep actualview.OrgReport) error {
+	scale := actualview.MonthlyScale(actualview.Window{Start: rep.StartDate, End: rep.EndDate})
+
+	out := orgReportJSON{
+		StartDate:     rep.StartDate,
+		EndDate:       rep.EndDate,
+		WindowHours:   rep.EndDate.Sub(rep.StartDate).Hours(),
+		TotalImpact:   rep.TotalImpact,
+		MonthlyImpact: actualview.ScaleImpact(rep.TotalImpact, scale),
+		Organizations: make([]orgJSON, 0, len(rep.Organizations)),
+	}
+	for _, org := range rep.Organizations {
+		out.Organizations = append(out.Organizations, orgJSON{
+			OrganizationID:          org.OrganizationID,
+			Profile:                 org.Profile,
+			QueryImpactDataResponse: org.Response,
+			MonthlyImpact:           actualview.ScaleImpact(orgTotal(org), scale),
+			Projects:                monthlyProjects(org.Response, scale),
+		})
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("could not encode json report: %w", err)
+	}
+	return nil
+}
+
+var orgCSVHeader = append([]string{"scope", "organization_id"}, actualCSVHeader...)
+
+// PrintOrgCSV writes a "total" line, one "organization" subtotal line per
+// organization and the per-SKU "line" records of every organization. Sum the
+// line records only, the other scopes already include them.
+func PrintOrgCSV(rep actualview.OrgReport) error {
+	return writeOrgCSV(os.Stdout, rep)
+}
+
+func writeOrgCSV(w io.Writer, rep actualview.OrgReport) error {
+	start, end := rep.StartDate.Format(time.RFC3339), rep.EndDate.Format(time.RFC3339)
+
+	record := func(scope, org string, impact footprint.TotalImpact, cells ...string) []string {
+		out := append([]string{scope, org, start, end}, cells...)
+		return append(out,
+			strconv.FormatFloat(impact.KgCO2Equivalent, 'f', -1, 64),
+			strconv.FormatFloat(impact.M3WaterUsage, 'f', -1, 64),
+		)
+	}
+	empty := make([]string, 7)
+
+	records := [][]string{orgCSVHeader, record("total", "", rep.TotalImpact, empty...)}
+	for _, org := range rep.Organizations {
+		records = append(records, record("organization", org.OrganizationID, orgTotal(org), empty...))
+	}
+	for _, line := range actualview.FlattenOrgs(rep, actualview.DepthSKU) {
+		records = append(records, record("line", line.OrganizationID, line.Impact,
+			line.ProjectID, line.ProjectName, line.Region, line.Zone, line.SKU, line.ServiceCategory, line.ProductCategory,
+		))
+	}
+	return writeCSV(w, records)
+}
+
+func orgTotal(org actualview.OrgImpact) footprint.TotalImpact {
+	if org.Response == nil {
+		return footprint.TotalImpact{}
+	}
+	return org.Response.TotalImpact
+}
@@ -0,0 +1,105 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alesr/impact/internal/pkg/actualview"
+	"github.com/alesr/impact/internal/scw/footprint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOrgReport() actualview.OrgReport {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	org := func(project string, kg float64) *footprint.QueryImpactDataResponse {
+		impact := footprint.TotalImpact{KgCO2Equivalent: kg, M3WaterUsage: kg / 10}
+		return &footprint.QueryImpactDataResponse{
+			StartDate:   start,
+			EndDate:     end,
+			TotalImpact: impact,
+			Projects: []footprint.ProjectImpact{{
+				ProjectID:          project,
+				TotalProjectImpact: impact,
+				Regions: []footprint.RegionImpact{{Region: "fr-par", TotalRegionImpact: impact, Zones: []footprint.ZoneImpact{{
+					Zone: "fr-par-1", TotalZoneImpact: impact,
+					SKUs: []footprint.SKUImpact{{SKU: "sku-" + project, TotalSKUImpact: impact}},
+				}}}},
+			}},
+		}
+	}
+
+	return actualview.CombineOrgs([]actualview.OrgImpact{
+		{OrganizationID: "org-a", Profile: "unit-a", Response: org("pa", 1)},
+		{OrganizationID: "org-b", Response: org("pb", 3)},
+	})
+}
+
+func TestPrintOrgTable(t *testing.T) {
+	output := captureStdout(t, func() {
+		require.NoError(t, PrintOrgTable(testOrgReport(), ActualTableOptions{}))
+	})
+
+	assert.Contains(t, output, "kgCO2e: 4.000000")
+	assert.Contains(t, output, "unit-a")
+	assert.Contains(t, output, "ORGANIZATION")
+	assert.Contains(t, output, "75.0%")
+	assert.Contains(t, output, "pb")
+}
+
+func TestPrintOrgJSON(t *testing.T) {
+	output := captureStdout(t, func() {
+		require.NoError(t, PrintOrgJSON(testOrgReport()))
+	})
+
+	var decoded struct {
+		TotalImpact   footprint.TotalImpact `json:"total_impact"`
+		Organizations []struct {
+			OrganizationID string                `json:"organization_id"`
+			Profile        string                `json:"profile"`
+			TotalImpact    footprint.TotalImpact `json:"total_impact"`
+			Projects       []struct {
+				ProjectID     string                `json:"project_id"`
+				MonthlyImpact footprint.TotalImpact `json:"monthly_impact"`
+			} `json:"projects"`
+		} `json:"organizations"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(output), &decoded))
+
+	assert.Equal(t, 4.0, decoded.TotalImpact.KgCO2Equivalent)
+	require.Len(t, decoded.Organizations, 2)
+	assert.Equal(t, "org-a", decoded.Organizations[0].OrganizationID)
+	assert.Equal(t, "unit-a", decoded.Organizations[0].Profile)
+	assert.Equal(t, 3.0, decoded.Organizations[1].TotalImpact.KgCO2Equivalent)
+	require.Len(t, decoded.Organizations[1].Projects, 1)
+	assert.Equal(t, "pb", decoded.Organizations[1].Projects[0].ProjectID)
+	assert.InDelta(t, 3*730.0/744, decoded.Organizations[1].Projects[0].MonthlyImpact.KgCO2Equivalent, 1e-9)
+}
+
+func TestWriteOrgCSV(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, writeOrgCSV(&buf, testOrgReport()))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 6)
+
+	assert.Equal(t, orgCSVHeader, records[0])
+	assert.Equal(t, []string{"total", ""}, records[1][:2])
+	assert.Equal(t, "4", records[1][len(records[1])-2])
+	assert.Equal(t, []string{"organization", "org-b"}, records[3][:2])
+	assert.Equal(t, "3", records[3][len(records[3])-2])
+	assert.Equal(t, []string{"line", "org-a"}, records[4][:2])
+	assert.Equal(t, "pa", records[4][4])
+	assert.Equal(t, "sku-pa", records[4][8])
+	for _, record := range records {
+		assert.Len(t, record, len(orgCSVHeader))
+	}
+}
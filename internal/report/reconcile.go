@@ -0,0 +1,61 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/alesr/impact/internal/reconcile"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// PrintReconcileTable prints one line per SKU with the estimated and
+// measured monthly footprint and their ratio, followed by the total.
+func PrintReconcileTable(rep reconcile.Report) error {
+	fmt.Fprintf(os.Stdout, "Window: %s -> %s (measured values scaled to a month)\n\n", rep.Window.Start.Format(time.RFC3339), rep.Window.End.Format(time.RFC3339))
+
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+	tw.AppendHeader(table.Row{"SKU", "RESOURCES", "KGCO2E EST", "KGCO2E ACT", "RATIO", "M3 EST", "M3 ACT", "RATIO", "STATUS"})
+
+	for _, line := range rep.Lines {
+		tw.AppendRow(reconcileRow(line.SKU, line))
+	}
+	tw.AppendFooter(reconcileRow("TOTAL", rep.Total))
+	tw.Render()
+
+	fmt.Fprintf(os.Stdout, "\nFlagged: %d of %d SKUs off by more than x%g\n", rep.Flagged, len(rep.Lines), rep.Factor)
+	return nil
+}
+
+func PrintReconcileJSON(rep reconcile.Report) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(rep); err != nil {
+		return fmt.Errorf("could not encode json report: %w", err)
+	}
+	return nil
+}
+
+func reconcileRow(label string, line reconcile.Line) table.Row {
+	return table.Row{
+		label,
+		line.Resources,
+		fmt.Sprintf("%.6f", line.EstimatedKgCO2eMonth),
+		fmt.Sprintf("%.6f", line.ActualKgCO2eMonth),
+		formatRatio(line.KgCO2eRatio),
+		fmt.Sprintf("%.6f", line.EstimatedM3WaterMonth),
+		fmt.Sprintf("%.6f", line.ActualM3WaterMonth),
+		formatRatio(line.M3WaterRatio),
+		line.Status,
+	}
+}
+
+func formatRatio(r *float64) string {
+	if r == nil {
+		return "-"
+	}
+	return fmt.Sprintf("x%.2f", *r)
+}
@@ -0,0 +1,38 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/alesr/impact/internal/reconcile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintReconcileTable(t *testing.T) {
+	high := 4.0
+	rep := reconcile.Report{
+		Factor: 2,
+		Lines: []reconcile.Line{
+			{SKU: "/compute/dev1_s/run_fr-par-1", Resources: 2, EstimatedKgCO2eMonth: 4, ActualKgCO2eMonth: 1, KgCO2eRatio: &high, Status: reconcile.StatusOff},
+			{SKU: "/block/sbs_5k/fr-par-1", Resources: 1, EstimatedKgCO2eMonth: 1, Status: reconcile.StatusNoActual},
+		},
+		Total:   reconcile.Line{Resources: 3, EstimatedKgCO2eMonth: 5, ActualKgCO2eMonth: 1},
+		Flagged: 1,
+	}
+
+	output := captureStdout(t, func() {
+		require.NoError(t, PrintReconcileTable(rep))
+	})
+
+	assert.Contains(t, output, "/compute/dev1_s/run_fr-par-1")
+	assert.Contains(t, output, "x4.00")
+	assert.Contains(t, output, "no_actual")
+	assert.Contains(t, output, "TOTAL")
+	assert.Contains(t, output, "Flagged: 1 of 2 SKUs off by more than x2")
+}
+
+func TestFormatRatio(t *testing.T) {
+	r := 0.456
+	assert.Equal(t, "x0.46", formatRatio(&r))
+	assert.Equal(t, "-", formatRatio(nil))
+}
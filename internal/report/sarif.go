@@ -0,0 +1,135 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/alesr/impact/internal/estimate"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	sarifToolURI = "https://github.com/alesr/impact"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string       `json:"id"`
+	ShortDescription     sarifMessage `json:"shortDescription"`
+	DefaultConfiguration struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation struct {
+		URI string `json:"uri"`
+	} `json:"artifactLocation"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// PrintSARIF writes the policy findings of the report as a SARIF 2.1.0 log,
+// with one result per finding and one rule per distinct rule name.
+func PrintSARIF(rep estimate.Report) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(buildSARIF(rep)); err != nil {
+		return fmt.Errorf("could not encode sarif report: %w", err)
+	}
+	return nil
+}
+
+func buildSARIF(rep estimate.Report) sarifLog {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "impact",
+			InformationURI: sarifToolURI,
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	var seen []string
+	for _, finding := range rep.Findings {
+		if !slices.Contains(seen, finding.Rule) {
+			seen = append(seen, finding.Rule)
+
+			rule := sarifRule{ID: finding.Rule, ShortDescription: sarifMessage{Text: finding.Message}}
+			rule.DefaultConfiguration.Level = finding.Severity
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+
+		result := sarifResult{
+			RuleID:  finding.Rule,
+			Level:   finding.Severity,
+			Message: sarifMessage{Text: findingText(finding)},
+		}
+
+		if finding.Source != "" || finding.Address != "" {
+			var location sarifLocation
+			if finding.Source != "" {
+				location.PhysicalLocation = &sarifPhysicalLocation{}
+				location.PhysicalLocation.ArtifactLocation.URI = finding.Source
+			}
+			if finding.Address != "" {
+				location.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: finding.Address, Kind: "resource"}}
+			}
+			result.Locations = []sarifLocation{location}
+		}
+
+		run.Results = append(run.Results, result)
+	}
+
+	return sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}
+}
+
+func findingText(finding estimate.Finding) string {
+	if finding.Address == "" {
+		return finding.Message
+	}
+	return finding.Address + ": " + finding.Message
+}
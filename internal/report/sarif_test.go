@@ -0,0 +1,43 @@
+package report
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/alesr/impact/internal/estimate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintSARIF(t *testing.T) {
+	rep := estimate.Report{
+		Findings: []estimate.Finding{
+			{Rule: "no-gpu-in-dev", Severity: "error", Message: "GPU not allowed", Source: "dev/plan.json", Address: "scaleway_instance_server.gpu"},
+			{Rule: "no-gpu-in-dev", Severity: "error", Message: "GPU not allowed", Source: "dev/plan.json", Address: "scaleway_instance_server.gpu2"},
+			{Rule: "big-change", Severity: "note", Message: "large change"},
+		},
+	}
+
+	output := captureStdout(t, func() {
+		require.NoError(t, PrintSARIF(rep))
+	})
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal([]byte(output), &log))
+
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+
+	run := log.Runs[0]
+	assert.Equal(t, "impact", run.Tool.Driver.Name)
+	require.Len(t, run.Tool.Driver.Rules, 2)
+	assert.Equal(t, "no-gpu-in-dev", run.Tool.Driver.Rules[0].ID)
+
+	require.Len(t, run.Results, 3)
+	assert.Equal(t, "error", run.Results[0].Level)
+	assert.Equal(t, "scaleway_instance_server.gpu: GPU not allowed", run.Results[0].Message.Text)
+	require.Len(t, run.Results[0].Locations, 1)
+	assert.Equal(t, "dev/plan.json", run.Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, "scaleway_instance_server.gpu", run.Results[0].Locations[0].LogicalLocations[0].FullyQualifiedName)
+	assert.Empty(t, run.Results[2].Locations)
+}
@@ -18,14 +18,27 @@ func PrintTable(rep estimate.Report) error {
 	}
 	fmt.Fprintf(os.Stdout, "\n")
 
+	grouped := len(rep.Groups) > 0
+	if grouped {
+		printGroups(rep.Groups)
+	}
+
 	planview.SortRows(rep.Rows, planview.SortByCO2)
 
 	tw := table.NewWriter()
 	tw.SetOutputMirror(os.Stdout)
-	tw.AppendHeader(table.Row{"ADDRESS", "ACTION", "KGCO2E/MO", "M3/MO", "SKU"})
+	if grouped {
+		tw.AppendHeader(table.Row{"SOURCE", "ADDRESS", "ACTION", "KGCO2E/MO", "M3/MO", "SKU"})
+	} else {
+		tw.AppendHeader(table.Row{"ADDRESS", "ACTION", "KGCO2E/MO", "M3/MO", "SKU"})
+	}
 
 	for _, row := range rep.Rows {
-		tw.AppendRow(table.Row{row.Address, row.Action, planview.FormatKg(row.KgCO2eMonth, row.KgCO2eKnown), planview.FormatWater(row.M3WaterMonth, row.M3WaterKnown), row.SKU})
+		cells := table.Row{row.Address, row.Action, planview.FormatKg(row.KgCO2eMonth, row.KgCO2eKnown), planview.FormatWater(row.M3WaterMonth, row.M3WaterKnown), row.SKU}
+		if grouped {
+			cells = append(table.Row{row.Source}, cells...)
+		}
+		tw.AppendRow(cells)
 	}
 
 	tw.Render()
@@ -33,8 +46,44 @@ func PrintTable(rep estimate.Report) error {
 	if len(rep.Unsupported) > 0 {
 		fmt.Fprintf(os.Stdout, "\nUnsupported resources (%d):\n", len(rep.Unsupported))
 		for _, unsupported := range rep.Unsupported {
+			if unsupported.Source != "" {
+				fmt.Fprintf(os.Stdout, "  - [%s] %s: %s\n", unsupported.Source, unsupported.Address, unsupported.Reason)
+				continue
+			}
 			fmt.Fprintf(os.Stdout, "  - %s: %s\n", unsupported.Address, unsupported.Reason)
 		}
 	}
+
+	if len(rep.Findings) > 0 {
+		fmt.Fprintf(os.Stdout, "\nPolicy findings (%d):\n", len(rep.Findings))
+		for _, finding := range rep.Findings {
+			if finding.Source != "" {
+				fmt.Fprintf(os.Stdout, "  - [%s] %s: [%s] %s\n", finding.Severity, finding.Rule, finding.Source, findingText(finding))
+				continue
+			}
+			fmt.Fprintf(os.Stdout, "  - [%s] %s: %s\n", finding.Severity, finding.Rule, findingText(finding))
+		}
+	}
 	return nil
 }
+
+func printGroups(groups []estimate.Group) {
+	fmt.Fprintf(os.Stdout, "Plans (%d)\n", len(groups))
+
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+	tw.AppendHeader(table.Row{"SOURCE", "KGCO2E/MO", "M3/MO", "UNKNOWN ROWS", "UNSUPPORTED"})
+
+	for _, group := range groups {
+		tw.AppendRow(table.Row{
+			group.Source,
+			planview.FormatKg(group.Totals.KgCO2eMonth, group.Totals.KgCO2eKnown),
+			planview.FormatWater(group.Totals.M3WaterMonth, group.Totals.M3WaterKnown),
+			group.Totals.UnknownRows,
+			group.Unsupported,
+		})
+	}
+
+	tw.Render()
+	fmt.Fprintf(os.Stdout, "\n")
+}
@@ -32,3 +32,49 @@ func TestPrintTable(t *testing.T) {
 	assert.Contains(t, output, "scaleway_instance_server.web")
 	assert.Contains(t, output, "Unsupported resources (1)")
 }
+
+func TestPrintTableGroups(t *testing.T) {
+	rep := estimate.Report{
+		Rows: []estimate.Row{{
+			Source:       "app/plan.json",
+			Address:      "scaleway_instance_server.web",
+			Action:       "create",
+			KgCO2eMonth:  0.1,
+			KgCO2eKnown:  true,
+			M3WaterMonth: 0.01,
+			M3WaterKnown: true,
+		}},
+		Unsupported: []estimate.UnsupportedResource{{Source: "network/plan.json", Address: "scaleway_x.y", Reason: "not implemented"}},
+		Totals:      estimate.Totals{KgCO2eMonth: 0.1, KgCO2eKnown: true, M3WaterMonth: 0.01, M3WaterKnown: true},
+		Groups: []estimate.Group{
+			{Source: "app/plan.json", Totals: estimate.Totals{KgCO2eMonth: 0.1, KgCO2eKnown: true, M3WaterMonth: 0.01, M3WaterKnown: true}},
+			{Source: "network/plan.json", Totals: estimate.Totals{KgCO2eKnown: true, M3WaterKnown: true}, Unsupported: 1},
+		},
+	}
+
+	output := captureStdout(t, func() {
+		require.NoError(t, PrintTable(rep))
+	})
+
+	assert.Contains(t, output, "Plans (2)")
+	assert.Contains(t, output, "SOURCE")
+	assert.Contains(t, output, "network/plan.json")
+	assert.Contains(t, output, "[network/plan.json] scaleway_x.y")
+}
+
+func TestPrintTableFindings(t *testing.T) {
+	rep := estimate.Report{
+		Findings: []estimate.Finding{
+			{Rule: "no-gpu-in-dev", Severity: "error", Message: "GPU not allowed", Address: "scaleway_instance_server.gpu"},
+			{Rule: "big-change", Severity: "warning", Message: "large change"},
+		},
+	}
+
+	output := captureStdout(t, func() {
+		require.NoError(t, PrintTable(rep))
+	})
+
+	assert.Contains(t, output, "Policy findings (2)")
+	assert.Contains(t, output, "[error] no-gpu-in-dev: scaleway_instance_server.gpu: GPU not allowed")
+	assert.Contains(t, output, "[warning] big-change: large change")
+}
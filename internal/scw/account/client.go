@@ -0,0 +1,102 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	accountv3 "github.com/scaleway/scaleway-sdk-go/api/account/v3"
+	"github.com/scaleway/scaleway-sdk-go/scw"
+
+	"github.com/alesr/impact/internal/scw/httpclient"
+)
+
+// ErrPermissionDenied is returned when the API key is not allowed to list
+// projects, for example when it lacks the ProjectReadOnly permission.
+var ErrPermissionDenied = errors.New("permission denied")
+
+type Client struct {
+	api *accountv3.ProjectAPI
+}
+
+func NewClient(accessKey, secretKey string, opts ...Option) (*Client, error) {
+	if accessKey == "" {
+		return nil, errors.New("could not create account client: access key is empty")
+	}
+	if secretKey == "" {
+		return nil, errors.New("could not create account client: secret key is empty")
+	}
+
+	var cfg options
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(&cfg)
+	}
+
+	sdkOpts := []scw.ClientOption{scw.WithAuth(accessKey, secretKey)}
+	if cfg.baseURL != "" {
+		sdkOpts = append(sdkOpts, scw.WithAPIURL(cfg.baseURL))
+	}
+	if cfg.userAgent != "" {
+		sdkOpts = append(sdkOpts, scw.WithUserAgent(cfg.userAgent))
+	}
+	retry := httpclient.Retry{MaxAttempts: cfg.maxAttempts, Deadline: cfg.requestDeadline, OnRetry: cfg.onRetry}
+	if cfg.httpClient != nil {
+		sdkOpts = append(sdkOpts, scw.WithHTTPClient(httpclient.Wrap(cfg.httpClient, retry)))
+	} else {
+		httpClient, err := httpclient.New(httpclient.Config{Timeout: cfg.timeout, CABundle: cfg.caBundle, ProxyURL: cfg.proxyURL, Retry: retry})
+		if err != nil {
+			return nil, fmt.Errorf("could not create account client: %w", err)
+		}
+		sdkOpts = append(sdkOpts, scw.WithHTTPClient(httpClient))
+	}
+
+	client, err := scw.NewClient(sdkOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not create account client: %w", err)
+	}
+
+	return &Client{api: accountv3.NewProjectAPI(client)}, nil
+}
+
+// ListProjects returns every project of the organization, across all pages.
+func (c *Client) ListProjects(ctx context.Context, organizationID string) ([]Project, error) {
+	resp, err := c.api.ListProjects(
+		&accountv3.ProjectAPIListProjectsRequest{OrganizationID: organizationID},
+		scw.WithContext(ctx),
+		scw.WithAllPages(),
+	)
+	if err != nil {
+		if isPermissionError(err) {
+			return nil, fmt.Errorf("could not list projects: %w: %w", ErrPermissionDenied, err)
+		}
+		return nil, fmt.Errorf("could not list projects: %w", err)
+	}
+
+	projects := make([]Project, 0, len(resp.Projects))
+	for _, project := range resp.Projects {
+		if project == nil {
+			continue
+		}
+		projects = append(projects, Project{ID: project.ID, Name: project.Name})
+	}
+	return projects, nil
+}
+
+func isPermissionError(err error) bool {
+	var (
+		denied   *scw.PermissionsDeniedError
+		response *scw.ResponseError
+	)
+	switch {
+	case errors.As(err, &denied):
+		return true
+	case errors.As(err, &response):
+		return response.StatusCode == http.StatusForbidden
+	default:
+		return false
+	}
+}
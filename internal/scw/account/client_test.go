@@ -0,0 +1,73 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClient(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewClient("", "secret")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "access key is empty")
+
+	_, err = NewClient("SCWXXXXXXXXXXXXXXXXX", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "secret key is empty")
+}
+
+func TestListProjects(t *testing.T) {
+	t.Parallel()
+
+	newClient := func(t *testing.T, handler http.HandlerFunc) *Client {
+		t.Helper()
+
+		srv := httptest.NewServer(handler)
+		t.Cleanup(srv.Close)
+
+		client, err := NewClient("SCWXXXXXXXXXXXXXXXXX", "11111111-1111-1111-1111-111111111111", WithBaseURL(srv.URL))
+		require.NoError(t, err)
+		return client
+	}
+
+	t.Run("returns projects of every page", func(t *testing.T) {
+		t.Parallel()
+
+		client := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/account/v3/projects", r.URL.Path)
+			assert.Equal(t, "org", r.URL.Query().Get("organization_id"))
+
+			page := r.URL.Query().Get("page")
+			if page == "" {
+				page = "1"
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"total_count": 2, "projects": [{"id": "p%s", "name": "project %s"}]}`, page, page)
+		})
+
+		projects, err := client.ListProjects(context.Background(), "org")
+		require.NoError(t, err)
+		assert.Equal(t, []Project{{ID: "p1", Name: "project 1"}, {ID: "p2", Name: "project 2"}}, projects)
+	})
+
+	t.Run("reports missing permissions", func(t *testing.T) {
+		t.Parallel()
+
+		client := newClient(t, func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"type": "permissions_denied", "details": [{"resource": "projects", "action": "read"}]}`)
+		})
+
+		_, err := client.ListProjects(context.Background(), "org")
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrPermissionDenied)
+	})
+}
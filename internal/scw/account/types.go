@@ -0,0 +1,6 @@
+package account
+
+type Project struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
@@ -3,10 +3,11 @@ package catalog
 import (
 	"context"
 	"fmt"
-	"net/http"
 
 	productcatalog "github.com/scaleway/scaleway-sdk-go/api/product_catalog/v2alpha1"
 	"github.com/scaleway/scaleway-sdk-go/scw"
+
+	"github.com/alesr/impact/internal/scw/httpclient"
 )
 
 type Client struct {
@@ -29,10 +30,15 @@ func NewClient(opts ...Option) (*Client, error) {
 	if cfg.userAgent != "" {
 		sdkOpts = append(sdkOpts, scw.WithUserAgent(cfg.userAgent))
 	}
+	retry := httpclient.Retry{MaxAttempts: cfg.maxAttempts, Deadline: cfg.requestDeadline, OnRetry: cfg.onRetry}
 	if cfg.httpClient != nil {
-		sdkOpts = append(sdkOpts, scw.WithHTTPClient(cfg.httpClient))
-	} else if cfg.timeout > 0 {
-		sdkOpts = append(sdkOpts, scw.WithHTTPClient(&http.Client{Timeout: cfg.timeout}))
+		sdkOpts = append(sdkOpts, scw.WithHTTPClient(httpclient.Wrap(cfg.httpClient, retry)))
+	} else {
+		httpClient, err := httpclient.New(httpclient.Config{Timeout: cfg.timeout, CABundle: cfg.caBundle, ProxyURL: cfg.proxyURL, Retry: retry})
+		if err != nil {
+			return nil, fmt.Errorf("could not create catalog client: %w", err)
+		}
+		sdkOpts = append(sdkOpts, scw.WithHTTPClient(httpClient))
 	}
 
 	client, err := scw.NewClient(sdkOpts...)
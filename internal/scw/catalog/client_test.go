@@ -1,6 +1,14 @@
 package catalog
 
 import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -8,6 +16,8 @@ import (
 	"github.com/scaleway/scaleway-sdk-go/scw"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/alesr/impact/internal/scw/httpclient"
 )
 
 func TestFromSDKProduct(t *testing.T) {
@@ -66,3 +76,69 @@ func TestFromSDKProduct(t *testing.T) {
 		assert.Equal(t, uint64(0), out.UnitOfMeasure.Size)
 	})
 }
+
+func TestNewClientTransport(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"total_count": 1, "products": [{"sku": "sku-1"}]}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600))
+
+	client, err := NewClient(WithBaseURL(srv.URL), WithCABundle(bundle))
+	require.NoError(t, err)
+
+	resp, err := client.ListProducts(context.Background(), ListProductsRequest{Page: 1, PageSize: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "sku-1", resp.Products[0].SKU)
+
+	_, err = NewClient(WithCABundle(filepath.Join(t.TempDir(), "missing.pem")))
+	require.ErrorContains(t, err, "could not create catalog client")
+}
+
+func TestListAllProductsRetries(t *testing.T) {
+	t.Parallel()
+
+	// One product per page; the second page is rate limited once.
+	var limited atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "2" && limited.CompareAndSwap(false, true) {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"total_count": 3, "products": [{"sku": "sku-%s"}]}`, page)
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Run("retries a failed page", func(t *testing.T) {
+		var retries []httpclient.RetryEvent
+		client, err := NewClient(WithBaseURL(srv.URL), WithRetryObserver(func(e httpclient.RetryEvent) {
+			retries = append(retries, e)
+		}))
+		require.NoError(t, err)
+
+		products, err := client.ListAllProducts(context.Background())
+		require.NoError(t, err)
+		require.Len(t, products, 3)
+		assert.Equal(t, "sku-3", products[2].SKU)
+		require.Len(t, retries, 1)
+		assert.Equal(t, http.StatusTooManyRequests, retries[0].Status)
+	})
+
+	t.Run("fails without retries", func(t *testing.T) {
+		limited.Store(false)
+
+		client, err := NewClient(WithBaseURL(srv.URL), WithMaxAttempts(1))
+		require.NoError(t, err)
+
+		_, err = client.ListAllProducts(context.Background())
+		require.ErrorContains(t, err, "could not list products")
+	})
+}
@@ -5,11 +5,20 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 
 	envfootprint "github.com/scaleway/scaleway-sdk-go/api/environmental_footprint/v1alpha1"
 	"github.com/scaleway/scaleway-sdk-go/scw"
+
+	"github.com/alesr/impact/internal/scw/httpclient"
 )
 
+// ErrRangeNotSupported reports that the API rejected the start or end date of
+// a query. The API does not document how far back it keeps data, so the range
+// is only checked by the API itself.
+var ErrRangeNotSupported = errors.New("date range exceeds the window supported by the footprint API")
+
 type Client struct {
 	api *envfootprint.UserAPI
 }
@@ -37,10 +46,15 @@ func NewClient(accessKey, secretKey string, opts ...Option) (*Client, error) {
 	if cfg.userAgent != "" {
 		sdkOpts = append(sdkOpts, scw.WithUserAgent(cfg.userAgent))
 	}
+	retry := httpclient.Retry{MaxAttempts: cfg.maxAttempts, Deadline: cfg.requestDeadline, OnRetry: cfg.onRetry}
 	if cfg.httpClient != nil {
-		sdkOpts = append(sdkOpts, scw.WithHTTPClient(cfg.httpClient))
-	} else if cfg.timeout > 0 {
-		sdkOpts = append(sdkOpts, scw.WithHTTPClient(&http.Client{Timeout: cfg.timeout}))
+		sdkOpts = append(sdkOpts, scw.WithHTTPClient(httpclient.Wrap(cfg.httpClient, retry)))
+	} else {
+		httpClient, err := httpclient.New(httpclient.Config{Timeout: cfg.timeout, CABundle: cfg.caBundle, ProxyURL: cfg.proxyURL, Retry: retry})
+		if err != nil {
+			return nil, fmt.Errorf("could not create footprint client: %w", err)
+		}
+		sdkOpts = append(sdkOpts, scw.WithHTTPClient(httpClient))
 	}
 
 	client, err := scw.NewClient(sdkOpts...)
@@ -72,12 +86,29 @@ func (c *Client) QueryImpactData(ctx context.Context, req QueryImpactDataRequest
 
 	resp, err := c.api.GetImpactData(sdkReq, scw.WithContext(ctx))
 	if err != nil {
+		if rejectsDates(err) {
+			return nil, fmt.Errorf("could not query impact data: %w, use a shorter or more recent range: %w", ErrRangeNotSupported, err)
+		}
 		return nil, fmt.Errorf("could not query impact data: %w", err)
 	}
 
 	return fromSDKImpactDataResponse(resp), nil
 }
 
+// rejectsDates reports whether err is a validation error of the API about the
+// start_date or end_date of the request. The SDK turns invalid requests with
+// fields into InvalidArgumentsError.
+func rejectsDates(err error) bool {
+	isDate := func(name string) bool { return name == "start_date" || name == "end_date" }
+
+	var invalidArgs *scw.InvalidArgumentsError
+	if errors.As(err, &invalidArgs) {
+		return slices.ContainsFunc(invalidArgs.Details, func(d scw.InvalidArgumentsErrorDetail) bool { return isDate(d.ArgumentName) })
+	}
+	var respErr *scw.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusBadRequest && strings.Contains(strings.ToLower(respErr.Message), "date")
+}
+
 func fromSDKImpactDataResponse(resp *envfootprint.ImpactDataResponse) *QueryImpactDataResponse {
 	if resp == nil {
 		return &QueryImpactDataResponse{}
@@ -1,6 +1,12 @@
 package footprint
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -8,6 +14,8 @@ import (
 	"github.com/scaleway/scaleway-sdk-go/scw"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/alesr/impact/internal/scw/httpclient"
 )
 
 func TestNewClient(t *testing.T) {
@@ -95,3 +103,77 @@ func TestFromSDKImpactDataResponse(t *testing.T) {
 		assert.Empty(t, out.Projects)
 	})
 }
+
+func TestQueryImpactDataRetries(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"total_impact": {"kg_co2_equivalent": 1.5, "m3_water_usage": 0.1}, "projects": []}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	var retries int
+	client, err := NewClient("SCWXXXXXXXXXXXXXXXXX", "00000000-0000-0000-0000-000000000000",
+		WithBaseURL(srv.URL),
+		WithRequestDeadline(5*time.Second),
+		WithRetryObserver(func(httpclient.RetryEvent) { retries++ }),
+	)
+	require.NoError(t, err)
+
+	resp, err := client.QueryImpactData(context.Background(), QueryImpactDataRequest{OrganizationID: "org"})
+	require.NoError(t, err)
+	assert.InDelta(t, 1.5, resp.TotalImpact.KgCO2Equivalent, 1e-6)
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, 1, retries)
+}
+
+func TestQueryImpactDataRejectedRange(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		body      string
+		wantRange bool
+	}{
+		"invalid start date argument": {
+			body:      `{"type": "invalid_arguments", "message": "invalid argument(s)", "details": [{"argument_name": "start_date", "reason": "constraint", "help_message": "must be within the last 12 months"}]}`,
+			wantRange: true,
+		},
+		"invalid request on end date": {
+			body:      `{"type": "invalid_request_error", "message": "invalid request", "fields": {"end_date": ["too far from start_date"]}}`,
+			wantRange: true,
+		},
+		"bad request about the period": {
+			body:      `{"message": "requested date range is too large"}`,
+			wantRange: true,
+		},
+		"other invalid argument": {
+			body: `{"type": "invalid_arguments", "message": "invalid argument(s)", "details": [{"argument_name": "organization_id", "reason": "format"}]}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, tc.body)
+			}))
+			t.Cleanup(srv.Close)
+
+			client, err := NewClient("SCWXXXXXXXXXXXXXXXXX", "00000000-0000-0000-0000-000000000000", WithBaseURL(srv.URL))
+			require.NoError(t, err)
+
+			_, err = client.QueryImpactData(context.Background(), QueryImpactDataRequest{OrganizationID: "org"})
+			require.Error(t, err)
+			assert.Equal(t, tc.wantRange, errors.Is(err, ErrRangeNotSupported), err.Error())
+		})
+	}
+}
@@ -3,6 +3,8 @@ package footprint
 import (
 	"net/http"
 	"time"
+
+	"github.com/alesr/impact/internal/scw/httpclient"
 )
 
 type Option func(*options)
@@ -12,6 +14,12 @@ type options struct {
 	userAgent  string
 	timeout    time.Duration
 	httpClient *http.Client
+	caBundle   string
+	proxyURL   string
+
+	maxAttempts     int
+	requestDeadline time.Duration
+	onRetry         func(httpclient.RetryEvent)
 }
 
 func WithBaseURL(baseURL string) Option {
@@ -37,3 +45,44 @@ func WithHTTPClient(httpClient *http.Client) Option {
 		opts.httpClient = httpClient
 	}
 }
+
+// WithCABundle trusts the certificates of a PEM file on top of the system
+// pool. It is ignored when WithHTTPClient is set.
+func WithCABundle(path string) Option {
+	return func(opts *options) {
+		opts.caBundle = path
+	}
+}
+
+// WithProxyURL sends requests through proxyURL instead of the proxy from
+// HTTPS_PROXY. It is ignored when WithHTTPClient is set.
+func WithProxyURL(proxyURL string) Option {
+	return func(opts *options) {
+		opts.proxyURL = proxyURL
+	}
+}
+
+// WithMaxAttempts sets how many times a GET is tried when it fails with 429,
+// a 5xx or a network error. It defaults to httpclient.DefaultMaxAttempts; 1
+// disables retries.
+func WithMaxAttempts(n int) Option {
+	return func(opts *options) {
+		opts.maxAttempts = n
+	}
+}
+
+// WithRequestDeadline caps the time spent on one HTTP request including its
+// retries. It does not bound calls that send several requests: bound those
+// with the context.
+func WithRequestDeadline(d time.Duration) Option {
+	return func(opts *options) {
+		opts.requestDeadline = d
+	}
+}
+
+// WithRetryObserver is called before each retry.
+func WithRetryObserver(fn func(httpclient.RetryEvent)) Option {
+	return func(opts *options) {
+		opts.onRetry = fn
+	}
+}
@@ -50,6 +50,7 @@ type TotalImpact struct {
 
 type ProjectImpact struct {
 	ProjectID          string         `json:"project_id"`
+	ProjectName        string         `json:"project_name,omitempty"`
 	TotalProjectImpact TotalImpact    `json:"total_project_impact"`
 	Regions            []RegionImpact `json:"regions"`
 }
@@ -0,0 +1,70 @@
+// Package httpclient builds the http client shared by the Scaleway API
+// clients.
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+type Config struct {
+	Timeout time.Duration
+	// CABundle is a PEM file whose certificates are trusted on top of the
+	// system pool.
+	CABundle string
+	// ProxyURL overrides HTTPS_PROXY, HTTP_PROXY and NO_PROXY.
+	ProxyURL string
+	Retry    Retry
+}
+
+func New(cfg Config) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, fmt.Errorf("could not parse proxy url %q: must be a valid absolute URL", cfg.ProxyURL)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if cfg.CABundle != "" {
+		pool, err := certPool(cfg.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	// Timeout bounds each attempt, so retries get a fresh budget.
+	return &http.Client{Transport: newRetryTransport(transport, cfg.Timeout, cfg.Retry)}, nil
+}
+
+// Wrap adds retries to a caller's client, keeping its transport and timeout.
+func Wrap(client *http.Client, retry Retry) *http.Client {
+	wrapped := *client
+	wrapped.Transport = newRetryTransport(client.Transport, 0, retry)
+	return &wrapped
+}
+
+func certPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read ca bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("could not load ca bundle " + path + ": no PEM certificates found")
+	}
+	return pool, nil
+}
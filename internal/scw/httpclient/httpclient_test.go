@@ -0,0 +1,75 @@
+package httpclient
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	t.Run("trusts the ca bundle", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer srv.Close()
+
+		bundle := filepath.Join(t.TempDir(), "ca.pem")
+		require.NoError(t, os.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600))
+
+		plain, err := New(Config{Timeout: time.Second})
+		require.NoError(t, err)
+		_, err = plain.Get(srv.URL)
+		require.Error(t, err, "the test certificate is not trusted by default")
+
+		client, err := New(Config{Timeout: time.Second, CABundle: bundle})
+		require.NoError(t, err)
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+
+	t.Run("sends requests through the proxy", func(t *testing.T) {
+		t.Parallel()
+
+		var proxied string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxied = r.URL.String()
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer proxy.Close()
+
+		client, err := New(Config{ProxyURL: proxy.URL})
+		require.NoError(t, err)
+		resp, err := client.Get("http://api.example.invalid/ping")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, "http://api.example.invalid/ping", proxied)
+	})
+
+	t.Run("rejects invalid settings", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := New(Config{ProxyURL: "not a url"})
+		require.ErrorContains(t, err, "could not parse proxy url")
+
+		_, err = New(Config{CABundle: filepath.Join(t.TempDir(), "missing.pem")})
+		require.ErrorContains(t, err, "could not read ca bundle")
+
+		empty := filepath.Join(t.TempDir(), "empty.pem")
+		require.NoError(t, os.WriteFile(empty, []byte("nothing"), 0o600))
+		_, err = New(Config{CABundle: empty})
+		require.ErrorContains(t, err, "no PEM certificates found")
+	})
+}
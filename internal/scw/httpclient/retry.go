@@ -0,0 +1,216 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultMaxAttempts = 4
+	defaultBaseDelay   = 250 * time.Millisecond
+	maxBackoff         = 10 * time.Second
+)
+
+// Retry configures retries of GET and HEAD requests that fail with 429, a 5xx
+// or a transport error.
+type Retry struct {
+	// MaxAttempts counts the first try: 0 uses DefaultMaxAttempts and 1
+	// disables retries.
+	MaxAttempts int
+	// Deadline caps one request including all its retries and waits. Zero
+	// leaves it to the request context.
+	Deadline time.Duration
+	// BaseDelay is the backoff before the first retry, doubled on each
+	// retry. Zero uses 250ms.
+	BaseDelay time.Duration
+	// OnRetry is called before waiting for each retry.
+	OnRetry func(RetryEvent)
+}
+
+// RetryEvent describes a failed attempt that is about to be retried.
+type RetryEvent struct {
+	Method      string
+	URL         string
+	Attempt     int
+	MaxAttempts int
+	// Status is 0 when the attempt failed without a response.
+	Status int
+	Err    error
+	Wait   time.Duration
+}
+
+// retryTransport retries idempotent requests and applies the client timeout
+// to each attempt rather than to the request as a whole.
+type retryTransport struct {
+	next    http.RoundTripper
+	timeout time.Duration
+	retry   Retry
+}
+
+func newRetryTransport(next http.RoundTripper, timeout time.Duration, retry Retry) *retryTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if retry.MaxAttempts == 0 {
+		retry.MaxAttempts = DefaultMaxAttempts
+	}
+	if retry.BaseDelay <= 0 {
+		retry.BaseDelay = defaultBaseDelay
+	}
+	return &retryTransport{next: next, timeout: timeout, retry: retry}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.retry.MaxAttempts <= 1 || !retryableRequest(req) {
+		return t.attempt(req)
+	}
+
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if t.retry.Deadline > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.retry.Deadline)
+	}
+
+	for attempt := 1; ; attempt++ {
+		try := req.Clone(ctx)
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			try.Body = body
+		}
+
+		resp, err := t.attempt(try)
+		if attempt == t.retry.MaxAttempts || ctx.Err() != nil || !retryableResult(resp, err) {
+			return finish(resp, err, cancel)
+		}
+
+		// Fail fast rather than wait past the deadline or longer than
+		// maxBackoff, however long Retry-After asks for.
+		wait, ok := t.backoff(attempt, resp)
+		if deadline, set := ctx.Deadline(); !ok || (set && time.Until(deadline) < wait) {
+			return finish(resp, err, cancel)
+		}
+
+		event := RetryEvent{Method: req.Method, URL: req.URL.String(), Attempt: attempt, MaxAttempts: t.retry.MaxAttempts, Err: err, Wait: wait}
+		if resp != nil {
+			event.Status = resp.StatusCode
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		if t.retry.OnRetry != nil {
+			t.retry.OnRetry(event)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			cancel()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// attempt sends req once within the per-attempt timeout. The timeout keeps
+// running while the body is read, like http.Client.Timeout.
+func (t *retryTransport) attempt(req *http.Request) (*http.Response, error) {
+	if t.timeout <= 0 {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	return finish(resp, err, cancel)
+}
+
+// backoff waits as long as Retry-After asks, or doubles BaseDelay per attempt
+// with up to half of it as jitter. It reports false when Retry-After asks for
+// more than maxBackoff.
+func (t *retryTransport) backoff(attempt int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			return wait, wait <= maxBackoff
+		}
+	}
+
+	delay := min(t.retry.BaseDelay<<(attempt-1), maxBackoff)
+	return delay/2 + rand.N(delay/2+1), true
+}
+
+func retryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+func retryableRequest(req *http.Request) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func retryableResult(resp *http.Response, err error) bool {
+	if err != nil {
+		return transientError(err)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// transientError reports network failures worth another try: timeouts,
+// refused or reset connections and unexpected EOFs. Certificate errors and
+// unknown hosts fail the same way again.
+func transientError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return !dnsErr.IsNotFound
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// finish hands cancel over to the response body, or calls it right away when
+// there is no body left to read.
+func finish(resp *http.Response, err error, cancel context.CancelFunc) (*http.Response, error) {
+	if err != nil || resp == nil || resp.Body == nil {
+		cancel()
+		return resp, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
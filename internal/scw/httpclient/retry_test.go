@@ -0,0 +1,244 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyServer answers the first failures requests with status, then 200.
+func flakyServer(t *testing.T, failures int, status int, header http.Header) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if int(calls.Add(1)) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("retries 5xx and 429 until success", func(t *testing.T) {
+		t.Parallel()
+
+		for _, status := range []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable} {
+			srv, calls := flakyServer(t, 2, status, nil)
+
+			var events []RetryEvent
+			client, err := New(Config{Retry: Retry{BaseDelay: time.Millisecond, OnRetry: func(e RetryEvent) {
+				events = append(events, e)
+			}}})
+			require.NoError(t, err)
+
+			resp, err := client.Get(srv.URL + "/products")
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, int32(3), calls.Load())
+			require.Len(t, events, 2)
+			assert.Equal(t, RetryEvent{Method: http.MethodGet, URL: srv.URL + "/products", Attempt: 2, MaxAttempts: DefaultMaxAttempts, Status: status, Wait: events[1].Wait}, events[1])
+		}
+	})
+
+	t.Run("returns the last response after max attempts", func(t *testing.T) {
+		t.Parallel()
+
+		srv, calls := flakyServer(t, 10, http.StatusBadGateway, nil)
+		client, err := New(Config{Retry: Retry{MaxAttempts: 3, BaseDelay: time.Millisecond}})
+		require.NoError(t, err)
+
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("does not retry client errors, non idempotent requests or when disabled", func(t *testing.T) {
+		t.Parallel()
+
+		srv, calls := flakyServer(t, 1, http.StatusBadRequest, nil)
+		client, err := New(Config{Retry: Retry{BaseDelay: time.Millisecond}})
+		require.NoError(t, err)
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, int32(1), calls.Load())
+
+		srv, calls = flakyServer(t, 1, http.StatusServiceUnavailable, nil)
+		resp, err = client.Post(srv.URL, "application/json", strings.NewReader("{}"))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, int32(1), calls.Load())
+
+		srv, calls = flakyServer(t, 1, http.StatusServiceUnavailable, nil)
+		client, err = New(Config{Retry: Retry{MaxAttempts: 1}})
+		require.NoError(t, err)
+		resp, err = client.Get(srv.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("honours Retry-After", func(t *testing.T) {
+		t.Parallel()
+
+		srv, calls := flakyServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"0"}})
+
+		var waits []time.Duration
+		client, err := New(Config{Retry: Retry{BaseDelay: time.Hour, OnRetry: func(e RetryEvent) {
+			waits = append(waits, e.Wait)
+		}}})
+		require.NoError(t, err)
+
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int32(2), calls.Load())
+		assert.Equal(t, []time.Duration{0}, waits, "Retry-After replaces the hour long backoff")
+	})
+
+	t.Run("stops when the wait would pass the deadline", func(t *testing.T) {
+		t.Parallel()
+
+		srv, calls := flakyServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"30"}})
+		client, err := New(Config{Retry: Retry{Deadline: time.Second}})
+		require.NoError(t, err)
+
+		began := time.Now()
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.Equal(t, int32(1), calls.Load())
+		assert.Less(t, time.Since(began), time.Second)
+	})
+
+	t.Run("fails fast on a long Retry-After without a deadline", func(t *testing.T) {
+		t.Parallel()
+
+		srv, calls := flakyServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"3600"}})
+		client, err := New(Config{})
+		require.NoError(t, err)
+
+		began := time.Now()
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.Equal(t, int32(1), calls.Load())
+		assert.Less(t, time.Since(began), time.Second)
+	})
+
+	t.Run("bounds the whole request by the deadline", func(t *testing.T) {
+		t.Parallel()
+
+		srv, calls := flakyServer(t, 100, http.StatusServiceUnavailable, nil)
+		client, err := New(Config{Timeout: time.Second, Retry: Retry{MaxAttempts: 100, Deadline: 300 * time.Millisecond, BaseDelay: 50 * time.Millisecond}})
+		require.NoError(t, err)
+
+		began := time.Now()
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Less(t, calls.Load(), int32(100))
+		assert.Less(t, time.Since(began), time.Second)
+	})
+
+	t.Run("applies the timeout to each attempt", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+				return
+			}
+			_, _ = w.Write([]byte("ok"))
+		}))
+		defer srv.Close()
+
+		client, err := New(Config{Timeout: 50 * time.Millisecond, Retry: Retry{BaseDelay: time.Millisecond}})
+		require.NoError(t, err)
+
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("wraps a caller's client", func(t *testing.T) {
+		t.Parallel()
+
+		srv, calls := flakyServer(t, 1, http.StatusServiceUnavailable, nil)
+		client := Wrap(srv.Client(), Retry{BaseDelay: time.Millisecond})
+
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	t.Parallel()
+
+	transport := newRetryTransport(nil, 0, Retry{BaseDelay: 100 * time.Millisecond})
+
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 3: 400 * time.Millisecond, 20: maxBackoff} {
+		for range 20 {
+			wait, ok := transport.backoff(attempt, nil)
+			assert.True(t, ok)
+			assert.GreaterOrEqual(t, wait, want/2)
+			assert.LessOrEqual(t, wait, want)
+		}
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": {"7"}}}
+	wait, ok := transport.backoff(1, resp)
+	assert.True(t, ok)
+	assert.Equal(t, 7*time.Second, wait)
+
+	resp.Header.Set("Retry-After", "3600")
+	_, ok = transport.backoff(1, resp)
+	assert.False(t, ok, "waits longer than maxBackoff are not retried")
+
+	resp.Header.Set("Retry-After", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	wait, ok = transport.backoff(1, resp)
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), wait, "a date in the past retries right away")
+
+	resp.Header.Set("Retry-After", "soon")
+	wait, _ = transport.backoff(1, resp)
+	assert.LessOrEqual(t, wait, 100*time.Millisecond)
+}
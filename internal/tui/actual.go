@@ -0,0 +1,428 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/alesr/impact/internal/pkg/actualview"
+	"github.com/alesr/impact/internal/pkg/daterange"
+	"github.com/alesr/impact/internal/scw/footprint"
+)
+
+const shareBarWidth = 20
+
+// QueryActualFn queries measured impact for a period. Nil dates leave the
+// choice of period to the API.
+type QueryActualFn func(ctx context.Context, start, end *time.Time) (*footprint.QueryImpactDataResponse, error)
+
+// impactNode is one level of the impact tree: the root, a project, a region,
+// a zone or a SKU.
+type impactNode struct {
+	label    string
+	kind     string
+	detail   string
+	impact   footprint.TotalImpact
+	children []*impactNode
+}
+
+type actualQueryDoneMsg struct {
+	resp *footprint.QueryImpactDataResponse
+	err  error
+}
+
+type actualModel struct {
+	ctx      context.Context
+	queryFn  QueryActualFn
+	resp     *footprint.QueryImpactDataResponse
+	root     *impactNode
+	stack    []*impactNode
+	cursor   int
+	offset   int
+	sortMode sortMode
+	height   int
+	width    int
+
+	editing  bool
+	input    textinput.Model
+	querying bool
+	err      error
+}
+
+func RunActualReportLoading(ctx context.Context, start, end *time.Time, queryFn QueryActualFn) error {
+	return runLoading(ctx, "Measured footprint", "querying actual footprint data", func(ctx context.Context) (tea.Model, error) {
+		resp, err := queryFn(ctx, start, end)
+		if err != nil {
+			return nil, err
+		}
+		return newActualModel(ctx, resp, queryFn), nil
+	})
+}
+
+// newActualModel keeps ctx for the queries of later date ranges, which run
+// while the program does.
+func newActualModel(ctx context.Context, resp *footprint.QueryImpactDataResponse, queryFn QueryActualFn) actualModel {
+	input := textinput.New()
+	input.Placeholder = "YYYY-MM-DD YYYY-MM-DD or last-month"
+	input.CharLimit = 64
+	input.Width = 40
+
+	m := actualModel{
+		ctx:      ctx,
+		queryFn:  queryFn,
+		input:    input,
+		sortMode: sortByCO2,
+		height:   12,
+		width:    120,
+	}
+	m.setResponse(resp)
+	return m
+}
+
+func (m *actualModel) setResponse(resp *footprint.QueryImpactDataResponse) {
+	m.resp = resp
+	m.root = buildImpactTree(resp)
+	m.stack = []*impactNode{m.root}
+	m.cursor, m.offset = 0, 0
+	m.sortTree(m.root)
+}
+
+func buildImpactTree(resp *footprint.QueryImpactDataResponse) *impactNode {
+	root := &impactNode{label: "All projects", kind: "organization", impact: resp.TotalImpact}
+
+	for _, project := range resp.Projects {
+		projectNode := &impactNode{label: actualview.ProjectLabel(project.ProjectID, project.ProjectName), kind: "project", impact: project.TotalProjectImpact}
+		for _, region := range project.Regions {
+			regionNode := &impactNode{label: region.Region, kind: "region", impact: region.TotalRegionImpact}
+			for _, zone := range region.Zones {
+				zoneNode := &impactNode{label: zone.Zone, kind: "zone", impact: zone.TotalZoneImpact}
+				for _, sku := range zone.SKUs {
+					zoneNode.children = append(zoneNode.children, &impactNode{
+						label:  sku.SKU,
+						kind:   "sku",
+						detail: strings.Trim(sku.ServiceCategory+" / "+sku.ProductCategory, " /"),
+						impact: sku.TotalSKUImpact,
+					})
+				}
+				regionNode.children = append(regionNode.children, zoneNode)
+			}
+			projectNode.children = append(projectNode.children, regionNode)
+		}
+		root.children = append(root.children, projectNode)
+	}
+	return root
+}
+
+func (m actualModel) Init() tea.Cmd { return nil }
+
+func (m actualModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		if msg.Width > 0 {
+			m.width = msg.Width
+		}
+		if msg.Height > 12 {
+			m.height = msg.Height - 10
+		}
+		if m.height < 5 {
+			m.height = 5
+		}
+
+	case actualQueryDoneMsg:
+		m.querying = false
+		if msg.err != nil {
+			m.err = msg.err
+			break
+		}
+		m.err = nil
+		m.setResponse(msg.resp)
+
+	case tea.KeyMsg:
+		if m.editing {
+			return m.updateInput(msg)
+		}
+
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		case "up", "k":
+			m.cursor--
+		case "down", "j":
+			m.cursor++
+		case "enter", "right", "l":
+			children := m.current().children
+			if m.cursor < len(children) && len(children[m.cursor].children) > 0 {
+				m.stack = append(m.stack, children[m.cursor])
+				m.cursor, m.offset = 0, 0
+			}
+		case "left", "h", "backspace", "esc":
+			if len(m.stack) > 1 {
+				parent := m.current()
+				m.stack = m.stack[:len(m.stack)-1]
+				m.cursor = indexOf(m.current().children, parent)
+			}
+		case "s":
+			m.sortMode = (m.sortMode + 1) % 2
+			selected := m.selected()
+			m.sortTree(m.root)
+			m.cursor = indexOf(m.current().children, selected)
+		case "r":
+			if m.querying {
+				break
+			}
+			m.editing = true
+			m.input.SetValue(m.resp.StartDate.Format(daterange.Layout) + " " + m.resp.EndDate.Format(daterange.Layout))
+			m.input.CursorEnd()
+			return m, m.input.Focus()
+		}
+	}
+
+	m.clamp()
+	return m, nil
+}
+
+func (m actualModel) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.editing = false
+		m.input.Blur()
+		return m, nil
+	case "enter":
+		start, end, err := parseDateRange(m.input.Value(), time.Now())
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+
+		m.editing = false
+		m.input.Blur()
+		m.querying = true
+		m.err = nil
+		return m, m.query(start, end)
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m actualModel) query(start, end time.Time) tea.Cmd {
+	ctx, queryFn := m.ctx, m.queryFn
+	return func() tea.Msg {
+		resp, err := queryFn(ctx, &start, &end)
+		return actualQueryDoneMsg{resp: resp, err: err}
+	}
+}
+
+// parseDateRange reads the same dates as --start and --end, or one named
+// period like --period, and validates the range the same way.
+func parseDateRange(raw string, now time.Time) (time.Time, time.Time, error) {
+	fields := strings.Fields(raw)
+	switch len(fields) {
+	case 1:
+		return daterange.ParsePeriod(fields[0], now)
+	case 2:
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("could not parse date range %q (use START END or a period such as last-month)", raw)
+	}
+
+	start, err := daterange.ParseDate(fields[0], now)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("could not parse start: %w", err)
+	}
+	end, err := daterange.ParseDate(fields[1], now)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("could not parse end: %w", err)
+	}
+	if err := daterange.Validate(start, end); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, end, nil
+}
+
+func (m actualModel) View() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("impact"))
+	b.WriteString("  ")
+	b.WriteString(subtitleStyle.Render("Measured footprint"))
+	b.WriteString("\n")
+
+	b.WriteString(chipStyle.Render(fmt.Sprintf("kgCO2e %.6f", m.resp.TotalImpact.KgCO2Equivalent)))
+	b.WriteString(" ")
+	b.WriteString(chipStyle.Render(fmt.Sprintf("m3 %.6f", m.resp.TotalImpact.M3WaterUsage)))
+	b.WriteString(" ")
+	b.WriteString(subtleStyle.Render(fmt.Sprintf("%s -> %s", m.resp.StartDate.Format(daterange.Layout), m.resp.EndDate.Format(daterange.Layout))))
+	b.WriteString("\n")
+
+	labels := make([]string, 0, len(m.stack))
+	for _, node := range m.stack {
+		labels = append(labels, node.label)
+	}
+	b.WriteString(tabStyle.Render(strings.Join(labels, " › ")))
+	b.WriteString("   ")
+	b.WriteString(subtleStyle.Render("Sort: " + m.sortLabel()))
+	b.WriteString("\n")
+	b.WriteString(subtleStyle.Render("Keys: ↑/↓ (j/k) move  enter/→ drill in  ←/backspace up  s sort  r date range  q quit"))
+	b.WriteString("\n\n")
+
+	nameWidth := 42
+	if m.width > 0 && m.width < 120 {
+		nameWidth = 28
+	}
+
+	children := m.current().children
+	b.WriteString(headerStyle.Render(fmt.Sprintf("  %-*s %12s %10s  %s", nameWidth, strings.ToUpper(childKind(m.current())), "kgCO2e", "m3", "share of total")))
+	b.WriteString("\n")
+
+	if len(children) == 0 {
+		b.WriteString(subtleStyle.Render("  no impact data for this period"))
+		b.WriteString("\n")
+	}
+
+	end := min(m.offset+m.height, len(children))
+	for i := m.offset; i < end; i++ {
+		node := children[i]
+		prefix := " "
+		if i == m.cursor {
+			prefix = ">"
+		}
+
+		share := m.share(node.impact)
+		line := fmt.Sprintf(
+			"%s %-*s %12.6f %10.6f  %s %5.1f%%",
+			prefix,
+			nameWidth,
+			truncate(node.label, nameWidth),
+			node.impact.KgCO2Equivalent,
+			node.impact.M3WaterUsage,
+			shareBar(share, shareBarWidth),
+			share,
+		)
+		if i == m.cursor {
+			b.WriteString(selectedStyle.Render(line))
+		} else {
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+	}
+
+	if selected := m.selected(); selected != nil {
+		detail := fmt.Sprintf("Selected %s: %s", selected.kind, selected.label)
+		if selected.detail != "" {
+			detail += "\nCategory: " + selected.detail
+		}
+		if len(selected.children) > 0 {
+			detail += fmt.Sprintf("\n%d %s(s) below, press enter to drill in", len(selected.children), childKind(selected))
+		}
+		b.WriteString("\n")
+		b.WriteString(detailStyle.Render(detail))
+		b.WriteString("\n")
+	}
+
+	switch {
+	case m.editing:
+		b.WriteString("\nDate range: ")
+		b.WriteString(m.input.View())
+		b.WriteString(subtleStyle.Render("  enter query  esc cancel"))
+		b.WriteString("\n")
+	case m.querying:
+		b.WriteString(subtleStyle.Render("\nquerying actual footprint data..."))
+		b.WriteString("\n")
+	}
+	if m.err != nil {
+		b.WriteString(subtleStyle.Render("error: " + m.err.Error()))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+func (m actualModel) current() *impactNode {
+	return m.stack[len(m.stack)-1]
+}
+
+func (m actualModel) selected() *impactNode {
+	children := m.current().children
+	if m.cursor < 0 || m.cursor >= len(children) {
+		return nil
+	}
+	return children[m.cursor]
+}
+
+func (m actualModel) share(impact footprint.TotalImpact) float64 {
+	if m.sortMode == sortByWater {
+		return actualview.Share(impact.M3WaterUsage, m.resp.TotalImpact.M3WaterUsage)
+	}
+	return actualview.Share(impact.KgCO2Equivalent, m.resp.TotalImpact.KgCO2Equivalent)
+}
+
+func (m actualModel) sortLabel() string {
+	if m.sortMode == sortByWater {
+		return "m3 water"
+	}
+	return "kgCO2e"
+}
+
+func (m actualModel) sortTree(node *impactNode) {
+	sort.SliceStable(node.children, func(i, j int) bool {
+		a, b := node.children[i].impact, node.children[j].impact
+		if m.sortMode == sortByWater {
+			return a.M3WaterUsage > b.M3WaterUsage
+		}
+		return a.KgCO2Equivalent > b.KgCO2Equivalent
+	})
+	for _, child := range node.children {
+		m.sortTree(child)
+	}
+}
+
+func (m *actualModel) clamp() {
+	size := len(m.current().children)
+	if m.cursor >= size {
+		m.cursor = size - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.height {
+		m.offset = m.cursor - m.height + 1
+	}
+}
+
+func childKind(node *impactNode) string {
+	switch node.kind {
+	case "organization":
+		return "project"
+	case "project":
+		return "region"
+	case "region":
+		return "zone"
+	default:
+		return "sku"
+	}
+}
+
+func indexOf(nodes []*impactNode, target *impactNode) int {
+	for i, node := range nodes {
+		if node == target {
+			return i
+		}
+	}
+	return 0
+}
+
+func shareBar(share float64, width int) string {
+	filled := int(share/100*float64(width) + 0.5)
+	filled = max(0, min(filled, width))
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
@@ -0,0 +1,164 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alesr/impact/internal/pkg/daterange"
+	"github.com/alesr/impact/internal/scw/footprint"
+)
+
+func testActualResponse() *footprint.QueryImpactDataResponse {
+	return &footprint.QueryImpactDataResponse{
+		StartDate:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:     time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		TotalImpact: footprint.TotalImpact{KgCO2Equivalent: 10, M3WaterUsage: 1},
+		Projects: []footprint.ProjectImpact{
+			{ProjectID: "small", TotalProjectImpact: footprint.TotalImpact{KgCO2Equivalent: 2, M3WaterUsage: 0.9}},
+			{
+				ProjectID:          "big",
+				TotalProjectImpact: footprint.TotalImpact{KgCO2Equivalent: 8, M3WaterUsage: 0.1},
+				Regions: []footprint.RegionImpact{{
+					Region:            "fr-par",
+					TotalRegionImpact: footprint.TotalImpact{KgCO2Equivalent: 8, M3WaterUsage: 0.1},
+				}},
+			},
+		},
+	}
+}
+
+func pressKey(t *testing.T, m tea.Model, key string) tea.Model {
+	t.Helper()
+
+	var msg tea.KeyMsg
+	switch key {
+	case "enter":
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+	case "backspace":
+		msg = tea.KeyMsg{Type: tea.KeyBackspace}
+	case "esc":
+		msg = tea.KeyMsg{Type: tea.KeyEsc}
+	case "shift+tab":
+		msg = tea.KeyMsg{Type: tea.KeyShiftTab}
+	default:
+		msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	}
+
+	next, _ := m.Update(msg)
+	return next
+}
+
+func TestActualModelNavigation(t *testing.T) {
+	t.Parallel()
+
+	var m tea.Model = newActualModel(context.Background(), testActualResponse(), nil)
+
+	view := m.View()
+	assert.Contains(t, view, "All projects")
+	assert.Contains(t, view, "80.0%")
+	assert.Equal(t, "big", m.(actualModel).selected().label)
+
+	m = pressKey(t, m, "enter")
+	assert.Contains(t, m.View(), "All projects › big")
+	assert.Equal(t, "fr-par", m.(actualModel).selected().label)
+
+	m = pressKey(t, m, "enter")
+	assert.Len(t, m.(actualModel).stack, 2, "regions without zones cannot be drilled into")
+
+	m = pressKey(t, m, "backspace")
+	assert.Len(t, m.(actualModel).stack, 1)
+	assert.Equal(t, "big", m.(actualModel).selected().label)
+
+	m = pressKey(t, m, "s")
+	assert.Equal(t, "big", m.(actualModel).selected().label, "selection follows the node after sorting")
+	assert.Equal(t, "small", m.(actualModel).current().children[0].label)
+	assert.Contains(t, m.View(), "Sort: m3 water")
+}
+
+func TestActualModelRequery(t *testing.T) {
+	t.Parallel()
+
+	var gotStart, gotEnd *time.Time
+	queryFn := func(_ context.Context, start, end *time.Time) (*footprint.QueryImpactDataResponse, error) {
+		gotStart, gotEnd = start, end
+		if start.Month() == time.March {
+			return nil, errors.New("boom")
+		}
+		return &footprint.QueryImpactDataResponse{StartDate: *start, EndDate: *end}, nil
+	}
+
+	var m tea.Model = newActualModel(context.Background(), testActualResponse(), queryFn)
+
+	m = pressKey(t, m, "r")
+	require.True(t, m.(actualModel).editing)
+	assert.Equal(t, "2026-01-01 2026-02-01", m.(actualModel).input.Value())
+
+	am := m.(actualModel)
+	am.input.SetValue("2026-02-01 2026-03-01")
+	next, cmd := am.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, cmd)
+	assert.True(t, next.(actualModel).querying)
+
+	m, _ = next.Update(cmd())
+	require.NotNil(t, gotStart)
+	assert.Equal(t, "2026-02-01", gotStart.Format(daterange.Layout))
+	assert.Equal(t, "2026-03-01", gotEnd.Format(daterange.Layout))
+	assert.Contains(t, m.View(), "2026-02-01 -> 2026-03-01")
+	assert.Contains(t, m.View(), "no impact data for this period")
+
+	am = pressKey(t, m, "r").(actualModel)
+	am.input.SetValue("2026-03-01 2026-04-01")
+	next, cmd = am.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = next.Update(cmd())
+	assert.Contains(t, m.View(), "error: boom")
+	assert.Contains(t, m.View(), "2026-02-01 -> 2026-03-01", "keeps the previous data on failure")
+}
+
+func TestParseDateRange(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 5, 14, 10, 30, 0, 0, time.UTC)
+
+	_, _, err := parseDateRange("2026-01-01 2026-01-02 2026-01-03", now)
+	require.Error(t, err)
+
+	_, _, err = parseDateRange("2026-02-01 2026-01-01", now)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be before end")
+
+	_, _, err = parseDateRange("2026-01-01 soon", now)
+	require.ErrorContains(t, err, "could not parse end")
+
+	start, end, err := parseDateRange(" 2026-01-01   2026-01-31 ", now)
+	require.NoError(t, err)
+	assert.Equal(t, 30*24*time.Hour, end.Sub(start))
+
+	start, end, err = parseDateRange("-90d now", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 2, 13, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, now, end)
+
+	start, end, err = parseDateRange("2026-01-01T12:00:00+02:00 2026-01-02", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), end)
+
+	start, end, err = parseDateRange("last-month", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), end)
+}
+
+func TestShareBar(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "█████░░░░░", shareBar(50, 10))
+	assert.Equal(t, "░░░░░░░░░░", shareBar(0, 10))
+	assert.Equal(t, "██████████", shareBar(120, 10))
+}
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -12,15 +13,22 @@ import (
 	"github.com/alesr/impact/internal/pkg/progress"
 )
 
-type buildPlanReportFn func() (estimate.Report, error)
+type buildPlanReportFn func(ctx context.Context) (estimate.Report, error)
+
+// loadFn does the slow work behind a loading screen and returns the model
+// that replaces it. ctx is canceled when the user quits.
+type loadFn func(ctx context.Context) (tea.Model, error)
 
 type loadingDoneMsg struct {
-	report estimate.Report
-	err    error
+	model tea.Model
+	err   error
 }
 
 type loadingModel struct {
-	buildFn  buildPlanReportFn
+	ctx      context.Context
+	load     loadFn
+	subtitle string
+	message  string
 	spinner  spinner.Model
 	width    int
 	height   int
@@ -32,16 +40,34 @@ type loadingErrorModel struct {
 	err error
 }
 
-func RunPlanReportLoading(buildFn buildPlanReportFn) error {
+func RunPlanReportLoading(ctx context.Context, buildFn buildPlanReportFn) error {
+	return runLoading(ctx, "Terraform plan impact report", "processing plan and fetching catalog", func(ctx context.Context) (tea.Model, error) {
+		rep, err := buildFn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return newPlanModel(rep), nil
+	})
+}
+
+// runLoading shows the loading screen until load returns. Quitting cancels
+// the context of load, so requests in flight stop with the program.
+func runLoading(ctx context.Context, subtitle, message string, load loadFn) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	spin := spinner.New(spinner.WithSpinner(progress.DotSpinner()))
 	spin.Style = subtleStyle
 
 	m := loadingModel{
-		buildFn: buildFn,
-		spinner: spin,
-		started: time.Now(),
-		width:   120,
-		height:  30,
+		ctx:      ctx,
+		load:     load,
+		subtitle: subtitle,
+		message:  message,
+		spinner:  spin,
+		started:  time.Now(),
+		width:    120,
+		height:   30,
 	}
 
 	finalModel, err := tea.NewProgram(m).Run()
@@ -89,17 +115,8 @@ func (m loadingModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return loadingErrorModel{err: msg.err}, tea.Quit
 		}
 
-		reportModel := newPlanModel(msg.report)
-		reportModel.width = m.width
-		if m.height > 12 {
-			reportModel.height = m.height - 10
-			if reportModel.height < 5 {
-				reportModel.height = 5
-			}
-		}
-
 		m.finished = true
-		return reportModel, nil
+		return msg.model.Update(tea.WindowSizeMsg{Width: m.width, Height: m.height})
 	}
 
 	return m, nil
@@ -117,9 +134,9 @@ func (m loadingModel) View() string {
 	var b strings.Builder
 	b.WriteString(titleStyle.Render("impact"))
 	b.WriteString("  ")
-	b.WriteString(subtitleStyle.Render("Terraform plan impact report"))
+	b.WriteString(subtitleStyle.Render(m.subtitle))
 	b.WriteString("\n\n")
-	b.WriteString(chipStyle.Render(fmt.Sprintf("%s processing plan and fetching catalog", m.spinner.View())))
+	b.WriteString(chipStyle.Render(fmt.Sprintf("%s %s", m.spinner.View(), m.message)))
 	b.WriteString("\n")
 	b.WriteString(subtleStyle.Render(fmt.Sprintf("elapsed: %s", time.Since(m.started).Truncate(time.Second))))
 	b.WriteString("\n")
@@ -131,7 +148,7 @@ func (m loadingModel) View() string {
 
 func (m loadingModel) runBuild() tea.Cmd {
 	return func() tea.Msg {
-		rep, err := m.buildFn()
-		return loadingDoneMsg{report: rep, err: err}
+		model, err := m.load(m.ctx)
+		return loadingDoneMsg{model: model, err: err}
 	}
 }
@@ -0,0 +1,29 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadingModelContext(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	m := loadingModel{ctx: ctx, load: func(ctx context.Context) (tea.Model, error) {
+		return nil, ctx.Err()
+	}}
+
+	done, ok := m.runBuild()().(loadingDoneMsg)
+	require.True(t, ok)
+	require.ErrorIs(t, done.err, context.Canceled)
+
+	next, cmd := m.Update(done)
+	assert.IsType(t, loadingErrorModel{}, next)
+	assert.NotNil(t, cmd)
+}
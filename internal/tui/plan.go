@@ -16,6 +16,7 @@ type tab int
 const (
 	tabRows tab = iota
 	tabUnsupported
+	tabPlans
 )
 
 type sortMode int
@@ -77,8 +78,10 @@ func (m planModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msg.String() {
 		case "q", "ctrl+c":
 			return m, tea.Quit
-		case "tab", "l", "right", "shift+tab", "h", "left":
-			m.tab = (m.tab + 1) % 2
+		case "tab", "l", "right":
+			m.tab = m.nextTab()
+		case "shift+tab", "h", "left":
+			m.tab = m.prevTab()
 		case "s":
 			m.sortMode = (m.sortMode + 1) % 2
 			m.sortRows()
@@ -87,6 +90,10 @@ func (m planModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.tab = tabRows
 		case "2":
 			m.tab = tabUnsupported
+		case "3":
+			if len(m.report.Groups) > 0 {
+				m.tab = tabPlans
+			}
 		case "up", "k":
 			m.moveCursor(-1)
 		case "down", "j":
@@ -117,6 +124,10 @@ func (m planModel) View() string {
 	b.WriteString(tabStyle.Render(m.tabLabel(tabRows)))
 	b.WriteString(" ")
 	b.WriteString(tabStyle.Render(m.tabLabel(tabUnsupported)))
+	if len(m.report.Groups) > 0 {
+		b.WriteString(" ")
+		b.WriteString(tabStyle.Render(m.tabLabel(tabPlans)))
+	}
 	b.WriteString("   ")
 	b.WriteString(subtleStyle.Render("Sort: " + m.sortLabel()))
 	b.WriteString("\n")
@@ -170,6 +181,9 @@ func (m planModel) View() string {
 			selected := m.rows[m.cursorRows]
 			detail := strings.Builder{}
 			detail.WriteString(fmt.Sprintf("Selected: %s\n", selected.Address))
+			if selected.Source != "" {
+				detail.WriteString(fmt.Sprintf("Plan: %s\n", selected.Source))
+			}
 			detail.WriteString(fmt.Sprintf("SKU: %s", selected.SKU))
 			b.WriteString("\n")
 			b.WriteString(detailStyle.Render(detail.String()))
@@ -193,7 +207,11 @@ func (m planModel) View() string {
 			if i == m.cursorUnsupported {
 				prefix = ">"
 			}
-			line := fmt.Sprintf("%s %s: %s", prefix, m.unsupported[i].Address, m.unsupported[i].Reason)
+			address := m.unsupported[i].Address
+			if m.unsupported[i].Source != "" {
+				address = fmt.Sprintf("[%s] %s", m.unsupported[i].Source, address)
+			}
+			line := fmt.Sprintf("%s %s: %s", prefix, address, m.unsupported[i].Reason)
 			if i == m.cursorUnsupported {
 				b.WriteString(selectedStyle.Render(line))
 			} else {
@@ -201,6 +219,25 @@ func (m planModel) View() string {
 			}
 			b.WriteString("\n")
 		}
+
+	case tabPlans:
+		srcWidth := 42
+		if m.width > 0 && m.width < 120 {
+			srcWidth = 28
+		}
+		b.WriteString(headerStyle.Render(fmt.Sprintf("  %-*s %12s %10s %8s %12s", srcWidth, "Plan", "kgCO2e/mo", "m3/mo", "unknown", "unsupported")))
+		b.WriteString("\n")
+		for _, group := range m.report.Groups {
+			b.WriteString(fmt.Sprintf(
+				"  %-*s %12s %10s %8d %12d\n",
+				srcWidth,
+				truncate(group.Source, srcWidth),
+				planview.FormatKg(group.Totals.KgCO2eMonth, group.Totals.KgCO2eKnown),
+				planview.FormatWater(group.Totals.M3WaterMonth, group.Totals.M3WaterKnown),
+				group.Totals.UnknownRows,
+				group.Unsupported,
+			))
+		}
 	}
 
 	return b.String()
@@ -229,10 +266,29 @@ func (m planModel) sortLabel() string {
 	}
 }
 
+func (m planModel) nextTab() tab {
+	return (m.tab + 1) % m.tabCount()
+}
+
+func (m planModel) prevTab() tab {
+	return (m.tab + m.tabCount() - 1) % m.tabCount()
+}
+
+// tabCount leaves out the plans tab when the report has a single plan.
+func (m planModel) tabCount() tab {
+	if len(m.report.Groups) > 0 {
+		return 3
+	}
+	return 2
+}
+
 func (m planModel) tabLabel(t tab) string {
 	name := "rows"
-	if t == tabUnsupported {
+	switch t {
+	case tabUnsupported:
 		name = "unsupported"
+	case tabPlans:
+		name = "plans"
 	}
 	if m.tab == t {
 		return strings.ToUpper(name)
@@ -3,6 +3,9 @@ package tui
 import (
 	"testing"
 
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/alesr/impact/internal/estimate"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -12,3 +15,44 @@ func TestTruncate(t *testing.T) {
 	assert.Equal(t, "short", truncate("short", 10))
 	assert.Equal(t, "very-lo...", truncate("very-long-resource-address", 10))
 }
+
+func TestPlanModelTabs(t *testing.T) {
+	t.Parallel()
+
+	single := newPlanModel(estimate.Report{})
+	single.tab = single.nextTab()
+	assert.Equal(t, tabUnsupported, single.tab)
+	single.tab = single.nextTab()
+	assert.Equal(t, tabRows, single.tab)
+
+	multi := newPlanModel(estimate.Report{Groups: []estimate.Group{{Source: "a/plan.json"}, {Source: "b/plan.json", Unsupported: 7}}})
+	multi.tab = tabUnsupported
+	multi.tab = multi.nextTab()
+	assert.Equal(t, tabPlans, multi.tab)
+	view := multi.View()
+	assert.Contains(t, view, "b/plan.json")
+	assert.Contains(t, view, "unsupported")
+	assert.Regexp(t, `b/plan\.json.* 7\n`, view)
+}
+
+func TestPlanModelTabKeys(t *testing.T) {
+	t.Parallel()
+
+	var m tea.Model = newPlanModel(estimate.Report{Groups: []estimate.Group{{Source: "a/plan.json"}, {Source: "b/plan.json"}}})
+	for _, step := range []struct {
+		key  string
+		want tab
+	}{
+		{"shift+tab", tabPlans},
+		{"h", tabUnsupported},
+		{"l", tabPlans},
+		{"l", tabRows},
+		{"shift+tab", tabPlans},
+	} {
+		m = pressKey(t, m, step.key)
+		assert.Equal(t, step.want, m.(planModel).tab, step.key)
+	}
+
+	single := pressKey(t, newPlanModel(estimate.Report{}), "shift+tab")
+	assert.Equal(t, tabUnsupported, single.(planModel).tab)
+}